@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -25,18 +27,48 @@ func main() {
 	}
 	fmt.Println("Connected to Redis")
 
+	// Sessions expire this long after they start (0 = never)
+	maxDuration, err := strconv.Atoi(getEnv("SESSION_MAX_DURATION_SECONDS", "0"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid SESSION_MAX_DURATION_SECONDS value: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Idempotency-Keys on session creation are remembered this long (0 = ignored)
+	idempotencyWindow, err := strconv.Atoi(getEnv("IDEMPOTENCY_WINDOW_SECONDS", "86400"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid IDEMPOTENCY_WINDOW_SECONDS value: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Proxies whose X-Forwarded-For/X-Real-IP headers are believed (none by default)
+	trustedProxies, err := httphandler.ParseTrustedProxies(getEnv("TRUSTED_PROXIES", ""))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid TRUSTED_PROXIES value: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Initialize HTTP handler
-	handler := httphandler.NewHandler(sessionStore)
+	// ADMIN_TOKEN enables admin-only features (e.g. explicit seeds)
+	// PLAYER_TOKEN_SECRET signs the tokens players act with
+	handler := httphandler.NewHandler(
+		sessionStore,
+		httphandler.WithAdminToken(getEnv("ADMIN_TOKEN", "")),
+		httphandler.WithPlayerSecret(getEnv("PLAYER_TOKEN_SECRET", "")),
+		httphandler.WithMaxSessionDuration(time.Duration(maxDuration)*time.Second),
+		httphandler.WithLedger(sessionStore.Ledger()),
+		httphandler.WithIdempotencyWindow(time.Duration(idempotencyWindow)*time.Second),
+	)
 
 	// Setup router
 	router := chi.NewRouter()
 
 	// Middleware
 	router.Use(middleware.RequestID)
-	router.Use(middleware.RealIP)
+	router.Use(httphandler.TrustedRealIP(trustedProxies))
 	router.Use(middleware.Logger) // Simple logging middleware
 	router.Use(middleware.Recoverer)
-	router.Use(middleware.Timeout(10 * time.Second))
+	router.Use(skipStreams(middleware.Timeout(10 * time.Second))) // Streams are long-lived
 
 	// Routes
 	router.Mount("/", handler.Routes())
@@ -77,6 +109,20 @@ func main() {
 	fmt.Println("Server exited")
 }
 
+// skipStreams applies a middleware to every request but session streams
+func skipStreams(mw func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		wrapped := mw(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "/stream") || strings.HasSuffix(r.URL.Path, "/events") {
+				next.ServeHTTP(w, r)
+				return
+			}
+			wrapped.ServeHTTP(w, r)
+		})
+	}
+}
+
 // getEnv gets an environment variable or returns a default value
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
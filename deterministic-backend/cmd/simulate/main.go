@@ -0,0 +1,160 @@
+// Command simulate runs the deterministic engine over many seeds and rounds
+// and reports game economics: round length and break value distributions,
+// crash points, and hit rates / expected payouts for cash-out targets.
+//
+// Usage:
+//
+//	simulate [-mode crash] [-param house_edge=0.02] [-seeds 10000] [-rounds 1000]
+//	         [-interval-min 50 -interval-max 400 -distribution geometric -p 0.01]
+//	         [-targets 1.5,2,10] [-format json|csv] [-o report.json]
+//
+// Seeds are derived from -seed-base, so runs are reproducible and results
+// do not depend on -workers.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/engine"
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/simulate"
+)
+
+// params collects repeated -param name=value flags.
+type params map[string]float64
+
+func (p params) String() string {
+	return fmt.Sprint(map[string]float64(p))
+}
+
+func (p params) Set(value string) error {
+	name, raw, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("expected name=value, got %q", value)
+	}
+	parsed, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return fmt.Errorf("invalid value for %s: %w", name, err)
+	}
+	p[name] = parsed
+	return nil
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run parses flags, runs the simulation and returns the exit code.
+func run(args []string) int {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	modeParams := params{}
+	mode := fs.String("mode", engine.DefaultMode, "game rule: "+strings.Join(engine.Modes(), ", "))
+	fs.Var(modeParams, "param", "mode parameter name=value (repeatable)")
+	engineVersion := fs.Int("engine-version", engine.LatestVersion, "engine algorithm version")
+	seeds := fs.Int("seeds", 10000, "number of simulated sessions")
+	rounds := fs.Int("rounds", 1000, "rounds per session")
+	seedBase := fs.String("seed-base", "simulate", "base string seeds are derived from")
+	tickMs := fs.Int64("tick-ms", 100, "tick interval for wall-time figures (0 = omit)")
+	intervalMin := fs.Int64("interval-min", 0, "minimum break interval (default engine range)")
+	intervalMax := fs.Int64("interval-max", 0, "maximum break interval")
+	distribution := fs.String("distribution", "", "interval distribution: uniform, geometric or weighted")
+	p := fs.Float64("p", 0, "per-step break probability (geometric)")
+	weights := fs.String("weights", "", "weighted intervals as interval:weight,... (weighted)")
+	targets := fs.String("targets", "", "comma-separated cash-out multipliers (crash) or values (counter)")
+	buckets := fs.Int("buckets", 20, "histogram buckets")
+	workers := fs.Int("workers", 0, "parallel workers (0 = GOMAXPROCS)")
+	format := fs.String("format", "json", "output format: json or csv")
+	output := fs.String("o", "-", "output file (- for stdout)")
+	fs.Parse(args)
+
+	config := simulate.Config{
+		Mode:          *mode,
+		EngineVersion: *engineVersion,
+		Params:        modeParams,
+		Seeds:         *seeds,
+		Rounds:        *rounds,
+		SeedBase:      *seedBase,
+		TickMs:        *tickMs,
+		Buckets:       *buckets,
+		Workers:       *workers,
+	}
+	if len(modeParams) == 0 {
+		config.Params = nil
+	}
+
+	if *intervalMin != 0 || *intervalMax != 0 || *distribution != "" || *weights != "" {
+		interval := &engine.IntervalSpec{Min: *intervalMin, Max: *intervalMax, Distribution: *distribution, P: *p}
+		for _, entry := range splitList(*weights) {
+			rawInterval, rawWeight, _ := strings.Cut(entry, ":")
+			value, err1 := strconv.ParseInt(rawInterval, 10, 64)
+			weight, err2 := strconv.ParseInt(rawWeight, 10, 64)
+			if err1 != nil || err2 != nil {
+				fmt.Fprintf(os.Stderr, "Invalid weight %q, expected interval:weight\n", entry)
+				return 2
+			}
+			interval.Weights = append(interval.Weights, engine.WeightedInterval{Interval: value, Weight: weight})
+		}
+		config.Interval = interval
+	}
+
+	for _, entry := range splitList(*targets) {
+		target, err := strconv.ParseFloat(entry, 64)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid target %q\n", entry)
+			return 2
+		}
+		config.Targets = append(config.Targets, target)
+	}
+
+	write := simulate.WriteJSON
+	switch *format {
+	case "json":
+	case "csv":
+		write = simulate.WriteCSV
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown format %q\n", *format)
+		return 2
+	}
+
+	started := time.Now()
+	report, err := simulate.Run(config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Simulation failed: %v\n", err)
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "-" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create %s: %v\n", *output, err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := write(w, report); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write report: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(os.Stderr, "Simulated %d rounds in %s\n", report.Rounds, time.Since(started).Round(time.Millisecond))
+	return 0
+}
+
+// splitList splits a comma-separated flag value, ignoring empty entries.
+func splitList(value string) []string {
+	var entries []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
@@ -0,0 +1,118 @@
+// Command vectors generates and verifies engine test vectors.
+//
+// Usage:
+//
+//	vectors generate [-engine-version 2] [-o vectors.json]
+//	vectors verify -i vectors-from-other-impl.json
+//
+// generate writes the Go engine's output for a set of edge-case inputs.
+// Other implementations (e.g. tick-broadcaster/src/engine.ts) compute the
+// state for the same inputs and write it back in the same format; verify
+// recomputes every vector with the Go engine and reports the first mismatch.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/engine"
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/vectors"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "generate":
+		os.Exit(generate(os.Args[2:]))
+	case "verify":
+		os.Exit(verify(os.Args[2:]))
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage:")
+	fmt.Fprintln(os.Stderr, "  vectors generate [-engine-version N] [-o file]")
+	fmt.Fprintln(os.Stderr, "  vectors verify [-i file]")
+}
+
+// generate writes a vector file and returns the exit code.
+func generate(args []string) int {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	engineVersion := fs.Int("engine-version", engine.LatestVersion, "engine algorithm version")
+	output := fs.String("o", "-", "output file (- for stdout)")
+	fs.Parse(args)
+
+	file, err := vectors.Generate(*engineVersion)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to generate vectors: %v\n", err)
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "-" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create %s: %v\n", *output, err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := vectors.Write(w, file); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write vectors: %v\n", err)
+		return 1
+	}
+
+	if *output != "-" {
+		fmt.Fprintf(os.Stderr, "Wrote %d vectors (engine version %d) to %s\n", len(file.Vectors), file.EngineVersion, *output)
+	}
+	return 0
+}
+
+// verify checks a vector file against the Go engine and returns the exit code:
+// 0 if every vector matches, 1 on the first mismatch or an invalid file.
+func verify(args []string) int {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	input := fs.String("i", "-", "input file (- for stdin)")
+	fs.Parse(args)
+
+	var r io.Reader = os.Stdin
+	if *input != "-" {
+		f, err := os.Open(*input)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to open %s: %v\n", *input, err)
+			return 1
+		}
+		defer f.Close()
+		r = f
+	}
+
+	file, err := vectors.Read(r)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+
+	mismatch, err := vectors.Verify(file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid vector file: %v\n", err)
+		return 1
+	}
+	if mismatch != nil {
+		fmt.Fprintf(os.Stderr, "MISMATCH (generator %s): %v\n", file.Generator, mismatch)
+		return 1
+	}
+
+	fmt.Printf("OK: %d vectors match engine version %d\n", len(file.Vectors), file.EngineVersion)
+	return 0
+}
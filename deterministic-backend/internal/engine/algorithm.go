@@ -0,0 +1,105 @@
+package engine
+
+import "fmt"
+
+// Engine algorithm versions.
+//
+// A session records the version it was created with, so its timeline stays
+// reproducible forever even after the default algorithm changes. The output
+// of a released version must never change; add a new version instead.
+const (
+	// Version1 is the original algorithm: xorshift64(seed ^ round).
+	// It degenerates when seed^round == 0 and correlates adjacent rounds.
+	Version1 = 1
+
+	// Version2 uses SplitMix64 over a pre-mixed seed and the round number.
+	Version2 = 2
+
+	// LatestVersion is the version new sessions are created with.
+	LatestVersion = Version2
+)
+
+// Algorithm is a versioned source of per-round randomness.
+// Implementations must be pure: same (seed, round) → same output.
+type Algorithm interface {
+	// Version returns the engine version of the algorithm.
+	Version() int
+
+	// RoundRandom returns a pseudo-random 64-bit value for a round.
+	RoundRandom(seed int64, round int64) uint64
+
+	// RoundUniform returns a pseudo-random float in [0, 1) for a round.
+	RoundUniform(seed int64, round int64) float64
+}
+
+// algorithms is the registry of engine versions.
+var algorithms = map[int]Algorithm{
+	Version1: algorithmV1{},
+	Version2: algorithmV2{},
+}
+
+// AlgorithmFor returns the algorithm for an engine version.
+// Version 0 selects Version1, which every session used before versions
+// were recorded.
+func AlgorithmFor(version int) (Algorithm, error) {
+	if version == 0 {
+		version = Version1
+	}
+
+	algorithm, ok := algorithms[version]
+	if !ok {
+		return nil, fmt.Errorf("unknown engine version %d", version)
+	}
+	return algorithm, nil
+}
+
+// algorithmV1 is the original xorshift64-based algorithm.
+type algorithmV1 struct{}
+
+func (algorithmV1) Version() int {
+	return Version1
+}
+
+func (algorithmV1) RoundRandom(seed int64, round int64) uint64 {
+	return roundRandom(seed, round)
+}
+
+func (algorithmV1) RoundUniform(seed int64, round int64) float64 {
+	return roundUniform(seed, round)
+}
+
+// algorithmV2 is the SplitMix64-based algorithm.
+//
+// The seed is mixed once, then each round is a distinct position in the
+// SplitMix64 sequence:
+//
+//	random(seed, round) = splitmix64(splitmix64(seed) + round * golden)
+//
+// Every input produces a well-distributed output (there is no all-zero
+// state), and adjacent rounds are uncorrelated.
+type algorithmV2 struct{}
+
+func (algorithmV2) Version() int {
+	return Version2
+}
+
+func (algorithmV2) RoundRandom(seed int64, round int64) uint64 {
+	return splitmix64(splitmix64(uint64(seed)) + uint64(round)*splitmixGamma)
+}
+
+func (a algorithmV2) RoundUniform(seed int64, round int64) float64 {
+	// Top 53 bits → uniform float in [0, 1)
+	return float64(a.RoundRandom(seed, round)>>11) / (1 << 53)
+}
+
+// splitmixGamma is the SplitMix64 increment (2^64 / golden ratio).
+const splitmixGamma = 0x9E3779B97F4A7C15
+
+// splitmix64 returns the SplitMix64 output for the state x.
+// This is a pure function: same input → same output.
+func splitmix64(x uint64) uint64 {
+	z := x + splitmixGamma
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	return z ^ (z >> 31)
+}
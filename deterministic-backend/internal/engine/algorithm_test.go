@@ -0,0 +1,189 @@
+package engine
+
+import (
+	"math"
+	"testing"
+)
+
+// The tests below freeze the output of released engine versions.
+// Sessions pinned to a version must stay reproducible forever, so these
+// values must never be updated; a change in output needs a new version.
+
+func TestVersion1_Frozen(t *testing.T) {
+	// computeBreakInterval(seed, round) for rounds 0, 1, 2 and 1000
+	intervals := []struct {
+		seed      int64
+		intervals [4]int64
+	}{
+		{0, [4]int64{100, 235, 169, 147}},
+		{1, [4]int64{235, 100, 103, 213}},
+		{12345, [4]int64{136, 269, 205, 173}},
+		{-1, [4]int64{139, 199, 259, 300}},
+		{math.MaxInt64, [4]int64{241, 100, 160, 201}},
+		{math.MinInt64, [4]int64{202, 136, 271, 249}},
+	}
+
+	v1, err := AlgorithmFor(Version1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	spec := DefaultIntervalSpec()
+
+	for _, tt := range intervals {
+		for i, round := range []int64{0, 1, 2, 1000} {
+			if got := computeBreakInterval(tt.seed, round); got != tt.intervals[i] {
+				t.Errorf("computeBreakInterval(%d, %d): expected %d, got %d", tt.seed, round, tt.intervals[i], got)
+			}
+			if got := spec.Interval(v1, tt.seed, round); got != tt.intervals[i] {
+				t.Errorf("v1 interval(%d, %d): expected %d, got %d", tt.seed, round, tt.intervals[i], got)
+			}
+		}
+	}
+
+	states := []State{
+		{Step: 0, Value: 1, Round: 0},
+		{Step: 99, Value: 100, Round: 0},
+		{Step: 500, Value: 94, Round: 2},
+		{Step: 10000, Value: 166, Round: 49},
+		{Step: 1000000, Value: 23, Round: 4983},
+	}
+	counter, _ := NewRule(ModeCounter, RuleConfig{Version: Version1})
+	for _, want := range states {
+		if got := StateAtStep(12345, want.Step); got != want {
+			t.Errorf("StateAtStep(12345, %d): expected %+v, got %+v", want.Step, want, got)
+		}
+		if got := RuleStateAtStep(counter, 12345, want.Step); got != want {
+			t.Errorf("v1 counter at step %d: expected %+v, got %+v", want.Step, want, got)
+		}
+	}
+
+	crash := CrashRule{HouseEdge: DefaultHouseEdge, Growth: DefaultGrowth, Algorithm: v1}
+	crashPoints := []float64{1.91, 100.57, 5.76, 1.45, 1.17}
+	for round, want := range crashPoints {
+		if got := crash.CrashPoint(12345, int64(round)); got != want {
+			t.Errorf("v1 crash point round %d: expected %v, got %v", round, want, got)
+		}
+	}
+
+	geometric, _ := IntervalSpec{Min: 10, Max: 1000, Distribution: DistributionGeometric, P: 0.02}.Normalize()
+	geometricIntervals := []int64{28, 85, 115, 35, 13}
+	for round, want := range geometricIntervals {
+		if got := geometric.Interval(v1, 42, int64(round)); got != want {
+			t.Errorf("v1 geometric interval round %d: expected %d, got %d", round, want, got)
+		}
+	}
+}
+
+func TestVersion2_Frozen(t *testing.T) {
+	// SplitMix64 seeded with 0 (reference value of the algorithm)
+	if got := splitmix64(0); got != 0xE220A8397B1DCDAF {
+		t.Errorf("splitmix64(0): expected 0xE220A8397B1DCDAF, got %#x", got)
+	}
+
+	randoms := []struct {
+		seed   int64
+		values [4]uint64
+	}{
+		{0, [4]uint64{12035550249420947055, 12935080325729570654, 7141179953334974231, 11412949293094596140}},
+		{1, [4]uint64{6791897765849424158, 17405687883870564846, 834844254806117752, 14798943950764616812}},
+		{12345, [4]uint64{291995243589385535, 9240811703748923664, 3274502074447245204, 4178903587928559033}},
+		{-1, [4]uint64{6755974106381971767, 13665441387248026780, 9418208206007042598, 625052457841906857}},
+	}
+
+	v2, err := AlgorithmFor(Version2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, tt := range randoms {
+		for i, round := range []int64{0, 1, 2, 1000} {
+			if got := v2.RoundRandom(tt.seed, round); got != tt.values[i] {
+				t.Errorf("v2 random(%d, %d): expected %d, got %d", tt.seed, round, tt.values[i], got)
+			}
+		}
+	}
+
+	states := []State{
+		{Step: 0, Value: 1, Round: 0},
+		{Step: 99, Value: 100, Round: 0},
+		{Step: 500, Value: 75, Round: 2},
+		{Step: 10000, Value: 253, Round: 48},
+		{Step: 1000000, Value: 167, Round: 4946},
+	}
+	counter, _ := NewRule(ModeCounter, RuleConfig{Version: Version2})
+	for _, want := range states {
+		if got := RuleStateAtStep(counter, 12345, want.Step); got != want {
+			t.Errorf("v2 counter at step %d: expected %+v, got %+v", want.Step, want, got)
+		}
+	}
+
+	crash := CrashRule{HouseEdge: DefaultHouseEdge, Growth: DefaultGrowth, Algorithm: v2}
+	crashPoints := []float64{1, 1.98, 1.2, 3.36, 1.09}
+	for round, want := range crashPoints {
+		if got := crash.CrashPoint(12345, int64(round)); got != want {
+			t.Errorf("v2 crash point round %d: expected %v, got %v", round, want, got)
+		}
+	}
+
+	// Crash rounds end on the last tick below the crash point
+	crashIntervals := []int64{1, 69, 19, 122, 9}
+	for round, want := range crashIntervals {
+		if got := crash.BreakInterval(12345, int64(round)); got != want {
+			t.Errorf("v2 crash interval round %d: expected %d, got %d", round, want, got)
+		}
+	}
+}
+
+func TestAlgorithmFor(t *testing.T) {
+	tests := []struct {
+		version  int
+		expected int
+		wantErr  bool
+	}{
+		{version: 0, expected: Version1},
+		{version: Version1, expected: Version1},
+		{version: Version2, expected: Version2},
+		{version: 99, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		algorithm, err := AlgorithmFor(tt.version)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("version %d: expected error", tt.version)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("version %d: unexpected error: %v", tt.version, err)
+		}
+		if algorithm.Version() != tt.expected {
+			t.Errorf("version %d: expected algorithm %d, got %d", tt.version, tt.expected, algorithm.Version())
+		}
+	}
+
+	if _, err := NewRule(ModeCounter, RuleConfig{Version: 99}); err == nil {
+		t.Error("Expected NewRule to reject an unknown version")
+	}
+}
+
+func TestVersion2_NoDegenerateRounds(t *testing.T) {
+	// v1 returns 0 whenever seed == round; v2 must not
+	v2 := algorithmV2{}
+	for i := int64(0); i < 1000; i++ {
+		if v2.RoundRandom(i, i) == 0 {
+			t.Fatalf("v2 random(%d, %d) is zero", i, i)
+		}
+	}
+
+	// Rounds of a small seed should cover the full 64-bit range
+	var high int
+	for round := int64(0); round < 1000; round++ {
+		if v2.RoundRandom(1, round) > math.MaxUint64/2 {
+			high++
+		}
+	}
+	if high < 400 || high > 600 {
+		t.Errorf("Expected ~half of outputs in the upper range, got %d/1000", high)
+	}
+}
@@ -0,0 +1,117 @@
+package engine
+
+import "time"
+
+// Pause is a window of wall time during which a session does not advance.
+type Pause struct {
+	From  time.Time // When the pause started
+	Until time.Time // When the pause ended (zero = still paused)
+}
+
+// Clock maps wall time to steps for a session.
+//
+// Only active time counts: pauses are subtracted from the time elapsed
+// since StartAt, so a session resumes exactly where it was paused, without
+// a jump in step, value or round. Phases add lobby and intermission ticks
+// during which the step holds. Once the end condition is met the clock
+// stops at the end step.
+type Clock struct {
+	StartAt  time.Time     // When the session started
+	TickMs   int64         // Tick interval in milliseconds
+	Schedule *TickSchedule // Variable tick rate (nil = TickMs throughout)
+	Phases   Phases        // Lobby and intermission ticks
+	End      *EndCondition // Deterministic end (nil = runs until stopped)
+	Pauses   []Pause       // Pause windows in chronological order
+}
+
+// Started reports whether the session has started at the given time.
+func (c Clock) Started(now time.Time) bool {
+	return !now.Before(c.StartAt)
+}
+
+// ActiveElapsed returns the active (unpaused) time between StartAt and now.
+// Returns 0 if now is before StartAt.
+func (c Clock) ActiveElapsed(now time.Time) time.Duration {
+	if !c.Started(now) {
+		return 0
+	}
+
+	elapsed := now.Sub(c.StartAt)
+	for _, p := range c.Pauses {
+		from := p.From
+		if from.Before(c.StartAt) {
+			from = c.StartAt
+		}
+		until := p.Until
+		if until.IsZero() || until.After(now) {
+			until = now
+		}
+		if until.After(from) {
+			elapsed -= until.Sub(from)
+		}
+	}
+
+	if elapsed < 0 {
+		return 0
+	}
+	return elapsed
+}
+
+// StepAt calculates the step index from active time.
+//
+// Formula: step = floor(activeElapsedMs / tickMs)
+// Without pauses, phases or a schedule this is identical to the
+// package-level StepAt. The rule and seed are only used by schedules with
+// round ticks and by intermissions.
+func (c Clock) StepAt(rule Rule, seed int64, now time.Time) int64 {
+	return c.PositionAt(rule, seed, now).Step
+}
+
+// PositionAt returns the step and game phase at the given time, frozen at
+// the end once the end condition is met. Before StartAt it returns the
+// zero position.
+func (c Clock) PositionAt(rule Rule, seed int64, now time.Time) Position {
+	position, _ := c.position(rule, seed, now)
+	return position
+}
+
+// positionAtElapsed returns the position after elapsedMs of active time.
+func (c Clock) positionAtElapsed(rule Rule, seed int64, elapsedMs int64) Position {
+	var tick int64
+	switch {
+	case c.Schedule != nil:
+		tick = c.Schedule.TickAt(rule, seed, c.Phases, elapsedMs)
+	case c.TickMs > 0:
+		tick = elapsedMs / c.TickMs
+	}
+	return c.Phases.PositionAt(rule, seed, tick)
+}
+
+// Paused reports whether the session is paused at the given time.
+func (c Clock) Paused(now time.Time) bool {
+	for _, p := range c.Pauses {
+		if !now.Before(p.From) && (p.Until.IsZero() || now.Before(p.Until)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClockStateAt computes the deterministic state at a given time for a rule,
+// counting only the clock's active time. It also reports the game phase:
+// in the lobby the state is zero, in an intermission it holds the break.
+func ClockStateAt(rule Rule, seed int64, clock Clock, now time.Time) State {
+	if !clock.Started(now) {
+		return State{}
+	}
+
+	position := clock.PositionAt(rule, seed, now)
+	if position.Phase == GamePhaseLobby {
+		return State{Phase: GamePhaseLobby, TicksLeft: position.TicksLeft}
+	}
+
+	state := RuleStateAtStep(rule, seed, position.Step)
+	state.Phase = position.Phase
+	state.TicksLeft = position.TicksLeft
+	return state
+}
@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClock_StepAtMatchesStepAt(t *testing.T) {
+	startAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	clock := Clock{StartAt: startAt, TickMs: 100}
+
+	for ms := int64(-500); ms < 5000; ms += 37 {
+		now := startAt.Add(time.Duration(ms) * time.Millisecond)
+		if got, expected := clock.StepAt(nil, 0, now), StepAt(startAt, 100, now); got != expected {
+			t.Fatalf("at %dms: expected step %d, got %d", ms, expected, got)
+		}
+	}
+}
+
+func TestClock_Pauses(t *testing.T) {
+	startAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	at := func(seconds float64) time.Time {
+		return startAt.Add(time.Duration(seconds * float64(time.Second)))
+	}
+
+	clock := Clock{
+		StartAt: startAt,
+		TickMs:  100,
+		Pauses: []Pause{
+			{From: at(10), Until: at(20)}, // 10s pause
+			{From: at(30)},                // Still paused
+		},
+	}
+
+	tests := []struct {
+		name   string
+		now    time.Time
+		step   int64
+		paused bool
+	}{
+		{name: "before pause", now: at(5), step: 50},
+		{name: "at pause start", now: at(10), step: 100, paused: true},
+		{name: "during pause", now: at(15), step: 100, paused: true},
+		{name: "at resume", now: at(20), step: 100},
+		{name: "after resume", now: at(25.05), step: 150},
+		{name: "ongoing pause", now: at(30), step: 200, paused: true},
+		{name: "long into ongoing pause", now: at(3600), step: 200, paused: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clock.StepAt(nil, 0, tt.now); got != tt.step {
+				t.Errorf("Expected step %d, got %d", tt.step, got)
+			}
+			if got := clock.Paused(tt.now); got != tt.paused {
+				t.Errorf("Expected paused %v, got %v", tt.paused, got)
+			}
+		})
+	}
+}
+
+func TestClockStateAt_NoJumpOnResume(t *testing.T) {
+	startAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	pauseAt := startAt.Add(12345 * time.Millisecond)
+	resumeAt := pauseAt.Add(time.Hour)
+
+	rule := CounterRule{}
+	seed := int64(12345)
+	clock := Clock{StartAt: startAt, TickMs: 100, Pauses: []Pause{{From: pauseAt, Until: resumeAt}}}
+
+	atPause := ClockStateAt(rule, seed, clock, pauseAt)
+	atResume := ClockStateAt(rule, seed, clock, resumeAt)
+	if atPause != atResume {
+		t.Errorf("State changed while paused: %+v → %+v", atPause, atResume)
+	}
+
+	// The remaining 55ms of the interrupted tick elapse after resuming
+	next := ClockStateAt(rule, seed, clock, resumeAt.Add(55*time.Millisecond))
+	if next.Step != atPause.Step+1 {
+		t.Errorf("Expected step %d after resume, got %d", atPause.Step+1, next.Step)
+	}
+
+	// Pauses before start do not count
+	early := Clock{StartAt: startAt, TickMs: 100, Pauses: []Pause{{From: startAt.Add(-time.Hour), Until: startAt.Add(time.Second)}}}
+	if got := early.StepAt(nil, 0, startAt.Add(3*time.Second)); got != 20 {
+		t.Errorf("Expected step 20, got %d", got)
+	}
+
+	if state := ClockStateAt(rule, seed, clock, startAt.Add(-time.Second)); state != (State{}) {
+		t.Errorf("Expected zero state before start, got %+v", state)
+	}
+}
@@ -0,0 +1,305 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+)
+
+// ModeCrash is the mode of the crash-multiplier rule.
+const ModeCrash = "crash"
+
+// Default crash rule parameters.
+const (
+	DefaultHouseEdge = 0.01 // 1% house edge
+	DefaultGrowth    = 0.01 // Multiplier grows by e^0.01 per tick
+
+	// MinGrowth keeps consecutive multipliers apart by far more than the
+	// fixed-point error, so the multiplier never shrinks from tick to tick.
+	MinGrowth = 1e-6
+)
+
+// CrashRule is a crash-multiplier mechanic.
+//
+// Each round the multiplier grows exponentially from 1.00x:
+//
+//	multiplier(tick) = e^(growth * (tick - 1)), tick = 1, 2, ...
+//
+// and the round breaks ("crashes") when the multiplier would reach the
+// round's crash point. The crash point is derived from (seed, round) via the
+// engine PRNG:
+//
+//	crashPoint = max(1.00, floor(100 * (1 - houseEdge) / (1 - u)) / 100)
+//
+// where u is uniform in [0, 1). Multipliers and crash points are rounded
+// down to two decimals. Every round lasts at least one tick; a round whose
+// crash point is 1.00x busts instantly, so its single 1.00x tick cannot be
+// cashed out. A round lasts about (1 - houseEdge) / growth ticks on average.
+//
+// The crash point only uses basic IEEE 754 operations, which are exactly
+// rounded on every platform. The multiplier is computed with integer
+// fixed-point arithmetic rather than math.Exp, whose last bit differs
+// between CPUs and languages, so every node and port breaks on the same tick.
+type CrashRule struct {
+	HouseEdge float64   // Fraction of expected payout kept by the house, [0, 1)
+	Growth    float64   // Exponential growth rate per tick, [MinGrowth, 1]
+	Algorithm Algorithm // Source of randomness (nil = Version1)
+
+	powers *growthPowers // Cached by NewRule; computed on demand otherwise
+}
+
+// CrashOutcome is the rule-specific state of a crash round.
+type CrashOutcome struct {
+	Multiplier float64 `json:"multiplier"`            // Current multiplier
+	CrashPoint float64 `json:"crash_point,omitempty"` // Set on the crash (break) step only
+}
+
+func newCrashRule(config RuleConfig) (Rule, error) {
+	if config.Interval != nil {
+		return nil, fmt.Errorf("mode %q derives round length from the crash point and takes no interval settings", ModeCrash)
+	}
+
+	algorithm, err := AlgorithmFor(config.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := CrashRule{
+		HouseEdge: DefaultHouseEdge,
+		Growth:    DefaultGrowth,
+		Algorithm: algorithm,
+	}
+
+	for name, value := range config.Params {
+		switch name {
+		case "house_edge":
+			rule.HouseEdge = value
+		case "growth":
+			rule.Growth = value
+		default:
+			return nil, fmt.Errorf("unknown parameter %q for mode %q", name, ModeCrash)
+		}
+	}
+
+	if math.IsNaN(rule.HouseEdge) || rule.HouseEdge < 0 || rule.HouseEdge >= 1 {
+		return nil, fmt.Errorf("house_edge must be in [0, 1)")
+	}
+	if math.IsNaN(rule.Growth) || rule.Growth < MinGrowth || rule.Growth > 1 {
+		return nil, fmt.Errorf("growth must be in [%g, 1]", MinGrowth)
+	}
+	if rule.MeanInterval() < MinMeanInterval {
+		return nil, fmt.Errorf("mean round length (1 - house_edge) / growth must be at least %d ticks", MinMeanInterval)
+	}
+
+	rule.powers = newGrowthPowers(rule.Growth)
+	return rule, nil
+}
+
+// Mode implements Rule.
+func (r CrashRule) Mode() string {
+	return ModeCrash
+}
+
+// Params implements Rule.
+func (r CrashRule) Params() map[string]float64 {
+	return map[string]float64{
+		"house_edge": r.HouseEdge,
+		"growth":     r.Growth,
+	}
+}
+
+// Version implements Rule.
+func (r CrashRule) Version() int {
+	return r.algorithm().Version()
+}
+
+// MeanInterval returns the approximate mean round length in ticks,
+// (1 - houseEdge) / growth.
+func (r CrashRule) MeanInterval() float64 {
+	return (1 - r.HouseEdge) / r.Growth
+}
+
+// BreakInterval implements Rule.
+// The round lasts as many ticks as the multiplier stays below the crash point.
+func (r CrashRule) BreakInterval(seed int64, round int64) int64 {
+	crashCents := r.crashCents(seed, round)
+	if crashCents <= 100 {
+		return 1
+	}
+
+	// The floating point estimate is only a starting point; the integer
+	// comparisons below decide the tick, so that the last displayed
+	// multiplier is always below the crash point.
+	ticks := int64(math.Ceil(math.Log(float64(crashCents)/100) / r.Growth))
+	if ticks < 1 {
+		ticks = 1
+	}
+
+	powers := r.growthPowers()
+	for ticks > 1 && powers.multiplierCents(ticks) >= crashCents {
+		ticks--
+	}
+	for powers.multiplierCents(ticks+1) < crashCents {
+		ticks++
+	}
+	return ticks
+}
+
+// Outcome implements Rule. Returns a CrashOutcome.
+// On a break step the multiplier is the crash point of the round that crashed.
+func (r CrashRule) Outcome(seed int64, state State) interface{} {
+	if state.Broken {
+		crashPoint := r.CrashPoint(seed, state.Round-1)
+		return CrashOutcome{
+			Multiplier: crashPoint,
+			CrashPoint: crashPoint,
+		}
+	}
+
+	return CrashOutcome{
+		Multiplier: r.MultiplierAt(state.Value),
+	}
+}
+
+// Payout implements Payer: the stake times the multiplier shown at the
+// state, rounded down. Payouts that do not fit an int64 are capped.
+func (r CrashRule) Payout(stake int64, state State) int64 {
+	cents := big.NewInt(r.growthPowers().multiplierCents(state.Value))
+	payout := cents.Mul(cents, big.NewInt(stake))
+	payout.Quo(payout, big.NewInt(100))
+	if !payout.IsInt64() {
+		return math.MaxInt64
+	}
+	return payout.Int64()
+}
+
+// CanCashOut implements CashOuter: only a multiplier below the round's crash
+// point can be cashed out, which rules out the tick of an instant crash.
+func (r CrashRule) CanCashOut(seed int64, state State) bool {
+	return r.growthPowers().multiplierCents(state.Value) < r.crashCents(seed, state.Round)
+}
+
+// CrashPoint returns the multiplier at which the given round crashes.
+func (r CrashRule) CrashPoint(seed int64, round int64) float64 {
+	return float64(r.crashCents(seed, round)) / 100
+}
+
+// crashCents returns the crash point of a round in hundredths.
+func (r CrashRule) crashCents(seed int64, round int64) int64 {
+	u := r.algorithm().RoundUniform(seed, round)
+
+	// At most 100 * 2^53, which fits an int64
+	cents := int64(math.Floor(100 * (1 - r.HouseEdge) / (1 - u)))
+	if cents < 100 {
+		return 100
+	}
+	return cents
+}
+
+// MultiplierAt returns the multiplier shown at the given tick of a round.
+// Ticks before the first one (e.g. before start) show 1.00x.
+func (r CrashRule) MultiplierAt(tick int64) float64 {
+	return float64(r.growthPowers().multiplierCents(tick)) / 100
+}
+
+// growthPowers returns the rule's cached powers of the growth factor.
+func (r CrashRule) growthPowers() *growthPowers {
+	if r.powers == nil {
+		return newGrowthPowers(r.Growth)
+	}
+	return r.powers
+}
+
+// algorithm returns the rule's algorithm, defaulting to Version1.
+func (r CrashRule) algorithm() Algorithm {
+	if r.Algorithm == nil {
+		return algorithmV1{}
+	}
+	return r.Algorithm
+}
+
+// fixedPoint is the positive number m * 2^(e-63), with m in [2^63, 2^64).
+type fixedPoint struct {
+	m uint64
+	e int
+}
+
+// fixedOne is 1 as a fixedPoint.
+var fixedOne = fixedPoint{m: 1 << 63}
+
+// maxFixedExponent bounds the multipliers computed exactly: 2^56 in
+// hundredths still fits an int64 and is far above any crash point.
+const maxFixedExponent = 55
+
+// mul returns a * b, rounded down to 64 bits of mantissa.
+func (a fixedPoint) mul(b fixedPoint) fixedPoint {
+	hi, lo := bits.Mul64(a.m, b.m)
+	if hi >= 1<<63 {
+		return fixedPoint{m: hi, e: a.e + b.e + 1}
+	}
+	return fixedPoint{m: hi<<1 | lo>>63, e: a.e + b.e}
+}
+
+// growthPowers holds the per-tick growth factor e^growth raised to the
+// powers 1, 2, 4, 8, ... up to the first one above 2^maxFixedExponent.
+type growthPowers struct {
+	powers []fixedPoint
+}
+
+// newGrowthPowers computes the powers of e^growth for growth in (0, 1].
+//
+// e^growth is summed as a Taylor series in 2.62 fixed point, rounding each
+// term down; every further power squares the previous one. Ports must
+// follow the same steps to show the same multipliers.
+func newGrowthPowers(growth float64) *growthPowers {
+	const one = 1 << 62
+
+	// growth * 2^62 is exact; rounding it is the only float step
+	g := uint64(math.Round(growth * one))
+	sum, term := uint64(one), uint64(one)
+	for n := uint64(1); term > 0; n++ {
+		hi, lo := bits.Mul64(term, g)
+		term = (hi<<2 | lo>>62) / n
+		sum += term
+	}
+
+	// sum is e^growth in [1, e] scaled by 2^62: normalize the mantissa
+	shift := bits.LeadingZeros64(sum)
+	factor := fixedPoint{m: sum << shift, e: 1 - shift}
+
+	powers := []fixedPoint{factor}
+	for len(powers) < 63 && powers[len(powers)-1].e <= maxFixedExponent {
+		last := powers[len(powers)-1]
+		powers = append(powers, last.mul(last))
+	}
+	return &growthPowers{powers: powers}
+}
+
+// multiplierCents returns the multiplier at a tick in hundredths, rounded
+// down: 100 * e^(growth * (tick - 1)), computed as the product of the powers
+// for the set bits of tick - 1, lowest first. Multipliers past
+// 2^maxFixedExponent are capped at math.MaxInt64.
+func (p *growthPowers) multiplierCents(tick int64) int64 {
+	if tick <= 1 {
+		return 100
+	}
+
+	x := fixedOne
+	for i, n := 0, uint64(tick-1); n > 0; i, n = i+1, n>>1 {
+		if n&1 == 0 {
+			continue
+		}
+		if i >= len(p.powers) {
+			return math.MaxInt64
+		}
+		if x = x.mul(p.powers[i]); x.e > maxFixedExponent {
+			return math.MaxInt64
+		}
+	}
+
+	// floor(100 * m * 2^(e-63)); below 100 * 2^56, so it fits an int64
+	hi, lo := bits.Mul64(x.m, 100)
+	shift := uint(63 - x.e)
+	return int64(hi<<(64-shift) | lo>>shift)
+}
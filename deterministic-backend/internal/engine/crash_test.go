@@ -0,0 +1,211 @@
+package engine
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewCrashRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]float64
+		wantErr bool
+	}{
+		{name: "defaults", params: nil},
+		{name: "custom", params: map[string]float64{"house_edge": 0.03, "growth": 0.005}},
+		{name: "zero house edge", params: map[string]float64{"house_edge": 0}},
+		{name: "negative house edge", params: map[string]float64{"house_edge": -0.1}, wantErr: true},
+		{name: "house edge of 1", params: map[string]float64{"house_edge": 1}, wantErr: true},
+		{name: "zero growth", params: map[string]float64{"growth": 0}, wantErr: true},
+		{name: "growth below minimum", params: map[string]float64{"growth": MinGrowth / 2}, wantErr: true},
+		{name: "mean round at minimum", params: map[string]float64{"house_edge": 0, "growth": 0.1}},
+		{name: "mean round too short", params: map[string]float64{"house_edge": 0.99, "growth": 1}, wantErr: true},
+		{name: "unknown parameter", params: map[string]float64{"speed": 2}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRule(ModeCrash, RuleConfig{Params: tt.params})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCrashRule_RoundEndsBeforeCrashPoint(t *testing.T) {
+	rule := CrashRule{HouseEdge: DefaultHouseEdge, Growth: DefaultGrowth}
+	seed := int64(987654321)
+
+	for round := int64(0); round < 10000; round++ {
+		crashPoint := rule.CrashPoint(seed, round)
+		if crashPoint < 1 {
+			t.Fatalf("round %d: crash point %v below 1.00", round, crashPoint)
+		}
+
+		ticks := rule.BreakInterval(seed, round)
+		if ticks < 1 {
+			t.Fatalf("round %d: expected at least one tick, got %d", round, ticks)
+		}
+
+		// Every displayed multiplier is below the crash point,
+		// except for the single tick of an instant (1.00x) crash
+		if last := rule.MultiplierAt(ticks); last >= crashPoint && crashPoint > 1 {
+			t.Fatalf("round %d: last multiplier %v reached crash point %v", round, last, crashPoint)
+		}
+		if next := rule.MultiplierAt(ticks + 1); next < crashPoint {
+			t.Fatalf("round %d: multiplier %v after the round is still below crash point %v", round, next, crashPoint)
+		}
+	}
+}
+
+func TestCrashRule_MultiplierAt(t *testing.T) {
+	rule := CrashRule{HouseEdge: DefaultHouseEdge, Growth: DefaultGrowth}
+
+	// Fixed-point multipliers, frozen. At tick 3000 the exact value is
+	// 1058014238280301.06 hundredths: ports must follow the fixed-point
+	// steps rather than exp to match
+	tests := []struct {
+		tick     int64
+		expected float64
+	}{
+		{tick: 0, expected: 1},
+		{tick: 1, expected: 1},
+		{tick: 2, expected: 1.01},
+		{tick: 70, expected: 1.99},
+		{tick: 101, expected: 2.71},
+		{tick: 461, expected: 99.48},
+		{tick: 1000, expected: 21807.29},
+		{tick: 2000, expected: 480337721.05},
+		{tick: 3000, expected: 10580142382802.99},
+	}
+
+	for _, tt := range tests {
+		if got := rule.MultiplierAt(tt.tick); got != tt.expected {
+			t.Errorf("tick %d: expected %v, got %v", tt.tick, tt.expected, got)
+		}
+	}
+
+	// The multiplier never shrinks, even at the smallest growth
+	slow := CrashRule{Growth: MinGrowth}
+	last := slow.MultiplierAt(1)
+	for tick := int64(2); tick < 40000000; tick += 9973 {
+		m := slow.MultiplierAt(tick)
+		if m < last {
+			t.Fatalf("tick %d: multiplier %v below %v", tick, m, last)
+		}
+		last = m
+	}
+	if math.IsInf(last, 0) || last < 1e6 {
+		t.Errorf("Expected the multiplier to keep growing, got %v", last)
+	}
+}
+
+func TestCrashRule_Outcome(t *testing.T) {
+	rule := CrashRule{HouseEdge: DefaultHouseEdge, Growth: DefaultGrowth}
+	seed := int64(12345)
+
+	// Walk to the first crash
+	var step int64
+	for step = 0; step < 100000; step++ {
+		if RuleStateAtStep(rule, seed, step).Broken {
+			break
+		}
+	}
+
+	before := RuleStateAtStep(rule, seed, step-1)
+	at := RuleStateAtStep(rule, seed, step)
+
+	beforeOutcome := rule.Outcome(seed, before).(CrashOutcome)
+	atOutcome := rule.Outcome(seed, at).(CrashOutcome)
+
+	expectedCrash := rule.CrashPoint(seed, 0)
+	if atOutcome.CrashPoint != expectedCrash {
+		t.Errorf("Expected crash point %v, got %v", expectedCrash, atOutcome.CrashPoint)
+	}
+	if beforeOutcome.CrashPoint != 0 {
+		t.Errorf("Crash point must not be disclosed before the crash, got %v", beforeOutcome.CrashPoint)
+	}
+	if beforeOutcome.Multiplier != rule.MultiplierAt(before.Value) {
+		t.Errorf("Expected multiplier %v, got %v", rule.MultiplierAt(before.Value), beforeOutcome.Multiplier)
+	}
+
+	// First tick of every round shows 1.00x
+	after := RuleStateAtStep(rule, seed, step+1)
+	if m := rule.Outcome(seed, after).(CrashOutcome).Multiplier; m != 1 {
+		t.Errorf("Expected 1.00x on the first tick of a round, got %v", m)
+	}
+}
+
+func TestCrashRule_Payout(t *testing.T) {
+	rule := CrashRule{HouseEdge: DefaultHouseEdge, Growth: DefaultGrowth}
+
+	tests := []struct {
+		name     string
+		stake    int64
+		value    int64
+		expected int64
+	}{
+		{name: "first tick pays the stake", stake: 1000, value: 1, expected: 1000},
+		{name: "multiplier 1.99x", stake: 1000, value: 70, expected: 1990},
+		{name: "rounded down", stake: 3, value: 70, expected: 5},
+		{name: "capped", stake: math.MaxInt64, value: 70, expected: math.MaxInt64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if payout := rule.Payout(tt.stake, State{Value: tt.value}); payout != tt.expected {
+				t.Errorf("Expected payout %d, got %d", tt.expected, payout)
+			}
+		})
+	}
+}
+
+func TestCrashRule_CanCashOut(t *testing.T) {
+	rule := CrashRule{HouseEdge: DefaultHouseEdge, Growth: DefaultGrowth}
+	seed := int64(987654321)
+
+	instant := 0
+	for round := int64(0); round < 10000; round++ {
+		ticks := rule.BreakInterval(seed, round)
+		first := rule.CanCashOut(seed, State{Round: round, Value: 1})
+		last := rule.CanCashOut(seed, State{Round: round, Value: ticks})
+
+		if rule.CrashPoint(seed, round) <= 1 {
+			instant++
+			if first || last {
+				t.Fatalf("round %d: instant crash can be cashed out", round)
+			}
+			continue
+		}
+		if !first || !last {
+			t.Fatalf("round %d: expected ticks 1 to %d to be cashable", round, ticks)
+		}
+	}
+
+	if instant == 0 {
+		t.Fatal("Expected at least one instant crash in 10000 rounds")
+	}
+}
+
+func TestCrashRule_HouseEdge(t *testing.T) {
+	// P(crashPoint >= x) should be close to (1 - houseEdge) / x
+	rule := CrashRule{HouseEdge: 0.04, Growth: DefaultGrowth}
+	seed := int64(42)
+	rounds := 200000
+
+	for _, target := range []float64{1.5, 2, 5} {
+		hits := 0
+		for round := int64(0); round < int64(rounds); round++ {
+			if rule.CrashPoint(seed, round) >= target {
+				hits++
+			}
+		}
+
+		got := float64(hits) / float64(rounds)
+		expected := (1 - rule.HouseEdge) / target
+		if math.Abs(got-expected) > 0.01 {
+			t.Errorf("P(crash >= %v): expected ~%.3f, got %.3f", target, expected, got)
+		}
+	}
+}
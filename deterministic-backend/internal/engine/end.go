@@ -0,0 +1,78 @@
+package engine
+
+import (
+	"errors"
+	"time"
+)
+
+// EndCondition ends a session deterministically. Zero fields are unset;
+// the first condition met ends the session.
+//
+// The end is derived from the clock alone, so every node freezes the
+// session at the same step without having to record when it happened.
+type EndCondition struct {
+	MaxRounds     int64 // End at the break that completes this many rounds
+	MaxDurationMs int64 // End after this much active time
+	StopAtStep    int64 // End at this step
+}
+
+// Validate checks the end condition.
+func (c EndCondition) Validate() error {
+	switch {
+	case c.MaxRounds < 0 || c.MaxDurationMs < 0 || c.StopAtStep < 0:
+		return errors.New("end conditions must not be negative")
+	case c.MaxRounds == 0 && c.MaxDurationMs == 0 && c.StopAtStep == 0:
+		return errors.New("end needs max_rounds, max_duration_ms or stop_at_step")
+	}
+	return nil
+}
+
+// endStep returns the step at which the step-based conditions end a
+// session that has reached step, or -1 if none has been met yet.
+func (c EndCondition) endStep(rule Rule, seed int64, step int64) int64 {
+	end := int64(-1)
+	if c.StopAtStep > 0 && step >= c.StopAtStep {
+		end = c.StopAtStep
+	}
+	if c.MaxRounds > 0 && RuleStateAtStep(rule, seed, step).Round >= c.MaxRounds {
+		// The break step that starts round MaxRounds
+		breakAt := int64(0)
+		for round := int64(0); round < c.MaxRounds; round++ {
+			breakAt = breakStepAfter(rule, seed, round, breakAt)
+		}
+		if end < 0 || breakAt < end {
+			end = breakAt
+		}
+	}
+	return end
+}
+
+// Ended reports whether the clock's end condition is met at the given time.
+func (c Clock) Ended(rule Rule, seed int64, now time.Time) bool {
+	_, ended := c.position(rule, seed, now)
+	return ended
+}
+
+// position returns the position at the given time, frozen once the end
+// condition is met, and whether it is.
+func (c Clock) position(rule Rule, seed int64, now time.Time) (Position, bool) {
+	if !c.Started(now) {
+		return Position{}, false
+	}
+
+	elapsedMs := c.ActiveElapsed(now).Milliseconds()
+	ended := false
+	if c.End != nil && c.End.MaxDurationMs > 0 && elapsedMs >= c.End.MaxDurationMs {
+		elapsedMs = c.End.MaxDurationMs
+		ended = true
+	}
+	position := c.positionAtElapsed(rule, seed, elapsedMs)
+
+	// A step-based end reached by then came first
+	if c.End != nil && position.Phase != GamePhaseLobby {
+		if end := c.End.endStep(rule, seed, position.Step); end >= 0 {
+			return Position{Step: end, Phase: GamePhaseRunning}, true
+		}
+	}
+	return position, ended
+}
@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEndCondition_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		end     EndCondition
+		wantErr bool
+	}{
+		{name: "max rounds", end: EndCondition{MaxRounds: 10}},
+		{name: "all conditions", end: EndCondition{MaxRounds: 10, MaxDurationMs: 60000, StopAtStep: 500}},
+		{name: "empty", end: EndCondition{}, wantErr: true},
+		{name: "negative step", end: EndCondition{StopAtStep: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.end.Validate()
+			if tt.wantErr && err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestClock_End(t *testing.T) {
+	// Round 0 is steps 0-2, round 1 breaks at 3, round 2 at 7, round 3 at 11
+	rule := fixedRule{interval: 3}
+	startAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	at := func(ms int64) time.Time { return startAt.Add(time.Duration(ms) * time.Millisecond) }
+
+	tests := []struct {
+		name     string
+		end      EndCondition
+		now      time.Time
+		expected State
+		ended    bool
+	}{
+		{
+			name:     "before max rounds",
+			end:      EndCondition{MaxRounds: 2},
+			now:      at(650),
+			expected: State{Step: 6, Value: 3, Round: 1, Phase: GamePhaseRunning},
+		},
+		{
+			name:     "max rounds ends at the break",
+			end:      EndCondition{MaxRounds: 2},
+			now:      at(700),
+			expected: State{Step: 7, Value: 0, Round: 2, Broken: true, Phase: GamePhaseRunning},
+			ended:    true,
+		},
+		{
+			name:     "frozen after max rounds",
+			end:      EndCondition{MaxRounds: 2},
+			now:      at(60000),
+			expected: State{Step: 7, Value: 0, Round: 2, Broken: true, Phase: GamePhaseRunning},
+			ended:    true,
+		},
+		{
+			name:     "stop at step",
+			end:      EndCondition{StopAtStep: 5},
+			now:      at(60000),
+			expected: State{Step: 5, Value: 2, Round: 1, Phase: GamePhaseRunning},
+			ended:    true,
+		},
+		{
+			name:     "max duration",
+			end:      EndCondition{MaxDurationMs: 1050},
+			now:      at(60000),
+			expected: State{Step: 10, Value: 3, Round: 2, Phase: GamePhaseRunning},
+			ended:    true,
+		},
+		{
+			name:     "earliest condition wins",
+			end:      EndCondition{MaxRounds: 3, MaxDurationMs: 5000, StopAtStep: 9},
+			now:      at(60000),
+			expected: State{Step: 9, Value: 2, Round: 2, Phase: GamePhaseRunning},
+			ended:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end := tt.end
+			clock := Clock{StartAt: startAt, TickMs: 100, End: &end}
+			if got := ClockStateAt(rule, 0, clock, tt.now); got != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+			if got := clock.Ended(rule, 0, tt.now); got != tt.ended {
+				t.Errorf("Expected ended %v, got %v", tt.ended, got)
+			}
+		})
+	}
+}
+
+func TestClock_EndCountsActiveTime(t *testing.T) {
+	rule := CounterRule{}
+	startAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	clock := Clock{
+		StartAt: startAt,
+		TickMs:  100,
+		End:     &EndCondition{MaxDurationMs: 2000},
+		Pauses:  []Pause{{From: startAt.Add(time.Second), Until: startAt.Add(11 * time.Second)}},
+	}
+
+	// 10s paused: 2s of active time end the session 12s after start
+	if clock.Ended(rule, 12345, startAt.Add(11900*time.Millisecond)) {
+		t.Error("Expected the session to run until 12s")
+	}
+	if !clock.Ended(rule, 12345, startAt.Add(12*time.Second)) {
+		t.Error("Expected the session to end at 12s")
+	}
+	if step := clock.StepAt(rule, 12345, startAt.Add(time.Hour)); step != 20 {
+		t.Errorf("Expected the end at step 20, got %d", step)
+	}
+}
+
+func TestClockEvents_StopAtEnd(t *testing.T) {
+	rule := fixedRule{interval: 3}
+	startAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	clock := Clock{StartAt: startAt, TickMs: 100, End: &EndCondition{MaxRounds: 2}}
+
+	events := collectEvents(ClockEvents(rule, 0, clock, startAt.Add(-time.Second), startAt.Add(time.Hour), EventBreak))
+	if len(events) != 2 || events[1].Step != 7 {
+		t.Errorf("Expected 2 breaks up to step 7, got %+v", events)
+	}
+}
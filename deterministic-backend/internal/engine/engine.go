@@ -7,11 +7,16 @@ import (
 
 // State represents the computed deterministic state at a given point in time.
 // All fields are computed deterministically from (seed, startAt, tickMs, now).
+//
+// Phase and TicksLeft are only set by ClockStateAt, which knows the
+// session's lobby and intermission; step-based functions leave them empty.
 type State struct {
-	Step   int64 // Number of ticks since start (0-based)
-	Value  int64 // Counter value (resets on break)
-	Round  int64 // Round number (increments after each break)
-	Broken bool  // Whether the sequence is currently 'broken' (just reset)
+	Step      int64     // Number of ticks since start (0-based)
+	Value     int64     // Counter value (resets on break)
+	Round     int64     // Round number (increments after each break)
+	Broken    bool      // Whether the sequence is currently 'broken' (just reset)
+	Phase     GamePhase // Game phase (lobby, running or intermission)
+	TicksLeft int64     // Ticks until the lobby or intermission ends
 }
 
 // StateAt computes the deterministic state at a given time.
@@ -50,39 +55,70 @@ func StateAt(seed int64, startAt time.Time, tickMs int64, now time.Time) State {
 		}
 	}
 
-	// Simulate from step 0 to current step to compute state
-	// This ensures deterministic computation regardless of step value
-	currentValue := int64(0)
-	currentRound := int64(0)
-	stepWithinRound := int64(0)
-	isBroken := false
-
-	// Track when the next break should occur
-	nextBreakAt := computeBreakInterval(seed, currentRound)
-
-	for s := int64(0); s <= step; s++ {
-		// Check if we should break at this step
-		if stepWithinRound >= nextBreakAt && s > 0 {
-			// Break: reset counter and increment round
-			currentRound++
-			stepWithinRound = 0
-			currentValue = 0
-			isBroken = true
-			// Compute next break interval for new round
-			nextBreakAt = computeBreakInterval(seed, currentRound)
-		} else {
-			// Increment counter
-			stepWithinRound++
-			currentValue++
-			isBroken = false
+	return StateAtStep(seed, step)
+}
+
+// StateAtStep computes the deterministic state at a given step.
+//
+// Instead of simulating every tick, it jumps from one break to the next
+// using computeBreakInterval, so the cost is O(rounds) rather than O(steps).
+// The result is identical to simulating the counter tick by tick:
+//   - Round 0 covers steps [0, interval) with Value = step + 1
+//   - Every later round starts with a break step (Value 0, Broken true)
+//     followed by interval steps with Value 1..interval
+//
+// Parameters:
+//   - seed: The base seed value (determines break pattern)
+//   - step: Step index (negative steps are treated as before start)
+//
+// Returns:
+//   - State with Step, Value, Round, and Broken fields
+func StateAtStep(seed int64, step int64) State {
+	return stateAtStep(step, func(round int64) int64 {
+		return computeBreakInterval(seed, round)
+	})
+}
+
+// stateAtStep walks the round/break timeline for the given round lengths.
+// interval(round) returns how many increments a round lasts before it breaks.
+func stateAtStep(step int64, interval func(round int64) int64) State {
+	if step < 0 {
+		return State{}
+	}
+
+	// Round 0 has no leading break step, so it is handled separately.
+	// The first break can never happen at step 0.
+	breakAt := interval(0)
+	if breakAt < 1 {
+		breakAt = 1
+	}
+	if step < breakAt {
+		return State{
+			Step:   step,
+			Value:  step + 1,
+			Round:  0,
+			Broken: false,
+		}
+	}
+
+	// Skip whole rounds: round r starts with a break at breakAt and
+	// lasts interval+1 steps (the break step plus its increments).
+	round := int64(1)
+	for {
+		next := breakAt + interval(round) + 1
+		if step < next {
+			break
 		}
+		breakAt = next
+		round++
 	}
 
+	value := step - breakAt
 	return State{
 		Step:   step,
-		Value:  currentValue,
-		Round:  currentRound,
-		Broken: isBroken,
+		Value:  value,
+		Round:  round,
+		Broken: value == 0,
 	}
 }
 
@@ -125,11 +161,7 @@ func StepAt(startAt time.Time, tickMs int64, now time.Time) int64 {
 // 2. Use xorshift PRNG to generate pseudo-random value
 // 3. Map to range [100, 300]
 func computeBreakInterval(seed int64, round int64) int64 {
-	// Combine seed and round for unique input
-	combined := seed ^ round
-
-	// Use xorshift64 for deterministic pseudo-random number
-	rng := xorshift64(uint64(combined))
+	rng := roundRandom(seed, round)
 
 	// Map to range [100, 300]
 	// rng is in range [0, 2^64-1], we want [100, 300]
@@ -139,6 +171,30 @@ func computeBreakInterval(seed int64, round int64) int64 {
 	return interval
 }
 
+// roundRandom returns the deterministic pseudo-random value for a round.
+// Combines seed and round into a unique input and runs it through xorshift64.
+func roundRandom(seed int64, round int64) uint64 {
+	return xorshift64(uint64(seed ^ round))
+}
+
+// uniformWarmup is the number of extra xorshift steps applied before the
+// high bits of the PRNG output are used.
+const uniformWarmup = 4
+
+// roundUniform returns a deterministic uniform float in [0, 1) for a round.
+//
+// A single xorshift step leaves the high bits empty for small inputs,
+// so the generator is advanced a few more times before they are used.
+func roundUniform(seed int64, round int64) float64 {
+	rng := roundRandom(seed, round)
+	for i := 0; i < uniformWarmup; i++ {
+		rng = xorshift64(rng)
+	}
+
+	// Top 53 bits → uniform float in [0, 1)
+	return float64(rng>>11) / (1 << 53)
+}
+
 // xorshift64 implements a 64-bit xorshift PRNG.
 // This is a pure function: same input → same output.
 //
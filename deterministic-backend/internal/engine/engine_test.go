@@ -1,10 +1,45 @@
 package engine
 
 import (
+	"math"
+	"math/rand"
 	"testing"
 	"time"
 )
 
+// simulateStateAt is the original tick-by-tick simulation of the counter.
+// It is kept as a reference implementation to prove that StateAtStep,
+// which skips whole rounds, produces identical output.
+func simulateStateAt(seed int64, step int64) State {
+	currentValue := int64(0)
+	currentRound := int64(0)
+	stepWithinRound := int64(0)
+	isBroken := false
+
+	nextBreakAt := computeBreakInterval(seed, currentRound)
+
+	for s := int64(0); s <= step; s++ {
+		if stepWithinRound >= nextBreakAt && s > 0 {
+			currentRound++
+			stepWithinRound = 0
+			currentValue = 0
+			isBroken = true
+			nextBreakAt = computeBreakInterval(seed, currentRound)
+		} else {
+			stepWithinRound++
+			currentValue++
+			isBroken = false
+		}
+	}
+
+	return State{
+		Step:   step,
+		Value:  currentValue,
+		Round:  currentRound,
+		Broken: isBroken,
+	}
+}
+
 func TestStepAt(t *testing.T) {
 	startAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
 	tickMs := int64(100)
@@ -321,6 +356,91 @@ func TestStateAt_DifferentSeeds(t *testing.T) {
 	}
 }
 
+func TestStateAtStep_MatchesSimulation(t *testing.T) {
+	// Walk every step of many seeds and compare against the reference
+	// simulation. The reference is advanced incrementally so that millions
+	// of (seed, step) pairs can be checked cheaply.
+	seeds := 1000
+	steps := int64(5000)
+	if testing.Short() {
+		seeds = 50
+	}
+
+	rng := rand.New(rand.NewSource(42))
+	edgeSeeds := []int64{0, 1, -1, 12345, math.MaxInt64, math.MinInt64}
+
+	for i := 0; i < seeds; i++ {
+		var seed int64
+		if i < len(edgeSeeds) {
+			seed = edgeSeeds[i]
+		} else {
+			seed = int64(rng.Uint64())
+		}
+
+		// Incremental reference simulation (same rules as simulateStateAt)
+		value, round, withinRound := int64(0), int64(0), int64(0)
+		broken := false
+		nextBreakAt := computeBreakInterval(seed, 0)
+
+		for step := int64(0); step < steps; step++ {
+			if withinRound >= nextBreakAt && step > 0 {
+				round++
+				withinRound = 0
+				value = 0
+				broken = true
+				nextBreakAt = computeBreakInterval(seed, round)
+			} else {
+				withinRound++
+				value++
+				broken = false
+			}
+
+			expected := State{Step: step, Value: value, Round: round, Broken: broken}
+			if got := StateAtStep(seed, step); got != expected {
+				t.Fatalf("seed %d step %d: expected %+v, got %+v", seed, step, expected, got)
+			}
+		}
+	}
+}
+
+func TestStateAtStep_MatchesSimulationRandomPairs(t *testing.T) {
+	// Random (seed, step) pairs spread over a wider step range
+	pairs := 2000
+	if testing.Short() {
+		pairs = 100
+	}
+
+	rng := rand.New(rand.NewSource(7))
+	for i := 0; i < pairs; i++ {
+		seed := int64(rng.Uint64())
+		step := rng.Int63n(200000)
+
+		expected := simulateStateAt(seed, step)
+		if got := StateAtStep(seed, step); got != expected {
+			t.Fatalf("seed %d step %d: expected %+v, got %+v", seed, step, expected, got)
+		}
+	}
+}
+
+func TestStateAt_MatchesStateAtStep(t *testing.T) {
+	seed := int64(987654321)
+	startAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	tickMs := int64(100)
+
+	for step := int64(0); step < 2000; step++ {
+		now := startAt.Add(time.Duration(step) * time.Duration(tickMs) * time.Millisecond)
+		if got, expected := StateAt(seed, startAt, tickMs, now), StateAtStep(seed, step); got != expected {
+			t.Fatalf("step %d: StateAt %+v, StateAtStep %+v", step, got, expected)
+		}
+	}
+}
+
+func TestStateAtStep_NegativeStep(t *testing.T) {
+	if state := StateAtStep(12345, -1); state != (State{}) {
+		t.Errorf("Expected zero state for negative step, got %+v", state)
+	}
+}
+
 func BenchmarkStateAt(b *testing.B) {
 	seed := int64(12345)
 	startAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
@@ -333,6 +453,29 @@ func BenchmarkStateAt(b *testing.B) {
 	}
 }
 
+func BenchmarkStateAt_OneDay(b *testing.B) {
+	// A day of 10ms ticks: ~8.6M steps
+	seed := int64(12345)
+	startAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	tickMs := int64(10)
+	now := startAt.Add(24 * time.Hour)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		StateAt(seed, startAt, tickMs, now)
+	}
+}
+
+func BenchmarkSimulateStateAt(b *testing.B) {
+	// Reference simulation for comparison with BenchmarkStateAt
+	seed := int64(12345)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		simulateStateAt(seed, 10000)
+	}
+}
+
 func BenchmarkStepAt(b *testing.B) {
 	startAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
 	tickMs := int64(100)
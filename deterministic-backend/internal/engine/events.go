@@ -0,0 +1,186 @@
+package engine
+
+import "time"
+
+// EventKind identifies what happened at a step.
+type EventKind string
+
+// Event kinds
+const (
+	EventRoundStart EventKind = "round_start" // A round begins (step 0, then every break step)
+	EventBreak      EventKind = "break"       // A round ends; emitted before the next round's start
+	EventTick       EventKind = "tick"        // A step elapsed; carries the state at that step
+)
+
+// Event is something that happened at a step.
+//
+// At a break step the iterator yields break (Round = the round that
+// ended), then round_start (Round = the new round), then tick.
+type Event struct {
+	Kind   EventKind
+	Step   int64
+	Round  int64 // Round that starts, ends, or is in progress (tick)
+	Value  int64 // State value at Step (tick only)
+	Broken bool  // Whether Step is a break step (tick only)
+}
+
+// EventIterator yields the ordered events of a step range.
+//
+// It computes the state at the first step once (O(rounds)), then advances
+// round by round: ticks cost O(1) each, and without ticks it jumps from
+// break to break.
+type EventIterator struct {
+	rule Rule
+	seed int64
+
+	step int64 // Next step to emit
+	to   int64 // Last step to emit (inclusive)
+
+	round      int64 // Round in progress before step
+	roundStart int64 // Step the round in progress started at
+	nextBreak  int64 // Step of the next break
+
+	ticks   bool
+	rounds  bool
+	breaks  bool
+	pending []Event
+}
+
+// NewEventIterator returns an iterator over the events of steps in
+// [fromStep, toStep]. Without kinds it yields every kind; otherwise only
+// the given ones.
+func NewEventIterator(rule Rule, seed int64, fromStep, toStep int64, kinds ...EventKind) *EventIterator {
+	if fromStep < 0 {
+		fromStep = 0
+	}
+
+	it := &EventIterator{
+		rule: rule,
+		seed: seed,
+		step: fromStep,
+		to:   toStep,
+	}
+	if len(kinds) == 0 {
+		it.ticks, it.rounds, it.breaks = true, true, true
+	}
+	for _, kind := range kinds {
+		switch kind {
+		case EventTick:
+			it.ticks = true
+		case EventRoundStart:
+			it.rounds = true
+		case EventBreak:
+			it.breaks = true
+		}
+	}
+
+	// Position on the round in progress just before fromStep, so a break
+	// at fromStep itself is emitted
+	state := RuleStateAtStep(rule, seed, fromStep)
+	if state.Round == 0 {
+		it.nextBreak = breakStepAfter(rule, seed, 0, 0)
+		return it
+	}
+	if state.Broken {
+		it.round = state.Round - 1
+		it.nextBreak = fromStep
+		return it
+	}
+	it.round = state.Round
+	it.roundStart = fromStep - state.Value
+	it.nextBreak = breakStepAfter(rule, seed, state.Round, it.roundStart)
+	return it
+}
+
+// ClockEvents returns an iterator over the events of steps that elapse
+// after from, up to and including to. Chained calls over consecutive
+// windows (t0, t1], (t1, t2], ... yield every event exactly once; a window
+// starting before the clock's start or in the lobby includes step 0.
+// Lobby and intermission ticks yield no events.
+func ClockEvents(rule Rule, seed int64, clock Clock, from, to time.Time, kinds ...EventKind) *EventIterator {
+	fromStep := int64(0)
+	if position := clock.PositionAt(rule, seed, from); clock.Started(from) && position.Phase != GamePhaseLobby {
+		fromStep = position.Step + 1
+	}
+	toStep := int64(-1)
+	if position := clock.PositionAt(rule, seed, to); clock.Started(to) && position.Phase != GamePhaseLobby {
+		toStep = position.Step
+	}
+	return NewEventIterator(rule, seed, fromStep, toStep, kinds...)
+}
+
+// ExtendTo moves the end of the range forward to toStep, so a live stream
+// can keep one iterator as time advances. Yielded steps are not repeated.
+func (it *EventIterator) ExtendTo(toStep int64) {
+	if toStep > it.to {
+		it.to = toStep
+	}
+}
+
+// Next returns the next event, or false once the range is exhausted.
+func (it *EventIterator) Next() (Event, bool) {
+	for len(it.pending) == 0 {
+		if it.step > it.to {
+			return Event{}, false
+		}
+
+		// Without ticks, only steps that start a round matter
+		if !it.ticks && it.step > 0 && it.step < it.nextBreak {
+			it.step = it.nextBreak
+			continue
+		}
+
+		it.emit(it.step)
+		it.step++
+	}
+
+	event := it.pending[0]
+	it.pending = it.pending[1:]
+	return event, true
+}
+
+// emit queues the events of a step and advances the round at breaks.
+func (it *EventIterator) emit(step int64) {
+	if step == 0 && it.rounds {
+		it.pending = append(it.pending, Event{Kind: EventRoundStart, Step: 0, Round: 0})
+	}
+
+	if step == it.nextBreak {
+		if it.breaks {
+			it.pending = append(it.pending, Event{Kind: EventBreak, Step: step, Round: it.round})
+		}
+		it.round++
+		it.roundStart = step
+		it.nextBreak = breakStepAfter(it.rule, it.seed, it.round, step)
+		if it.rounds {
+			it.pending = append(it.pending, Event{Kind: EventRoundStart, Step: step, Round: it.round})
+		}
+	}
+
+	if it.ticks {
+		value := step - it.roundStart
+		if it.round == 0 {
+			value = step + 1
+		}
+		it.pending = append(it.pending, Event{
+			Kind:   EventTick,
+			Step:   step,
+			Round:  it.round,
+			Value:  value,
+			Broken: it.round > 0 && step == it.roundStart,
+		})
+	}
+}
+
+// breakStepAfter returns the break step that ends a round, given the step
+// it started at. Same boundaries as RuleRounds.
+func breakStepAfter(rule Rule, seed int64, round int64, start int64) int64 {
+	interval := rule.BreakInterval(seed, round)
+	if round == 0 {
+		if interval < 1 {
+			return 1
+		}
+		return interval
+	}
+	return start + interval + 1
+}
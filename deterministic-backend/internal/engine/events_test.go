@@ -0,0 +1,181 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+// collectEvents drains an iterator.
+func collectEvents(it *EventIterator) []Event {
+	var events []Event
+	for {
+		event, ok := it.Next()
+		if !ok {
+			return events
+		}
+		events = append(events, event)
+	}
+}
+
+func TestEventIterator_TicksMatchStateAtStep(t *testing.T) {
+	rule := CounterRule{}
+	seed := int64(12345)
+
+	// Start mid-round, and exactly on a break step
+	rounds := RuleRounds(rule, seed, 3000, 0, 1000)
+	for _, from := range []int64{0, 1, 150, 299, 1000, rounds[2].BreakStep} {
+		events := collectEvents(NewEventIterator(rule, seed, from, from+2000, EventTick))
+		if len(events) != 2001 {
+			t.Fatalf("from %d: expected 2001 ticks, got %d", from, len(events))
+		}
+		for _, event := range events {
+			state := RuleStateAtStep(rule, seed, event.Step)
+			if event.Round != state.Round || event.Value != state.Value || event.Broken != state.Broken {
+				t.Fatalf("from %d, step %d: expected %+v, got %+v", from, event.Step, state, event)
+			}
+		}
+	}
+}
+
+func TestEventIterator_Order(t *testing.T) {
+	// Round 0: steps 0-2, break at 3, round 1: 3-6, break at 7
+	rule := fixedRule{interval: 3}
+
+	expected := []Event{
+		{Kind: EventRoundStart, Step: 0, Round: 0},
+		{Kind: EventTick, Step: 0, Round: 0, Value: 1},
+		{Kind: EventTick, Step: 1, Round: 0, Value: 2},
+		{Kind: EventTick, Step: 2, Round: 0, Value: 3},
+		{Kind: EventBreak, Step: 3, Round: 0},
+		{Kind: EventRoundStart, Step: 3, Round: 1},
+		{Kind: EventTick, Step: 3, Round: 1, Value: 0, Broken: true},
+		{Kind: EventTick, Step: 4, Round: 1, Value: 1},
+	}
+
+	got := collectEvents(NewEventIterator(rule, 0, 0, 4))
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d events, got %d: %+v", len(expected), len(got), got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Event %d: expected %+v, got %+v", i, expected[i], got[i])
+		}
+	}
+
+	// Starting on a break step still yields the break
+	got = collectEvents(NewEventIterator(rule, 0, 7, 7))
+	if len(got) != 3 || got[0].Kind != EventBreak || got[0].Round != 1 || got[1].Kind != EventRoundStart || got[1].Round != 2 {
+		t.Errorf("Expected break, round_start, tick at step 7, got %+v", got)
+	}
+
+	// Empty range
+	if got := collectEvents(NewEventIterator(rule, 0, 5, 4)); len(got) != 0 {
+		t.Errorf("Expected no events, got %+v", got)
+	}
+}
+
+func TestEventIterator_BreaksMatchRuleRounds(t *testing.T) {
+	rule := CounterRule{Algorithm: algorithmV2{}}
+	seed := int64(987654321)
+
+	rounds := RuleRounds(rule, seed, 100000, 0, 10000)
+	var breaks []Event
+	for _, event := range collectEvents(NewEventIterator(rule, seed, 0, 100000, EventBreak, EventRoundStart)) {
+		if event.Kind == EventTick {
+			t.Fatalf("Unexpected tick %+v", event)
+		}
+		if event.Kind == EventBreak {
+			breaks = append(breaks, event)
+		}
+	}
+
+	for i, event := range breaks {
+		if event.Round != rounds[i].Round || event.Step != rounds[i].BreakStep {
+			t.Fatalf("Break %d: expected round %d at step %d, got %+v", i, rounds[i].Round, rounds[i].BreakStep, event)
+		}
+	}
+	if len(breaks) != len(rounds)-1 {
+		t.Errorf("Expected %d breaks, got %d", len(rounds)-1, len(breaks))
+	}
+}
+
+func TestEventIterator_FilterMatchesFull(t *testing.T) {
+	rule := CounterRule{}
+	seed := int64(42)
+
+	var full []Event
+	for _, event := range collectEvents(NewEventIterator(rule, seed, 500, 5000)) {
+		if event.Kind == EventRoundStart {
+			full = append(full, event)
+		}
+	}
+	filtered := collectEvents(NewEventIterator(rule, seed, 500, 5000, EventRoundStart))
+
+	if len(full) != len(filtered) {
+		t.Fatalf("Expected %d round starts, got %d", len(full), len(filtered))
+	}
+	for i := range full {
+		if full[i] != filtered[i] {
+			t.Errorf("Event %d: expected %+v, got %+v", i, full[i], filtered[i])
+		}
+	}
+}
+
+func TestEventIterator_ExtendTo(t *testing.T) {
+	rule := CounterRule{}
+	seed := int64(42)
+
+	full := collectEvents(NewEventIterator(rule, seed, 0, 3000))
+
+	// Extending in chunks yields the same events
+	var chunked []Event
+	it := NewEventIterator(rule, seed, 0, -1)
+	for to := int64(0); to <= 3000; to += 77 {
+		it.ExtendTo(to)
+		chunked = append(chunked, collectEvents(it)...)
+	}
+	it.ExtendTo(3000)
+	chunked = append(chunked, collectEvents(it)...)
+
+	if len(full) != len(chunked) {
+		t.Fatalf("Expected %d events, got %d", len(full), len(chunked))
+	}
+	for i := range full {
+		if full[i] != chunked[i] {
+			t.Fatalf("Event %d: expected %+v, got %+v", i, full[i], chunked[i])
+		}
+	}
+}
+
+func TestClockEvents_ChainedWindows(t *testing.T) {
+	rule := CounterRule{}
+	seed := int64(12345)
+	startAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	clock := Clock{StartAt: startAt, TickMs: 100}
+
+	// Windows of 250ms from before the start: every step exactly once
+	next := int64(0)
+	for from := startAt.Add(-time.Second); from.Before(startAt.Add(time.Minute)); from = from.Add(250 * time.Millisecond) {
+		for _, event := range collectEvents(ClockEvents(rule, seed, clock, from, from.Add(250*time.Millisecond), EventTick)) {
+			if event.Step != next {
+				t.Fatalf("Expected step %d, got %d", next, event.Step)
+			}
+			next++
+		}
+	}
+	if next != 601 {
+		t.Errorf("Expected 601 steps in the first minute, got %d", next)
+	}
+}
+
+func BenchmarkEventIterator_Ticks(b *testing.B) {
+	rule := CounterRule{}
+	for i := 0; i < b.N; i++ {
+		it := NewEventIterator(rule, 12345, 1000000, 1010000, EventTick)
+		for {
+			if _, ok := it.Next(); !ok {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+)
+
+// Interval distributions.
+const (
+	DistributionUniform   = "uniform"
+	DistributionGeometric = "geometric"
+	DistributionWeighted  = "weighted"
+)
+
+// Interval bounds.
+const (
+	DefaultMinInterval = 100
+	DefaultMaxInterval = 300
+
+	// MaxInterval caps any configured round length.
+	MaxInterval = 1000000
+
+	// MinMeanInterval is the shortest allowed mean round length. Replaying a
+	// session walks it round by round, so shorter rounds make every state
+	// lookup proportionally costlier; this keeps it within 20x the default.
+	MinMeanInterval = 10
+
+	// minGeometricP is the smallest geometric p for which 1 - p < 1.
+	minGeometricP = 0x1p-53
+
+	// maxWeightedEntries caps the size of a weighted table.
+	maxWeightedEntries = 256
+)
+
+// IntervalSpec configures how break intervals are drawn for each round.
+//
+// A spec must be normalized before use. An empty spec normalizes to
+// DefaultIntervalSpec, a uniform distribution over [100, 300], which with
+// Version1 is exactly what computeBreakInterval produces.
+type IntervalSpec struct {
+	Min          int64              `json:"min,omitempty"`          // Minimum interval (uniform, geometric)
+	Max          int64              `json:"max,omitempty"`          // Maximum interval (uniform, geometric)
+	Distribution string             `json:"distribution,omitempty"` // "uniform" (default), "geometric" or "weighted"
+	P            float64            `json:"p,omitempty"`            // Per-step break probability (geometric)
+	Weights      []WeightedInterval `json:"weights,omitempty"`      // Table of intervals (weighted)
+}
+
+// WeightedInterval is one entry of a weighted interval table.
+type WeightedInterval struct {
+	Interval int64 `json:"interval"` // Round length
+	Weight   int64 `json:"weight"`   // Relative weight (> 0)
+}
+
+// DefaultIntervalSpec returns the default uniform [100, 300] distribution.
+func DefaultIntervalSpec() IntervalSpec {
+	return IntervalSpec{
+		Min:          DefaultMinInterval,
+		Max:          DefaultMaxInterval,
+		Distribution: DistributionUniform,
+	}
+}
+
+// Normalize fills in defaults and validates the spec.
+func (s IntervalSpec) Normalize() (IntervalSpec, error) {
+	if s.Distribution == "" {
+		s.Distribution = DistributionUniform
+	}
+
+	switch s.Distribution {
+	case DistributionUniform, DistributionGeometric:
+		if len(s.Weights) > 0 {
+			return s, fmt.Errorf("weights are only used with the %q distribution", DistributionWeighted)
+		}
+		if s.Min == 0 && s.Max == 0 {
+			s.Min, s.Max = DefaultMinInterval, DefaultMaxInterval
+		}
+		if s.Min < 1 {
+			return s, fmt.Errorf("min interval must be at least 1")
+		}
+		if s.Max < s.Min {
+			return s, fmt.Errorf("max interval must not be less than min interval")
+		}
+		if s.Max > MaxInterval {
+			return s, fmt.Errorf("max interval must be at most %d", MaxInterval)
+		}
+
+		if s.Distribution == DistributionGeometric {
+			if math.IsNaN(s.P) || s.P <= 0 || s.P >= 1 {
+				return s, fmt.Errorf("p must be in (0, 1) for the %q distribution", DistributionGeometric)
+			}
+			if s.P < minGeometricP {
+				// Below this 1 - p rounds to 1: the inverse CDF would
+				// divide by log(1) and every round would last min
+				return s, fmt.Errorf("p must be at least %g for the %q distribution", minGeometricP, DistributionGeometric)
+			}
+		} else if s.P != 0 {
+			return s, fmt.Errorf("p is only used with the %q distribution", DistributionGeometric)
+		}
+
+	case DistributionWeighted:
+		if s.Min != 0 || s.Max != 0 || s.P != 0 {
+			return s, fmt.Errorf("min, max and p are not used with the %q distribution", DistributionWeighted)
+		}
+		if len(s.Weights) == 0 || len(s.Weights) > maxWeightedEntries {
+			return s, fmt.Errorf("weights must have between 1 and %d entries", maxWeightedEntries)
+		}
+		for _, w := range s.Weights {
+			if w.Interval < 1 || w.Interval > MaxInterval {
+				return s, fmt.Errorf("weighted interval must be between 1 and %d", MaxInterval)
+			}
+			if w.Weight < 1 || w.Weight > math.MaxUint32 {
+				return s, fmt.Errorf("weight must be between 1 and %d", uint32(math.MaxUint32))
+			}
+		}
+
+	default:
+		return s, fmt.Errorf("unknown distribution %q", s.Distribution)
+	}
+
+	if s.Mean() < MinMeanInterval {
+		return s, fmt.Errorf("mean interval must be at least %d", MinMeanInterval)
+	}
+
+	return s, nil
+}
+
+// Mean returns the expected break interval of a normalized spec.
+func (s IntervalSpec) Mean() float64 {
+	switch s.Distribution {
+	case DistributionGeometric:
+		// Mean of Min + k with P(k) ∝ q^k truncated to k < n, written
+		// with a = -ln q as 1/(e^a - 1) - n/(e^(na) - 1) so that tiny p
+		// does not lose the result to 1 - q^n rounding
+		a := -math.Log1p(-s.P)
+		n := float64(s.Max - s.Min + 1)
+		return float64(s.Min) + 1/math.Expm1(a) - n/math.Expm1(n*a)
+
+	case DistributionWeighted:
+		var sum, total float64
+		for _, w := range s.Weights {
+			sum += float64(w.Interval) * float64(w.Weight)
+			total += float64(w.Weight)
+		}
+		return sum / total
+
+	default:
+		return float64(s.Min+s.Max) / 2
+	}
+}
+
+// Interval returns the break interval for a round, drawing randomness from
+// the given algorithm. The spec must have been normalized.
+func (s IntervalSpec) Interval(algorithm Algorithm, seed int64, round int64) int64 {
+	switch s.Distribution {
+	case DistributionGeometric:
+		// Truncated geometric distribution over [Min, Max] via inverse CDF:
+		// P(Min + k) ∝ (1 - P)^k
+		u := algorithm.RoundUniform(seed, round)
+		q := 1 - s.P
+		n := float64(s.Max - s.Min + 1)
+		truncated := u * (1 - math.Pow(q, n))
+		k := int64(math.Floor(math.Log(1-truncated) / math.Log(q)))
+		if k < 0 {
+			k = 0
+		}
+		if interval := s.Min + k; interval < s.Max {
+			return interval
+		}
+		return s.Max
+
+	case DistributionWeighted:
+		total := uint64(0)
+		for _, w := range s.Weights {
+			total += uint64(w.Weight)
+		}
+		pick := algorithm.RoundRandom(seed, round) % total
+		for _, w := range s.Weights {
+			if pick < uint64(w.Weight) {
+				return w.Interval
+			}
+			pick -= uint64(w.Weight)
+		}
+		return s.Weights[len(s.Weights)-1].Interval
+
+	default:
+		// Uniform over [Min, Max]; with the defaults and Version1
+		// this is computeBreakInterval
+		return s.Min + int64(algorithm.RoundRandom(seed, round)%uint64(s.Max-s.Min+1))
+	}
+}
@@ -0,0 +1,179 @@
+package engine
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIntervalSpec_Normalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    IntervalSpec
+		wantErr bool
+	}{
+		{name: "empty uses defaults", spec: IntervalSpec{}},
+		{name: "uniform range", spec: IntervalSpec{Min: 10, Max: 20}},
+		{name: "fixed length", spec: IntervalSpec{Min: 50, Max: 50}},
+		{name: "geometric", spec: IntervalSpec{Min: 10, Max: 500, Distribution: DistributionGeometric, P: 0.02}},
+		{name: "weighted", spec: IntervalSpec{Distribution: DistributionWeighted, Weights: []WeightedInterval{{Interval: 50, Weight: 3}, {Interval: 200, Weight: 1}}}},
+		{name: "min zero", spec: IntervalSpec{Min: 0, Max: 10}, wantErr: true},
+		{name: "max below min", spec: IntervalSpec{Min: 20, Max: 10}, wantErr: true},
+		{name: "max too large", spec: IntervalSpec{Min: 1, Max: MaxInterval + 1}, wantErr: true},
+		{name: "geometric without p", spec: IntervalSpec{Distribution: DistributionGeometric}, wantErr: true},
+		{name: "uniform with p", spec: IntervalSpec{P: 0.5}, wantErr: true},
+		{name: "weighted without entries", spec: IntervalSpec{Distribution: DistributionWeighted}, wantErr: true},
+		{name: "weighted with zero weight", spec: IntervalSpec{Distribution: DistributionWeighted, Weights: []WeightedInterval{{Interval: 50, Weight: 0}}}, wantErr: true},
+		{name: "weighted with range", spec: IntervalSpec{Min: 1, Max: 2, Distribution: DistributionWeighted, Weights: []WeightedInterval{{Interval: 50, Weight: 1}}}, wantErr: true},
+		{name: "unknown distribution", spec: IntervalSpec{Distribution: "poisson"}, wantErr: true},
+		{name: "mean at bound", spec: IntervalSpec{Min: 1, Max: 2*MinMeanInterval - 1}},
+		{name: "mean too short", spec: IntervalSpec{Min: 1, Max: 10}, wantErr: true},
+		{name: "geometric p too small", spec: IntervalSpec{Min: 1, Max: MaxInterval, Distribution: DistributionGeometric, P: 1e-17}, wantErr: true},
+		{name: "geometric smallest p", spec: IntervalSpec{Min: 1, Max: MaxInterval, Distribution: DistributionGeometric, P: minGeometricP}},
+		{name: "geometric mean too short", spec: IntervalSpec{Min: 1, Max: 1000, Distribution: DistributionGeometric, P: 0.99}, wantErr: true},
+		{name: "weighted mean too short", spec: IntervalSpec{Distribution: DistributionWeighted, Weights: []WeightedInterval{{Interval: 1, Weight: 9}, {Interval: 50, Weight: 1}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.spec.Normalize()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestIntervalSpec_Mean(t *testing.T) {
+	// Geometric mean matches a direct sum over the truncated distribution
+	geometric := IntervalSpec{Min: 10, Max: 60, Distribution: DistributionGeometric, P: 0.05}
+	var sum, total float64
+	for k := int64(0); k <= geometric.Max-geometric.Min; k++ {
+		w := math.Pow(1-geometric.P, float64(k))
+		sum += float64(geometric.Min+k) * w
+		total += w
+	}
+
+	tests := []struct {
+		name     string
+		spec     IntervalSpec
+		expected float64
+	}{
+		{name: "uniform", spec: DefaultIntervalSpec(), expected: 200},
+		{name: "geometric", spec: geometric, expected: sum / total},
+		{name: "geometric tiny p", spec: IntervalSpec{Min: 1, Max: MaxInterval, Distribution: DistributionGeometric, P: minGeometricP}, expected: (1 + MaxInterval) / 2.0},
+		{name: "weighted", spec: IntervalSpec{Distribution: DistributionWeighted, Weights: []WeightedInterval{{Interval: 50, Weight: 3}, {Interval: 200, Weight: 1}}}, expected: 87.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.spec.Mean(); math.Abs(got-tt.expected) > 1e-6*tt.expected {
+				t.Errorf("Expected mean %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestIntervalSpec_DefaultMatchesComputeBreakInterval(t *testing.T) {
+	spec, err := IntervalSpec{}.Normalize()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if def := DefaultIntervalSpec(); spec.Min != def.Min || spec.Max != def.Max || spec.Distribution != def.Distribution {
+		t.Fatalf("Expected default spec, got %+v", spec)
+	}
+
+	for _, seed := range []int64{0, 12345, -987654321, math.MaxInt64} {
+		for round := int64(0); round < 1000; round++ {
+			if got, expected := spec.Interval(algorithmV1{}, seed, round), computeBreakInterval(seed, round); got != expected {
+				t.Fatalf("seed %d round %d: expected %d, got %d", seed, round, expected, got)
+			}
+		}
+	}
+}
+
+func TestIntervalSpec_Distributions(t *testing.T) {
+	rounds := int64(100000)
+	seed := int64(42)
+
+	t.Run("uniform stays in range", func(t *testing.T) {
+		spec, _ := IntervalSpec{Min: 10, Max: 20}.Normalize()
+		for round := int64(0); round < rounds; round++ {
+			if v := spec.Interval(algorithmV1{}, seed, round); v < 10 || v > 20 {
+				t.Fatalf("round %d: interval %d outside [10, 20]", round, v)
+			}
+		}
+	})
+
+	t.Run("geometric mean", func(t *testing.T) {
+		// With a wide range the truncation is negligible:
+		// mean ≈ Min + (1 - p) / p
+		spec, _ := IntervalSpec{Min: 10, Max: 100000, Distribution: DistributionGeometric, P: 0.05}.Normalize()
+		sum := int64(0)
+		for round := int64(0); round < rounds; round++ {
+			v := spec.Interval(algorithmV1{}, seed, round)
+			if v < spec.Min || v > spec.Max {
+				t.Fatalf("round %d: interval %d outside range", round, v)
+			}
+			sum += v
+		}
+
+		mean := float64(sum) / float64(rounds)
+		expected := 10 + (1-0.05)/0.05
+		if math.Abs(mean-expected) > 0.5 {
+			t.Errorf("Expected mean ~%.2f, got %.2f", expected, mean)
+		}
+	})
+
+	t.Run("geometric smallest p matches its mean", func(t *testing.T) {
+		// The accepted mean must be what Interval draws, not all Min
+		spec, err := IntervalSpec{Min: 1, Max: MaxInterval, Distribution: DistributionGeometric, P: minGeometricP}.Normalize()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		sum := int64(0)
+		for round := int64(0); round < rounds; round++ {
+			sum += spec.Interval(algorithmV1{}, seed, round)
+		}
+		if mean := float64(sum) / float64(rounds); math.Abs(mean-spec.Mean()) > 0.01*spec.Mean() {
+			t.Errorf("Expected mean ~%.0f, got %.0f", spec.Mean(), mean)
+		}
+	})
+
+	t.Run("weighted frequencies", func(t *testing.T) {
+		spec, _ := IntervalSpec{
+			Distribution: DistributionWeighted,
+			Weights:      []WeightedInterval{{Interval: 50, Weight: 3}, {Interval: 200, Weight: 1}},
+		}.Normalize()
+
+		counts := map[int64]int64{}
+		for round := int64(0); round < rounds; round++ {
+			counts[spec.Interval(algorithmV1{}, seed, round)]++
+		}
+
+		if len(counts) != 2 {
+			t.Fatalf("Expected only table intervals, got %v", counts)
+		}
+		share := float64(counts[50]) / float64(rounds)
+		if math.Abs(share-0.75) > 0.01 {
+			t.Errorf("Expected interval 50 in ~75%% of rounds, got %.3f", share)
+		}
+	})
+}
+
+func TestCounterRule_CustomInterval(t *testing.T) {
+	rule, err := NewRule(ModeCounter, RuleConfig{Interval: &IntervalSpec{Min: 10, Max: 10}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Round 0 breaks at step 10, then every 11 steps
+	for _, step := range []int64{10, 21, 32} {
+		if state := RuleStateAtStep(rule, 12345, step); !state.Broken {
+			t.Errorf("Expected break at step %d, got %+v", step, state)
+		}
+	}
+
+	if _, err := NewRule(ModeCrash, RuleConfig{Interval: &IntervalSpec{Min: 5, Max: 5}}); err == nil {
+		t.Error("Expected crash mode to reject interval settings")
+	}
+}
@@ -0,0 +1,89 @@
+package engine
+
+import "fmt"
+
+// MaxPhaseTicks is the maximum length of a lobby or intermission.
+const MaxPhaseTicks = 1000000
+
+// GamePhase identifies what a session's timeline is doing at a tick.
+type GamePhase string
+
+// Game phases
+const (
+	GamePhaseLobby        GamePhase = "lobby"        // Countdown before round 0
+	GamePhaseRunning      GamePhase = "running"      // A round is in progress (including its break step)
+	GamePhaseIntermission GamePhase = "intermission" // Cooldown after a break, before the round runs
+)
+
+// Phases inserts ticks outside running rounds into the timeline.
+//
+// The lobby is LobbyTicks ticks before step 0. After every break step the
+// timeline holds the break state for IntermissionTicks more ticks before
+// the round runs, so clients get a window between rounds (e.g. to bet).
+// Steps and the values they carry do not change; phases only delay them.
+// Zero phases leave the timeline one tick per step.
+type Phases struct {
+	LobbyTicks        int64
+	IntermissionTicks int64
+}
+
+// Validate checks the phase lengths.
+func (p Phases) Validate() error {
+	if p.LobbyTicks < 0 || p.LobbyTicks > MaxPhaseTicks {
+		return fmt.Errorf("lobby_ticks must be between 0 and %d", MaxPhaseTicks)
+	}
+	if p.IntermissionTicks < 0 || p.IntermissionTicks > MaxPhaseTicks {
+		return fmt.Errorf("intermission_ticks must be between 0 and %d", MaxPhaseTicks)
+	}
+	return nil
+}
+
+// Position is a point on the timeline.
+type Position struct {
+	Step      int64     // Game step (0 in the lobby, the break step in an intermission)
+	Phase     GamePhase // Phase at the tick
+	TicksLeft int64     // Ticks until the lobby or intermission ends, counting the current one (0 while running)
+}
+
+// PositionAt maps the number of ticks elapsed since start to a position.
+//
+// Without an intermission this is O(1); otherwise it walks the rounds like
+// StateAtStep, so the cost is O(rounds).
+func (p Phases) PositionAt(rule Rule, seed int64, tick int64) Position {
+	if tick < p.LobbyTicks {
+		return Position{Phase: GamePhaseLobby, TicksLeft: p.LobbyTicks - tick}
+	}
+	tick -= p.LobbyTicks
+	if tick < 0 {
+		tick = 0
+	}
+	if p.IntermissionTicks <= 0 {
+		return Position{Step: tick, Phase: GamePhaseRunning}
+	}
+
+	// Round 0 has no leading break, so it runs one tick per step
+	start := breakStepAfter(rule, seed, 0, 0)
+	if tick < start {
+		return Position{Step: tick, Phase: GamePhaseRunning}
+	}
+
+	// Round r takes its break tick, the intermission, then its increments;
+	// startTick is the tick of the round's break step
+	startTick := start
+	for round := int64(1); ; round++ {
+		offset := tick - startTick
+		if offset == 0 {
+			return Position{Step: start, Phase: GamePhaseRunning}
+		}
+		if offset <= p.IntermissionTicks {
+			return Position{Step: start, Phase: GamePhaseIntermission, TicksLeft: p.IntermissionTicks - offset + 1}
+		}
+
+		next := breakStepAfter(rule, seed, round, start)
+		if offset < next-start+p.IntermissionTicks {
+			return Position{Step: start + offset - p.IntermissionTicks, Phase: GamePhaseRunning}
+		}
+		startTick += next - start + p.IntermissionTicks
+		start = next
+	}
+}
@@ -0,0 +1,170 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPhases_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		phases  Phases
+		wantErr bool
+	}{
+		{name: "none", phases: Phases{}},
+		{name: "lobby and intermission", phases: Phases{LobbyTicks: 50, IntermissionTicks: 30}},
+		{name: "negative lobby", phases: Phases{LobbyTicks: -1}, wantErr: true},
+		{name: "intermission too long", phases: Phases{IntermissionTicks: MaxPhaseTicks + 1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.phases.Validate()
+			if tt.wantErr && err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestPhases_PositionAt(t *testing.T) {
+	// Round 0 is steps 0-2, round 1 breaks at 3 and runs 4-6, round 2 breaks at 7
+	rule := fixedRule{interval: 3}
+	phases := Phases{LobbyTicks: 2, IntermissionTicks: 2}
+
+	tests := []struct {
+		tick     int64
+		expected Position
+	}{
+		{tick: 0, expected: Position{Phase: GamePhaseLobby, TicksLeft: 2}},
+		{tick: 1, expected: Position{Phase: GamePhaseLobby, TicksLeft: 1}},
+		{tick: 2, expected: Position{Step: 0, Phase: GamePhaseRunning}},
+		{tick: 4, expected: Position{Step: 2, Phase: GamePhaseRunning}},
+		{tick: 5, expected: Position{Step: 3, Phase: GamePhaseRunning}}, // Break step
+		{tick: 6, expected: Position{Step: 3, Phase: GamePhaseIntermission, TicksLeft: 2}},
+		{tick: 7, expected: Position{Step: 3, Phase: GamePhaseIntermission, TicksLeft: 1}},
+		{tick: 8, expected: Position{Step: 4, Phase: GamePhaseRunning}},
+		{tick: 10, expected: Position{Step: 6, Phase: GamePhaseRunning}},
+		{tick: 11, expected: Position{Step: 7, Phase: GamePhaseRunning}},
+		{tick: 12, expected: Position{Step: 7, Phase: GamePhaseIntermission, TicksLeft: 2}},
+		{tick: 14, expected: Position{Step: 8, Phase: GamePhaseRunning}},
+	}
+
+	for _, tt := range tests {
+		if got := phases.PositionAt(rule, 0, tt.tick); got != tt.expected {
+			t.Errorf("Tick %d: expected %+v, got %+v", tt.tick, tt.expected, got)
+		}
+	}
+
+	// Without phases ticks are steps
+	if got := (Phases{}).PositionAt(rule, 0, 5); got != (Position{Step: 5, Phase: GamePhaseRunning}) {
+		t.Errorf("Expected step 5 running, got %+v", got)
+	}
+}
+
+func TestPhases_EveryStepRunsOnce(t *testing.T) {
+	rule := CounterRule{}
+	seed := int64(12345)
+	phases := Phases{LobbyTicks: 10, IntermissionTicks: 7}
+
+	// Walking the ticks visits every step once while running, and every
+	// break is followed by exactly IntermissionTicks intermission ticks
+	next := int64(0)
+	intermission := int64(0)
+	afterBreak := false
+	for tick := phases.LobbyTicks; next < 5000; tick++ {
+		position := phases.PositionAt(rule, seed, tick)
+		if position.Phase == GamePhaseIntermission {
+			intermission++
+			continue
+		}
+		expected := int64(0)
+		if afterBreak {
+			expected = phases.IntermissionTicks
+		}
+		if intermission != expected {
+			t.Fatalf("Step %d: expected %d intermission ticks, got %d", next, expected, intermission)
+		}
+		if position.Step != next {
+			t.Fatalf("Tick %d: expected step %d, got %+v", tick, next, position)
+		}
+		intermission = 0
+		afterBreak = RuleStateAtStep(rule, seed, next).Broken
+		next++
+	}
+}
+
+func TestClockStateAt_Phases(t *testing.T) {
+	rule := fixedRule{interval: 3}
+	startAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	clock := Clock{StartAt: startAt, TickMs: 100, Phases: Phases{LobbyTicks: 5, IntermissionTicks: 10}}
+	at := func(ms int64) time.Time { return startAt.Add(time.Duration(ms) * time.Millisecond) }
+
+	if state := ClockStateAt(rule, 0, clock, at(-100)); state != (State{}) {
+		t.Errorf("Expected zero state before start, got %+v", state)
+	}
+	if state := ClockStateAt(rule, 0, clock, at(150)); state != (State{Phase: GamePhaseLobby, TicksLeft: 4}) {
+		t.Errorf("Expected lobby with 4 ticks left, got %+v", state)
+	}
+	if state := ClockStateAt(rule, 0, clock, at(500)); state != (State{Step: 0, Value: 1, Phase: GamePhaseRunning}) {
+		t.Errorf("Expected step 0 running, got %+v", state)
+	}
+
+	// Break at tick 8, intermission at ticks 9-18, step 4 at tick 19
+	expected := State{Step: 3, Value: 0, Round: 1, Broken: true, Phase: GamePhaseIntermission, TicksLeft: 10}
+	if state := ClockStateAt(rule, 0, clock, at(900)); state != expected {
+		t.Errorf("Expected %+v, got %+v", expected, state)
+	}
+	if state := ClockStateAt(rule, 0, clock, at(1900)); state != (State{Step: 4, Value: 1, Round: 1, Phase: GamePhaseRunning}) {
+		t.Errorf("Expected step 4 running, got %+v", state)
+	}
+}
+
+func TestTickSchedule_RoundTicksWithPhases(t *testing.T) {
+	// Lobby at round 0's tick, intermission at the tick of the round it precedes
+	rule := fixedRule{interval: 3}
+	schedule := TickSchedule{RoundTicks: []int64{100, 50}}
+	phases := Phases{LobbyTicks: 2, IntermissionTicks: 2}
+
+	tests := []struct {
+		elapsedMs int64
+		tick      int64
+	}{
+		{elapsedMs: 199, tick: 1},
+		{elapsedMs: 200, tick: 2},
+		{elapsedMs: 499, tick: 4},
+		{elapsedMs: 500, tick: 5}, // Break of round 1, 50ms from here
+		{elapsedMs: 550, tick: 6},
+		{elapsedMs: 799, tick: 10},
+		{elapsedMs: 800, tick: 11},
+	}
+
+	for _, tt := range tests {
+		if got := schedule.TickAt(rule, 0, phases, tt.elapsedMs); got != tt.tick {
+			t.Errorf("elapsed %dms: expected tick %d, got %d", tt.elapsedMs, tt.tick, got)
+		}
+	}
+}
+
+func TestClockEvents_ChainedWindowsWithPhases(t *testing.T) {
+	rule := CounterRule{}
+	seed := int64(12345)
+	startAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	clock := Clock{StartAt: startAt, TickMs: 100, Phases: Phases{LobbyTicks: 30, IntermissionTicks: 20}}
+
+	next := int64(0)
+	for from := startAt.Add(-time.Second); from.Before(startAt.Add(time.Minute)); from = from.Add(250 * time.Millisecond) {
+		for _, event := range collectEvents(ClockEvents(rule, seed, clock, from, from.Add(250*time.Millisecond), EventTick)) {
+			if event.Step != next {
+				t.Fatalf("Expected step %d, got %d", next, event.Step)
+			}
+			next++
+		}
+	}
+	if expected := clock.StepAt(rule, seed, startAt.Add(time.Minute)) + 1; next != expected {
+		t.Errorf("Expected %d steps in the first minute, got %d", expected, next)
+	}
+}
@@ -0,0 +1,226 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ModeCounter is the mode of the default counter rule.
+const ModeCounter = "counter"
+
+// DefaultMode is used for sessions that do not specify a mode.
+const DefaultMode = ModeCounter
+
+// Rule defines a deterministic game mechanic on top of the engine's
+// round/break timeline.
+//
+// The engine owns the timeline (steps, rounds, breaks); a rule decides how
+// long each round lasts and what outcome a state has. Rules must be pure:
+// same (seed, round) or (seed, state) → same result.
+type Rule interface {
+	// Mode returns the identifier stored on sessions (e.g. "counter").
+	Mode() string
+
+	// Params returns the parameters the rule was created with.
+	Params() map[string]float64
+
+	// Version returns the engine algorithm version the rule draws
+	// randomness from.
+	Version() int
+
+	// BreakInterval returns how many increments the given round lasts
+	// before it breaks.
+	BreakInterval(seed int64, round int64) int64
+
+	// Outcome returns rule-specific state for a point on the timeline.
+	// It is encoded as JSON in API responses; nil means the rule has
+	// nothing to add beyond State.
+	Outcome(seed int64, state State) interface{}
+}
+
+// Payer is implemented by rules whose cash-outs pay out a stake. Rules
+// without it (counter) have no payout.
+type Payer interface {
+	// Payout returns what a stake cashed out at the given running state
+	// pays, in the stake's unit.
+	Payout(stake int64, state State) int64
+}
+
+// CashOuter is implemented by rules under which some running states cannot
+// be cashed out. Rules without it (counter) accept a cash-out at every
+// running state.
+type CashOuter interface {
+	// CanCashOut reports whether a cash-out at the given running state
+	// wins; seed is the track's.
+	CanCashOut(seed int64, state State) bool
+}
+
+// RuleConfig holds the per-session settings a rule is created from.
+type RuleConfig struct {
+	Version  int                // Engine algorithm version (0 = Version1)
+	Params   map[string]float64 // Rule parameters
+	Interval *IntervalSpec      // Break interval distribution (nil = default)
+}
+
+// RuleFactory creates a rule from its configuration.
+type RuleFactory func(config RuleConfig) (Rule, error)
+
+// rules is the registry of known game modes.
+var rules = map[string]RuleFactory{
+	ModeCounter: newCounterRule,
+	ModeCrash:   newCrashRule,
+}
+
+// NewRule creates the rule registered for mode.
+// An empty mode selects DefaultMode.
+func NewRule(mode string, config RuleConfig) (Rule, error) {
+	if mode == "" {
+		mode = DefaultMode
+	}
+
+	factory, ok := rules[mode]
+	if !ok {
+		return nil, fmt.Errorf("unknown mode %q", mode)
+	}
+
+	return factory(config)
+}
+
+// Modes returns the registered game modes in sorted order.
+func Modes() []string {
+	modes := make([]string, 0, len(rules))
+	for mode := range rules {
+		modes = append(modes, mode)
+	}
+	sort.Strings(modes)
+	return modes
+}
+
+// RuleStateAt computes the deterministic state at a given time for a rule.
+// It behaves like StateAt, with round lengths taken from the rule.
+func RuleStateAt(rule Rule, seed int64, startAt time.Time, tickMs int64, now time.Time) State {
+	if now.Before(startAt) {
+		return State{}
+	}
+
+	return RuleStateAtStep(rule, seed, StepAt(startAt, tickMs, now))
+}
+
+// RuleStateAtStep computes the deterministic state at a given step for a rule.
+// It behaves like StateAtStep, with round lengths taken from the rule.
+func RuleStateAtStep(rule Rule, seed int64, step int64) State {
+	return stateAtStep(step, func(round int64) int64 {
+		return rule.BreakInterval(seed, round)
+	})
+}
+
+// RoundInfo describes one round on the timeline.
+type RoundInfo struct {
+	Round     int64 // Round number
+	StartStep int64 // First step of the round (the break step of the previous round, 0 for round 0)
+	BreakStep int64 // Step at which the round breaks (first step of the next round)
+	Interval  int64 // Increments before the break (Rule.BreakInterval)
+}
+
+// RuleRounds returns the rounds that start at or before untilStep,
+// beginning with round from and returning at most limit rounds.
+//
+// Like RuleStateAtStep it walks from round 0, so the cost is O(from + limit).
+func RuleRounds(rule Rule, seed int64, untilStep int64, from int64, limit int) []RoundInfo {
+	var rounds []RoundInfo
+	if untilStep < 0 || limit <= 0 {
+		return rounds
+	}
+
+	// Round 0 starts at step 0 and never breaks at step 0
+	start := int64(0)
+	for round := int64(0); start <= untilStep && len(rounds) < limit; round++ {
+		interval := rule.BreakInterval(seed, round)
+		breakAt := start + interval + 1
+		if round == 0 {
+			breakAt = interval
+			if breakAt < 1 {
+				breakAt = 1
+			}
+		}
+
+		if round >= from {
+			rounds = append(rounds, RoundInfo{
+				Round:     round,
+				StartStep: start,
+				BreakStep: breakAt,
+				Interval:  interval,
+			})
+		}
+		start = breakAt
+	}
+
+	return rounds
+}
+
+// CounterRule is the default mechanic: a counter that increments every tick
+// and resets after a number of steps drawn from Interval.
+// The zero value resets after 100–300 steps using Version1.
+type CounterRule struct {
+	Interval  *IntervalSpec // Normalized interval distribution (nil = default)
+	Algorithm Algorithm     // Source of randomness (nil = Version1)
+}
+
+func newCounterRule(config RuleConfig) (Rule, error) {
+	if len(config.Params) > 0 {
+		return nil, fmt.Errorf("mode %q takes no parameters", ModeCounter)
+	}
+
+	algorithm, err := AlgorithmFor(config.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := CounterRule{Algorithm: algorithm}
+	if config.Interval != nil {
+		spec, err := config.Interval.Normalize()
+		if err != nil {
+			return nil, err
+		}
+		rule.Interval = &spec
+	}
+
+	return rule, nil
+}
+
+// Mode implements Rule.
+func (CounterRule) Mode() string {
+	return ModeCounter
+}
+
+// Params implements Rule.
+func (CounterRule) Params() map[string]float64 {
+	return nil
+}
+
+// Version implements Rule.
+func (r CounterRule) Version() int {
+	return r.algorithm().Version()
+}
+
+// BreakInterval implements Rule.
+func (r CounterRule) BreakInterval(seed int64, round int64) int64 {
+	if r.Interval == nil {
+		return DefaultIntervalSpec().Interval(r.algorithm(), seed, round)
+	}
+	return r.Interval.Interval(r.algorithm(), seed, round)
+}
+
+// algorithm returns the rule's algorithm, defaulting to Version1.
+func (r CounterRule) algorithm() Algorithm {
+	if r.Algorithm == nil {
+		return algorithmV1{}
+	}
+	return r.Algorithm
+}
+
+// Outcome implements Rule. The counter value is already part of State.
+func (CounterRule) Outcome(seed int64, state State) interface{} {
+	return nil
+}
@@ -0,0 +1,127 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		params   map[string]float64
+		wantMode string
+		wantErr  bool
+	}{
+		{name: "empty mode uses default", mode: "", wantMode: DefaultMode},
+		{name: "counter", mode: ModeCounter, wantMode: ModeCounter},
+		{name: "unknown mode", mode: "roulette", wantErr: true},
+		{name: "counter with params", mode: ModeCounter, params: map[string]float64{"x": 1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := NewRule(tt.mode, RuleConfig{Params: tt.params})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected error, got rule %q", rule.Mode())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if rule.Mode() != tt.wantMode {
+				t.Errorf("Expected mode %q, got %q", tt.wantMode, rule.Mode())
+			}
+		})
+	}
+}
+
+func TestRuleStateAt_CounterMatchesStateAt(t *testing.T) {
+	seed := int64(12345)
+	startAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	tickMs := int64(100)
+
+	rule, err := NewRule(ModeCounter, RuleConfig{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for step := int64(-5); step < 2000; step++ {
+		now := startAt.Add(time.Duration(step) * time.Duration(tickMs) * time.Millisecond)
+		if got, expected := RuleStateAt(rule, seed, startAt, tickMs, now), StateAt(seed, startAt, tickMs, now); got != expected {
+			t.Fatalf("step %d: RuleStateAt %+v, StateAt %+v", step, got, expected)
+		}
+	}
+}
+
+// fixedRule breaks every round after a constant number of increments.
+type fixedRule struct {
+	interval int64
+}
+
+func (r fixedRule) Mode() string                                { return "fixed" }
+func (r fixedRule) Params() map[string]float64                  { return nil }
+func (r fixedRule) Version() int                                { return Version1 }
+func (r fixedRule) BreakInterval(seed int64, round int64) int64 { return r.interval }
+func (r fixedRule) Outcome(seed int64, state State) interface{} { return nil }
+
+func TestRuleStateAtStep_CustomRule(t *testing.T) {
+	rule := fixedRule{interval: 3}
+
+	// Round 0: steps 0-2 (values 1-3), break at 3, round 1: 4-6 (values 1-3), break at 7
+	expected := []State{
+		{Step: 0, Value: 1, Round: 0},
+		{Step: 1, Value: 2, Round: 0},
+		{Step: 2, Value: 3, Round: 0},
+		{Step: 3, Value: 0, Round: 1, Broken: true},
+		{Step: 4, Value: 1, Round: 1},
+		{Step: 5, Value: 2, Round: 1},
+		{Step: 6, Value: 3, Round: 1},
+		{Step: 7, Value: 0, Round: 2, Broken: true},
+	}
+
+	for _, want := range expected {
+		if got := RuleStateAtStep(rule, 0, want.Step); got != want {
+			t.Errorf("step %d: expected %+v, got %+v", want.Step, want, got)
+		}
+	}
+}
+
+func TestRuleRounds_MatchesStateAtStep(t *testing.T) {
+	rule := CounterRule{}
+	seed := int64(12345)
+
+	rounds := RuleRounds(rule, seed, 5000, 0, 1000)
+	if len(rounds) < 2 {
+		t.Fatalf("Expected several rounds, got %d", len(rounds))
+	}
+
+	for i, info := range rounds {
+		if info.Round != int64(i) {
+			t.Fatalf("Expected round %d, got %d", i, info.Round)
+		}
+		if info.StartStep > 5000 {
+			t.Fatalf("Round %d starts after untilStep: %d", info.Round, info.StartStep)
+		}
+
+		// The break step begins the next round
+		state := RuleStateAtStep(rule, seed, info.BreakStep)
+		if !state.Broken || state.Round != info.Round+1 {
+			t.Errorf("Round %d: expected break at step %d, got %+v", info.Round, info.BreakStep, state)
+		}
+
+		// The step before the break is the last increment of the round
+		last := RuleStateAtStep(rule, seed, info.BreakStep-1)
+		if last.Round != info.Round || last.Value != info.Interval {
+			t.Errorf("Round %d: expected value %d before break, got %+v", info.Round, info.Interval, last)
+		}
+	}
+
+	// Paging returns the same rounds
+	page := RuleRounds(rule, seed, 5000, 3, 2)
+	if len(page) != 2 || page[0] != rounds[3] || page[1] != rounds[4] {
+		t.Errorf("Expected rounds 3-4, got %+v", page)
+	}
+}
@@ -0,0 +1,162 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Tick schedule limits
+const (
+	MaxScheduleEntries = 256         // Maximum segments or round ticks
+	MaxTickMs          = 3600 * 1000 // Maximum tick interval (1 hour)
+)
+
+// TickSegment runs the clock at a fixed tick rate for a span of active time.
+type TickSegment struct {
+	DurationMs int64 // Active time covered (0 on the last segment = until the end)
+	TickMs     int64 // Tick interval in milliseconds
+}
+
+// TickSchedule varies the tick rate within a session.
+// Exactly one of Segments or RoundTicks is set.
+//
+// Segments switch the tick rate after fixed spans of active time
+// ("100ms for the first 5 minutes, then 50ms"). Every segment but the last
+// must last a whole number of ticks, so no tick straddles two segments.
+//
+// RoundTicks sets the tick rate by round: every step of round r lasts
+// RoundTicks[min(r, len-1)] ms, so games can speed up as rounds progress.
+// A round's steps are [StartStep, BreakStep) as reported by RuleRounds.
+type TickSchedule struct {
+	Segments   []TickSegment
+	RoundTicks []int64
+}
+
+// Validate checks the schedule.
+func (s TickSchedule) Validate() error {
+	switch {
+	case len(s.Segments) > 0 && len(s.RoundTicks) > 0:
+		return errors.New("tick schedule takes either segments or round ticks, not both")
+	case len(s.Segments) > MaxScheduleEntries || len(s.RoundTicks) > MaxScheduleEntries:
+		return fmt.Errorf("tick schedule takes at most %d entries", MaxScheduleEntries)
+	}
+
+	if len(s.RoundTicks) > 0 {
+		for _, tickMs := range s.RoundTicks {
+			if tickMs < 1 || tickMs > MaxTickMs {
+				return fmt.Errorf("round tick %d must be between 1 and %d ms", tickMs, MaxTickMs)
+			}
+		}
+		return nil
+	}
+
+	if len(s.Segments) == 0 {
+		return errors.New("tick schedule needs segments or round ticks")
+	}
+	for i, segment := range s.Segments {
+		if segment.TickMs < 1 || segment.TickMs > MaxTickMs {
+			return fmt.Errorf("segment %d: tick_ms must be between 1 and %d", i, MaxTickMs)
+		}
+		if i == len(s.Segments)-1 {
+			if segment.DurationMs != 0 {
+				return fmt.Errorf("segment %d: the last segment runs until the end and takes no duration", i)
+			}
+			continue
+		}
+		if segment.DurationMs <= 0 || segment.DurationMs%segment.TickMs != 0 {
+			return fmt.Errorf("segment %d: duration_ms must be a positive multiple of tick_ms", i)
+		}
+	}
+	return nil
+}
+
+// FirstTickMs returns the tick interval the schedule starts with.
+func (s TickSchedule) FirstTickMs() int64 {
+	if len(s.RoundTicks) > 0 {
+		return s.RoundTicks[0]
+	}
+	if len(s.Segments) > 0 {
+		return s.Segments[0].TickMs
+	}
+	return 0
+}
+
+// StepAt returns the step reached after elapsedMs of active time.
+//
+// Segments are walked in order; round ticks walk the rule's rounds, so the
+// cost is O(segments) or O(rounds). The rule and seed are only used for
+// round ticks.
+func (s TickSchedule) StepAt(rule Rule, seed int64, elapsedMs int64) int64 {
+	return s.TickAt(rule, seed, Phases{}, elapsedMs)
+}
+
+// TickAt returns the number of ticks elapsed after elapsedMs of active
+// time on a timeline with the given phases. Without phases ticks and steps
+// are the same.
+//
+// Segments do not depend on the phases. With round ticks the lobby runs at
+// the first round's tick and an intermission at the tick of the round its
+// break starts.
+func (s TickSchedule) TickAt(rule Rule, seed int64, phases Phases, elapsedMs int64) int64 {
+	if elapsedMs < 0 {
+		return 0
+	}
+
+	if len(s.RoundTicks) > 0 {
+		return s.roundTickAt(rule, seed, phases, elapsedMs)
+	}
+
+	var tick int64
+	for i, segment := range s.Segments {
+		if segment.TickMs <= 0 {
+			return tick
+		}
+		if i == len(s.Segments)-1 || elapsedMs < segment.DurationMs {
+			return tick + elapsedMs/segment.TickMs
+		}
+		tick += segment.DurationMs / segment.TickMs
+		elapsedMs -= segment.DurationMs
+	}
+	return tick
+}
+
+// roundTickAt maps active time to ticks with a tick rate per round.
+func (s TickSchedule) roundTickAt(rule Rule, seed int64, phases Phases, elapsedMs int64) int64 {
+	var tick int64
+	if phases.LobbyTicks > 0 {
+		tickMs := s.RoundTicks[0]
+		if tickMs <= 0 {
+			return 0
+		}
+		duration := phases.LobbyTicks * tickMs
+		if elapsedMs < duration {
+			return elapsedMs / tickMs
+		}
+		elapsedMs -= duration
+		tick = phases.LobbyTicks
+	}
+
+	start := int64(0)
+	for round := int64(0); ; round++ {
+		tickMs := s.RoundTicks[len(s.RoundTicks)-1]
+		if round < int64(len(s.RoundTicks)) {
+			tickMs = s.RoundTicks[round]
+		}
+		if tickMs <= 0 {
+			return tick
+		}
+
+		breakAt := breakStepAfter(rule, seed, round, start)
+		ticks := breakAt - start
+		if round > 0 {
+			ticks += phases.IntermissionTicks
+		}
+		duration := ticks * tickMs
+		if elapsedMs < duration {
+			return tick + elapsedMs/tickMs
+		}
+		elapsedMs -= duration
+		tick += ticks
+		start = breakAt
+	}
+}
@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTickSchedule_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule TickSchedule
+		wantErr  bool
+	}{
+		{name: "segments", schedule: TickSchedule{Segments: []TickSegment{{DurationMs: 300000, TickMs: 100}, {TickMs: 50}}}},
+		{name: "single open segment", schedule: TickSchedule{Segments: []TickSegment{{TickMs: 100}}}},
+		{name: "round ticks", schedule: TickSchedule{RoundTicks: []int64{100, 90, 80}}},
+		{name: "empty", schedule: TickSchedule{}, wantErr: true},
+		{name: "both", schedule: TickSchedule{Segments: []TickSegment{{TickMs: 100}}, RoundTicks: []int64{100}}, wantErr: true},
+		{name: "zero tick", schedule: TickSchedule{Segments: []TickSegment{{TickMs: 0}}}, wantErr: true},
+		{name: "tick too long", schedule: TickSchedule{RoundTicks: []int64{MaxTickMs + 1}}, wantErr: true},
+		{name: "zero round tick", schedule: TickSchedule{RoundTicks: []int64{100, 0}}, wantErr: true},
+		{name: "duration not a multiple of tick", schedule: TickSchedule{Segments: []TickSegment{{DurationMs: 250, TickMs: 100}, {TickMs: 50}}}, wantErr: true},
+		{name: "missing duration", schedule: TickSchedule{Segments: []TickSegment{{TickMs: 100}, {TickMs: 50}}}, wantErr: true},
+		{name: "last segment with duration", schedule: TickSchedule{Segments: []TickSegment{{DurationMs: 1000, TickMs: 100}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.schedule.Validate()
+			if tt.wantErr && err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestTickSchedule_Segments(t *testing.T) {
+	// 100ms for the first 5 minutes (3000 steps), then 50ms
+	schedule := TickSchedule{Segments: []TickSegment{{DurationMs: 300000, TickMs: 100}, {TickMs: 50}}}
+
+	tests := []struct {
+		elapsedMs int64
+		step      int64
+	}{
+		{elapsedMs: -1, step: 0},
+		{elapsedMs: 0, step: 0},
+		{elapsedMs: 99, step: 0},
+		{elapsedMs: 100, step: 1},
+		{elapsedMs: 299999, step: 2999},
+		{elapsedMs: 300000, step: 3000},
+		{elapsedMs: 300049, step: 3000},
+		{elapsedMs: 300050, step: 3001},
+		{elapsedMs: 3600000, step: 3000 + 3300000/50},
+	}
+
+	for _, tt := range tests {
+		if got := schedule.StepAt(nil, 0, tt.elapsedMs); got != tt.step {
+			t.Errorf("elapsed %dms: expected step %d, got %d", tt.elapsedMs, tt.step, got)
+		}
+	}
+}
+
+func TestTickSchedule_RoundTicks(t *testing.T) {
+	// Round 0 is steps 0-2, round 1 steps 3-6, round 2 steps 7-10, ...
+	rule := fixedRule{interval: 3}
+	schedule := TickSchedule{RoundTicks: []int64{100, 50}}
+
+	tests := []struct {
+		elapsedMs int64
+		step      int64
+	}{
+		{elapsedMs: 0, step: 0},
+		{elapsedMs: 299, step: 2},
+		{elapsedMs: 300, step: 3}, // round 1 ticks every 50ms
+		{elapsedMs: 349, step: 3},
+		{elapsedMs: 350, step: 4},
+		{elapsedMs: 499, step: 6},
+		{elapsedMs: 500, step: 7}, // round 2 keeps the last tick
+		{elapsedMs: 700, step: 11},
+	}
+
+	for _, tt := range tests {
+		if got := schedule.StepAt(rule, 0, tt.elapsedMs); got != tt.step {
+			t.Errorf("elapsed %dms: expected step %d, got %d", tt.elapsedMs, tt.step, got)
+		}
+	}
+}
+
+func TestTickSchedule_RoundTicksFollowRounds(t *testing.T) {
+	rule := CounterRule{}
+	seed := int64(12345)
+	schedule := TickSchedule{RoundTicks: []int64{100, 80, 60, 40}}
+
+	// Every step lasts the tick of the round it belongs to
+	elapsedMs := int64(0)
+	for _, info := range RuleRounds(rule, seed, 3000, 0, 1000) {
+		tickMs := schedule.RoundTicks[len(schedule.RoundTicks)-1]
+		if info.Round < int64(len(schedule.RoundTicks)) {
+			tickMs = schedule.RoundTicks[info.Round]
+		}
+		for step := info.StartStep; step < info.BreakStep; step++ {
+			if got := schedule.StepAt(rule, seed, elapsedMs); got != step {
+				t.Fatalf("Round %d: expected step %d at %dms, got %d", info.Round, step, elapsedMs, got)
+			}
+			if got := schedule.StepAt(rule, seed, elapsedMs+tickMs-1); got != step {
+				t.Fatalf("Round %d: expected step %d at %dms, got %d", info.Round, step, elapsedMs+tickMs-1, got)
+			}
+			elapsedMs += tickMs
+		}
+	}
+}
+
+func TestClock_Schedule(t *testing.T) {
+	startAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	clock := Clock{
+		StartAt:  startAt,
+		TickMs:   100,
+		Schedule: &TickSchedule{Segments: []TickSegment{{DurationMs: 1000, TickMs: 100}, {TickMs: 10}}},
+		// Paused for one second after 500ms
+		Pauses: []Pause{{From: startAt.Add(500 * time.Millisecond), Until: startAt.Add(1500 * time.Millisecond)}},
+	}
+
+	// 2s wall time = 1s active: the whole first segment, then nothing
+	if got := clock.StepAt(nil, 0, startAt.Add(2*time.Second)); got != 10 {
+		t.Errorf("Expected step 10, got %d", got)
+	}
+	// 2.5s wall time = 1.5s active: 10 steps + 500ms at 10ms
+	if got := clock.StepAt(nil, 0, startAt.Add(2500*time.Millisecond)); got != 60 {
+		t.Errorf("Expected step 60, got %d", got)
+	}
+}
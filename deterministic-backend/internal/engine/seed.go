@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidSeed is returned for seed strings that match no supported format.
+var ErrInvalidSeed = errors.New("invalid seed")
+
+// digestHexLength is the length of a hex-encoded SHA-256 digest.
+const digestHexLength = 64
+
+// ParseSeed converts a session seed string to the engine's int64 seed.
+//
+// The derivation is part of the engine version, so sessions stay
+// reproducible:
+//
+// Version 1 (frozen, sessions created before versions existed):
+//   - Decimal int64 strings are used as is
+//   - Anything else is hashed with hash = hash*31 + byte, then negated if
+//     negative. This collides easily and is kept only for old sessions.
+//
+// Version 2 and later accept exactly three formats and reject anything else:
+//   - Decimal integer in [-2^63, 2^64-1] (explicit QA seeds):
+//     used as is; values above 2^63-1 are reinterpreted as int64
+//   - UUID (any case): SHA-256 of the canonical lowercase form, then the
+//     first 8 bytes of the digest read as a big-endian uint64
+//   - 64-character hex SHA-256/HMAC digest (e.g. a seed mixed with a
+//     client seed): the first 8 bytes read as a big-endian uint64
+//
+// The uint64 is reinterpreted as int64 (two's complement), so client SDKs
+// can port the derivation with plain 64-bit integer arithmetic.
+func ParseSeed(version int, seed string) (int64, error) {
+	if version == 0 || version == Version1 {
+		return parseSeedV1(seed), nil
+	}
+	if _, ok := algorithms[version]; !ok {
+		return 0, fmt.Errorf("unknown engine version %d", version)
+	}
+
+	if value, err := ParseNumericSeed(seed); err == nil {
+		return value, nil
+	}
+
+	if id, err := uuid.Parse(seed); err == nil && len(seed) == 36 {
+		sum := sha256.Sum256([]byte(id.String()))
+		return SeedFromDigest(sum[:]), nil
+	}
+
+	if len(seed) == digestHexLength {
+		if digest, err := hex.DecodeString(seed); err == nil {
+			return SeedFromDigest(digest), nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %q is not a decimal integer, UUID or 64-character hex digest", ErrInvalidSeed, seed)
+}
+
+// ParseNumericSeed parses an explicit decimal seed in [-2^63, 2^64-1].
+// Values above 2^63-1 are reinterpreted as int64 (two's complement).
+// Only canonical decimal forms are accepted (no sign on positive values,
+// no leading zeros, no whitespace).
+func ParseNumericSeed(seed string) (int64, error) {
+	if seed == "" || seed[0] == '+' || (len(seed) > 1 && seed[0] == '0') || strings.HasPrefix(seed, "-0") {
+		return 0, fmt.Errorf("%w: %q is not a canonical decimal integer", ErrInvalidSeed, seed)
+	}
+
+	if strings.HasPrefix(seed, "-") {
+		value, err := strconv.ParseInt(seed, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q is not a 64-bit integer", ErrInvalidSeed, seed)
+		}
+		return value, nil
+	}
+
+	value, err := strconv.ParseUint(seed, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q is not a 64-bit integer", ErrInvalidSeed, seed)
+	}
+	return int64(value), nil
+}
+
+// SeedFromDigest returns the engine seed for a hash digest: its first
+// 8 bytes read as a big-endian uint64, reinterpreted as int64.
+// The digest must be at least 8 bytes long.
+func SeedFromDigest(digest []byte) int64 {
+	return int64(binary.BigEndian.Uint64(digest[:8]))
+}
+
+// TrackSeed derives the engine seed of track i of a multi-track session
+// from the session's engine seed.
+//
+// Track 0 uses the session seed itself, so a single-track session is
+// unchanged. Track i > 0 uses SHA-256 over the 16 bytes
+// bigEndian(uint64(seed)) || bigEndian(uint64(i)), reduced with
+// SeedFromDigest, so tracks are independent of each other.
+func TrackSeed(seed int64, track int) int64 {
+	if track == 0 {
+		return seed
+	}
+
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[:8], uint64(seed))
+	binary.BigEndian.PutUint64(buf[8:], uint64(track))
+	sum := sha256.Sum256(buf[:])
+	return SeedFromDigest(sum[:])
+}
+
+// parseSeedV1 is the original seed conversion. Its output must never
+// change, since version 1 sessions depend on it.
+func parseSeedV1(seedStr string) int64 {
+	// Try parsing as numeric first
+	if seed, err := strconv.ParseInt(seedStr, 10, 64); err == nil {
+		return seed
+	}
+
+	// Otherwise hash: hash = hash*31 + byte
+	var hash int64
+	for _, b := range []byte(seedStr) {
+		hash = hash*31 + int64(b)
+	}
+	// Ensure positive (math.MinInt64 stays negative)
+	if hash < 0 {
+		hash = -hash
+	}
+	return hash
+}
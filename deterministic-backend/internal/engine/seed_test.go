@@ -0,0 +1,128 @@
+package engine
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParseSeed_Version1(t *testing.T) {
+	// Frozen: version 1 sessions depend on these values
+	tests := []struct {
+		seed     string
+		expected int64
+	}{
+		{"12345", 12345},
+		{"-42", -42},
+		{"test-seed-987654321", 982424114146720396},
+		{"550e8400-e29b-41d4-a716-446655440000", 7367939282728386611},
+	}
+
+	for _, tt := range tests {
+		for _, version := range []int{0, Version1} {
+			got, err := ParseSeed(version, tt.seed)
+			if err != nil {
+				t.Fatalf("ParseSeed(%d, %q): unexpected error: %v", version, tt.seed, err)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseSeed(%d, %q): expected %d, got %d", version, tt.seed, tt.expected, got)
+			}
+		}
+	}
+}
+
+func TestParseSeed_Version2(t *testing.T) {
+	tests := []struct {
+		name     string
+		seed     string
+		expected int64
+		wantErr  bool
+	}{
+		{name: "decimal", seed: "12345", expected: 12345},
+		{name: "zero", seed: "0", expected: 0},
+		{name: "negative", seed: "-42", expected: -42},
+		{name: "min int64", seed: "-9223372036854775808", expected: math.MinInt64},
+		{name: "max uint64", seed: "18446744073709551615", expected: -1},
+		{name: "max int64 + 1", seed: "9223372036854775808", expected: math.MinInt64},
+		// SHA-256("550e8400-e29b-41d4-a716-446655440000") starts with a3a9e1ed9732cab2
+		{name: "uuid", seed: "550e8400-e29b-41d4-a716-446655440000", expected: -6653538563903010126},
+		{name: "uppercase uuid", seed: "550E8400-E29B-41D4-A716-446655440000", expected: -6653538563903010126},
+		{name: "hex digest", seed: "00000000000000ff000000000000000000000000000000000000000000000000", expected: 255},
+		{name: "leading zero", seed: "0123", wantErr: true},
+		{name: "plus sign", seed: "+5", wantErr: true},
+		{name: "negative zero", seed: "-0", wantErr: true},
+		{name: "overflow", seed: "18446744073709551616", wantErr: true},
+		{name: "free text", seed: "test-seed-987654321", wantErr: true},
+		{name: "uuid without dashes", seed: "550e8400e29b41d4a716446655440000", wantErr: true},
+		{name: "empty", seed: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSeed(Version2, tt.seed)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidSeed) {
+					t.Fatalf("Expected ErrInvalidSeed, got %v (seed %d)", err, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+
+	if _, err := ParseSeed(99, "12345"); err == nil {
+		t.Error("Expected error for unknown engine version")
+	}
+}
+
+func TestParseSeed_Version2NoCollisions(t *testing.T) {
+	// The version 1 hash collides for strings like "Aa" and "BB";
+	// version 2 derives UUID seeds from SHA-256 and must not
+	a, err := ParseSeed(Version2, "00000000-0000-0000-0000-0000000000Aa")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	b, err := ParseSeed(Version2, "00000000-0000-0000-0000-0000000000BB")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if a == b {
+		t.Error("Expected different seeds for different UUIDs")
+	}
+	if parseSeedV1("Aa") != parseSeedV1("BB") {
+		t.Error("Expected version 1 hash collision for Aa and BB")
+	}
+}
+
+func TestTrackSeed(t *testing.T) {
+	// Frozen values: client SDKs port this derivation
+	tests := []struct {
+		seed     int64
+		track    int
+		expected int64
+	}{
+		{seed: 12345, track: 0, expected: 12345},
+		{seed: 12345, track: 1, expected: -3097467260299935684},
+		{seed: 12345, track: 2, expected: -266194962200589810},
+		{seed: -1, track: 1, expected: 9110530055553361591},
+	}
+
+	for _, tt := range tests {
+		if got := TrackSeed(tt.seed, tt.track); got != tt.expected {
+			t.Errorf("TrackSeed(%d, %d): expected %d, got %d", tt.seed, tt.track, tt.expected, got)
+		}
+	}
+
+	// Tracks of one session break at different times
+	rule := CounterRule{Algorithm: algorithmV2{}}
+	first := RuleRounds(rule, TrackSeed(12345, 1), 1000, 0, 1)[0].BreakStep
+	second := RuleRounds(rule, TrackSeed(12345, 2), 1000, 0, 1)[0].BreakStep
+	if first == second {
+		t.Errorf("Expected tracks to break at different steps, both broke at %d", first)
+	}
+}
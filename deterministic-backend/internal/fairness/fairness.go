@@ -0,0 +1,40 @@
+package fairness
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strconv"
+)
+
+// Commit returns the commitment for a server seed: hex(SHA-256(serverSeed)).
+//
+// The commitment is published when a session is created, while the seed
+// itself stays secret until the session is stopped. Anyone can then check
+// that the revealed seed matches the commitment.
+func Commit(serverSeed string) string {
+	sum := sha256.Sum256([]byte(serverSeed))
+	return hex.EncodeToString(sum[:])
+}
+
+// Verify reports whether serverSeed matches the given commitment.
+func Verify(serverSeed string, commitment string) bool {
+	expected := Commit(serverSeed)
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(commitment)) == 1
+}
+
+// MixSeed combines the server seed with a client seed and nonce:
+//
+//	hex(HMAC-SHA256(key = serverSeed, message = clientSeed + ":" + nonce))
+//
+// The client cannot steer the result, as the server seed stays secret until
+// it is revealed. The server is not bound by the client seed, though: it
+// arrives in the same request the server seed is generated in, so the
+// server could generate seeds until the mix suits it. The commitment only
+// proves that the seed did not change after creation.
+func MixSeed(serverSeed string, clientSeed string, nonce int64) string {
+	mac := hmac.New(sha256.New, []byte(serverSeed))
+	mac.Write([]byte(clientSeed + ":" + strconv.FormatInt(nonce, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
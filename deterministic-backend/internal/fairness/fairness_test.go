@@ -0,0 +1,48 @@
+package fairness
+
+import "testing"
+
+func TestCommit(t *testing.T) {
+	// SHA-256("abc")
+	expected := "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+	if got := Commit("abc"); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	seed := "550e8400-e29b-41d4-a716-446655440000"
+	commitment := Commit(seed)
+
+	if !Verify(seed, commitment) {
+		t.Error("Expected revealed seed to match its commitment")
+	}
+	if Verify("other-seed", commitment) {
+		t.Error("Expected different seed not to match commitment")
+	}
+	if Verify(seed, "") {
+		t.Error("Expected empty commitment not to match")
+	}
+}
+
+func TestMixSeed(t *testing.T) {
+	serverSeed := "server"
+
+	first := MixSeed(serverSeed, "client", 0)
+	if first != MixSeed(serverSeed, "client", 0) {
+		t.Error("MixSeed is not deterministic")
+	}
+
+	if first == MixSeed(serverSeed, "client", 1) {
+		t.Error("Expected different nonce to change the seed")
+	}
+	if first == MixSeed(serverSeed, "other", 0) {
+		t.Error("Expected different client seed to change the seed")
+	}
+	if first == MixSeed("other", "client", 0) {
+		t.Error("Expected different server seed to change the seed")
+	}
+	if len(first) != 64 {
+		t.Errorf("Expected 64 hex characters, got %d", len(first))
+	}
+}
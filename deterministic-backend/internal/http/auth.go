@@ -0,0 +1,115 @@
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/types"
+	"github.com/go-chi/chi/v5"
+)
+
+// playerTokenTTL is how long a player token is valid
+const playerTokenTTL = time.Hour
+
+// CreatePlayerToken handles POST /v1/players/{player}/tokens
+//
+// Admin only. The operator's backend, which knows who its players are,
+// issues each of them a token to act as themselves.
+func (h *Handler) CreatePlayerToken(w http.ResponseWriter, r *http.Request) {
+	if !h.isAdmin(r) {
+		h.respondError(w, http.StatusForbidden, "forbidden", "issuing player tokens requires an admin token")
+		return
+	}
+	if h.playerSecret == "" {
+		h.respondError(w, http.StatusNotImplemented, "player tokens disabled", "no player secret is configured")
+		return
+	}
+
+	playerID := chi.URLParam(r, "player")
+	if len(playerID) > maxPlayerIDLength {
+		h.respondError(w, http.StatusBadRequest, "invalid player_id", "player_id must be between 1 and 128 characters")
+		return
+	}
+
+	expiresAt := time.Now().Add(playerTokenTTL).Truncate(time.Second)
+	h.respondJSON(w, http.StatusCreated, types.PlayerTokenResponse{
+		PlayerID:  playerID,
+		Token:     signPlayerToken(h.playerSecret, playerID, expiresAt),
+		ExpiresAt: expiresAt.Format(time.RFC3339),
+	})
+}
+
+// authorizePlayer checks that the request may act for a player: it carries
+// the admin token, or an unexpired X-Player-Token issued to that player.
+// On failure it responds and returns false.
+func (h *Handler) authorizePlayer(w http.ResponseWriter, r *http.Request, playerID string) bool {
+	if h.isAdmin(r) {
+		return true
+	}
+
+	token := r.Header.Get("X-Player-Token")
+	if token == "" {
+		h.respondError(w, http.StatusUnauthorized, "unauthorized", "an X-Player-Token is required")
+		return false
+	}
+	tokenPlayer, err := verifyPlayerToken(h.playerSecret, token, time.Now())
+	if err != nil {
+		h.respondError(w, http.StatusUnauthorized, "unauthorized", err.Error())
+		return false
+	}
+	if tokenPlayer != playerID {
+		h.respondError(w, http.StatusForbidden, "forbidden", "the player token was issued to another player")
+		return false
+	}
+	return true
+}
+
+// signPlayerToken builds a token for a player: the base64url player ID,
+// the Unix expiry and an HMAC-SHA256 of both, joined by dots.
+func signPlayerToken(secret, playerID string, expiresAt time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(playerID)) + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + playerTokenMAC(secret, payload)
+}
+
+// verifyPlayerToken checks a token's signature and expiry and returns the
+// player it was issued to.
+func verifyPlayerToken(secret, token string, now time.Time) (string, error) {
+	if secret == "" {
+		return "", fmt.Errorf("player tokens are disabled")
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("malformed player token")
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(playerTokenMAC(secret, payload))) {
+		return "", fmt.Errorf("invalid player token")
+	}
+
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("malformed player token")
+	}
+	if now.Unix() >= expires {
+		return "", fmt.Errorf("player token expired")
+	}
+	playerID, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", fmt.Errorf("malformed player token")
+	}
+	return string(playerID), nil
+}
+
+// playerTokenMAC signs a token payload.
+func playerTokenMAC(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,115 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/types"
+	"github.com/go-chi/chi/v5"
+)
+
+func TestVerifyPlayerToken(t *testing.T) {
+	now := time.Now()
+	token := signPlayerToken("secret", "alice.smith", now.Add(time.Minute))
+
+	tests := []struct {
+		name     string
+		secret   string
+		token    string
+		now      time.Time
+		expected string
+	}{
+		{name: "valid", secret: "secret", token: token, now: now, expected: "alice.smith"},
+		{name: "expired", secret: "secret", token: token, now: now.Add(time.Hour)},
+		{name: "other secret", secret: "other", token: token, now: now},
+		{name: "no secret", token: token, now: now},
+		{name: "tampered player", secret: "secret", token: "Ym9i" + token[len("YWxpY2Uuc21pdGg"):], now: now},
+		{name: "malformed", secret: "secret", token: "alice", now: now},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			playerID, err := verifyPlayerToken(tt.secret, tt.token, tt.now)
+			if tt.expected == "" && err == nil {
+				t.Errorf("Expected an error, got player %q", playerID)
+			}
+			if tt.expected != "" && (err != nil || playerID != tt.expected) {
+				t.Errorf("Expected player %q, got %q (%v)", tt.expected, playerID, err)
+			}
+		})
+	}
+}
+
+func TestHandler_CreatePlayerToken(t *testing.T) {
+	router := chi.NewRouter()
+	router.Mount("/", NewHandler(newMockStore(), WithAdminToken("admin"), WithPlayerSecret(testPlayerSecret)).Routes())
+
+	req := httptest.NewRequest("POST", "/v1/players/alice/tokens", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 without the admin token, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("POST", "/v1/players/alice/tokens", nil)
+	req.Header.Set("X-Admin-Token", "admin")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var response types.PlayerTokenResponse
+	json.NewDecoder(w.Body).Decode(&response)
+	if playerID, err := verifyPlayerToken(testPlayerSecret, response.Token, time.Now()); err != nil || playerID != "alice" {
+		t.Errorf("Expected a token for alice, got %q (%v)", playerID, err)
+	}
+}
+
+func TestHandler_PlayerAuthorization(t *testing.T) {
+	store := newMockStore()
+	router := chi.NewRouter()
+	router.Mount("/", NewHandler(store, WithAdminToken("admin"), WithPlayerSecret(testPlayerSecret)).Routes())
+
+	store.CreateSession(context.Background(), &types.Session{
+		ID:        "test-session-auth",
+		Seed:      "12345",
+		StartAt:   time.Now().Add(time.Hour),
+		TickMs:    100,
+		Status:    "running",
+		CreatedAt: time.Now(),
+	})
+
+	tests := []struct {
+		name           string
+		header         string
+		value          string
+		expectedStatus int
+	}{
+		{name: "no token", expectedStatus: http.StatusUnauthorized},
+		{name: "invalid token", header: "X-Player-Token", value: "not-a-token", expectedStatus: http.StatusUnauthorized},
+		{name: "expired token", header: "X-Player-Token", value: signPlayerToken(testPlayerSecret, "alice", time.Now().Add(-time.Minute)), expectedStatus: http.StatusUnauthorized},
+		{name: "another player's token", header: "X-Player-Token", value: signPlayerToken(testPlayerSecret, "mallory", time.Now().Add(time.Hour)), expectedStatus: http.StatusForbidden},
+		{name: "own token", header: "X-Player-Token", value: signPlayerToken(testPlayerSecret, "alice", time.Now().Add(time.Hour)), expectedStatus: http.StatusCreated},
+		{name: "admin token", header: "X-Admin-Token", value: "admin", expectedStatus: http.StatusConflict}, // Already joined
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := json.Marshal(types.JoinRoundRequest{PlayerID: "alice"})
+			req := httptest.NewRequest("POST", "/v1/sessions/test-session-auth/rounds/0/join", bytes.NewReader(data))
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -2,28 +2,111 @@ package http
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
-	"github.com/go-chi/chi/v5"
-	"github.com/google/uuid"
 	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/engine"
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/fairness"
 	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/store"
 	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/types"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxClientSeedLength bounds the client seed accepted at creation
+	maxClientSeedLength = 256
+
+	// maxTracks bounds the tracks of a multi-track session
+	maxTracks = 16
+
+	// defaultVerifyLimit and maxVerifyLimit bound rounds per verify response
+	defaultVerifyLimit = 1000
+	maxVerifyLimit     = 10000
+
+	// maxBatchSessions bounds the sessions of a batch state request
+	maxBatchSessions = 100
+
+	// defaultListLimit and maxListLimit bound sessions per list response
+	defaultListLimit = 20
+	maxListLimit     = 100
+
+	// maxStateLookahead bounds how far past the current time admins may
+	// query the state; computing it costs O(rounds) up to that time
+	maxStateLookahead = 24 * time.Hour
+
+	// defaultIdempotencyWindow is how long Idempotency-Keys are remembered
+	defaultIdempotencyWindow = 24 * time.Hour
 )
 
 // Handler holds HTTP handlers and dependencies
 type Handler struct {
-	store store.Store
+	store             store.Store
+	ledger            store.Ledger  // Wallet ledger for stakes and payouts (nil = wallets disabled)
+	adminToken        string        // Token required for admin-only features (empty = disabled)
+	playerSecret      string        // Key signing player tokens (empty = only admins act for players)
+	maxDuration       time.Duration // Session lifetime after start_at (0 = unlimited)
+	idempotencyWindow time.Duration // How long Idempotency-Keys are remembered (0 = ignored)
+}
+
+// Option configures optional Handler settings
+type Option func(*Handler)
+
+// WithAdminToken enables admin-only features for requests carrying the
+// token in the X-Admin-Token header.
+func WithAdminToken(token string) Option {
+	return func(h *Handler) {
+		h.adminToken = token
+	}
+}
+
+// WithPlayerSecret sets the key player tokens are signed with. Without
+// one, only admins can act for players.
+func WithPlayerSecret(secret string) Option {
+	return func(h *Handler) {
+		h.playerSecret = secret
+	}
+}
+
+// WithMaxSessionDuration makes new sessions expire the given duration after
+// they start. Expired sessions are frozen like stopped ones.
+func WithMaxSessionDuration(d time.Duration) Option {
+	return func(h *Handler) {
+		h.maxDuration = d
+	}
+}
+
+// WithIdempotencyWindow sets how long session creation remembers an
+// Idempotency-Key and its response (default 24h, 0 = keys are ignored).
+func WithIdempotencyWindow(d time.Duration) Option {
+	return func(h *Handler) {
+		h.idempotencyWindow = d
+	}
+}
+
+// WithLedger enables wallets: players stake from their balance when they
+// join a round and are paid out when they cash out.
+func WithLedger(ledger store.Ledger) Option {
+	return func(h *Handler) {
+		h.ledger = ledger
+	}
 }
 
 // NewHandler creates a new HTTP handler
-func NewHandler(store store.Store) *Handler {
-	return &Handler{
-		store: store,
+func NewHandler(store store.Store, opts ...Option) *Handler {
+	h := &Handler{
+		store:             store,
+		idempotencyWindow: defaultIdempotencyWindow,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 // Routes sets up all HTTP routes
@@ -33,9 +116,26 @@ func (h *Handler) Routes() chi.Router {
 	// API v1 routes
 	r.Route("/v1", func(r chi.Router) {
 		r.Post("/sessions", h.CreateSession)
+		r.Get("/sessions", h.ListSessions)
+		r.Post("/sessions/states", h.GetSessionStates)
 		r.Get("/sessions/{id}", h.GetSession)
 		r.Get("/sessions/{id}/state", h.GetSessionState)
 		r.Post("/sessions/{id}/stop", h.StopSession)
+		r.Post("/sessions/{id}/pause", h.PauseSession)
+		r.Post("/sessions/{id}/resume", h.ResumeSession)
+		r.Get("/sessions/{id}/verify", h.VerifySession)
+		r.Get("/sessions/{id}/stream", h.StreamSession)
+		r.Get("/sessions/{id}/events", h.StreamSessionEvents)
+		r.Post("/sessions/{id}/rounds/{round}/join", h.JoinRound)
+		r.Post("/sessions/{id}/rounds/{round}/cashout", h.CashOut)
+		r.Get("/sessions/{id}/rounds/{round}/players/{player}", h.GetEntry)
+		r.Post("/sessions/{id}/rounds/{round}/reconcile", h.ReconcileRound)
+		r.Get("/sessions/{id}/leaderboards/{board}", h.GetSessionLeaderboard)
+		r.Get("/leaderboards/{board}", h.GetLeaderboard)
+		r.Post("/players/{player}/tokens", h.CreatePlayerToken)
+		r.Get("/wallets/{player}", h.GetWallet)
+		r.Post("/wallets/{player}/deposit", h.Deposit)
+		r.Post("/wallets/{player}/withdraw", h.Withdraw)
 	})
 
 	// Health check
@@ -62,17 +162,130 @@ func (h *Handler) CreateSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Retries carrying the same Idempotency-Key replay the first response
+	if key := r.Header.Get("Idempotency-Key"); key != "" && h.idempotencyWindow > 0 {
+		h.createSessionOnce(ctx, w, r, key, req)
+		return
+	}
+	h.createSession(ctx, w, r, req)
+}
+
+// createSession validates a creation request and creates the session.
+func (h *Handler) createSession(ctx context.Context, w http.ResponseWriter, r *http.Request, req types.CreateSessionRequest) {
+	// Validate the tick schedule; tick_ms defaults to its first tick
+	schedule := tickSchedule(req.TickSchedule)
+	if schedule != nil {
+		if err := schedule.Validate(); err != nil {
+			h.respondError(w, http.StatusBadRequest, "invalid tick_schedule", err.Error())
+			return
+		}
+		if req.TickMs == 0 {
+			req.TickMs = int(schedule.FirstTickMs())
+		}
+		if int64(req.TickMs) != schedule.FirstTickMs() {
+			h.respondError(w, http.StatusBadRequest, "invalid tick_ms", "tick_ms must match the first tick of tick_schedule")
+			return
+		}
+	}
+
 	// Validate tickMs
 	if req.TickMs <= 0 {
 		h.respondError(w, http.StatusBadRequest, "invalid tick_ms", "tick_ms must be greater than 0")
 		return
 	}
 
+	// Validate the client seed and nonce
+	if len(req.ClientSeed) > maxClientSeedLength {
+		h.respondError(w, http.StatusBadRequest, "invalid client_seed", "client_seed must be at most 256 characters")
+		return
+	}
+	if req.Nonce < 0 {
+		h.respondError(w, http.StatusBadRequest, "invalid nonce", "nonce must not be negative")
+		return
+	}
+
+	// Validate track count (0 is the default, a single track)
+	if req.Tracks < 0 || req.Tracks > maxTracks {
+		h.respondError(w, http.StatusBadRequest, "invalid tracks", "tracks must be between 1 and 16, or 0 or omitted for 1")
+		return
+	}
+
+	// Validate lobby and intermission lengths
+	phases := engine.Phases{LobbyTicks: req.LobbyTicks, IntermissionTicks: req.IntermissionTicks}
+	if err := phases.Validate(); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid phases", err.Error())
+		return
+	}
+
+	// Validate end conditions
+	if req.End != nil {
+		if err := endCondition(req.End).Validate(); err != nil {
+			h.respondError(w, http.StatusBadRequest, "invalid end", err.Error())
+			return
+		}
+	}
+
+	// Pin the engine version (default: latest)
+	engineVersion := req.EngineVersion
+	if engineVersion == 0 {
+		engineVersion = engine.LatestVersion
+	}
+	if _, err := engine.AlgorithmFor(engineVersion); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid engine_version", err.Error())
+		return
+	}
+
+	// Every metadata label is indexed: bound them
+	if labels := store.SessionLabels(&types.Session{Metadata: req.Metadata}); len(labels) > store.MaxSessionLabels {
+		h.respondError(w, http.StatusBadRequest, "invalid metadata", fmt.Sprintf("metadata may have at most %d labels (top-level strings of up to %d bytes)", store.MaxSessionLabels, store.MaxLabelValueLength))
+		return
+	}
+
+	// Validate break interval settings
+	interval := intervalSpec(req.Interval)
+	if interval != nil {
+		normalized, err := interval.Normalize()
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "invalid interval", err.Error())
+			return
+		}
+		interval = &normalized
+	}
+
+	// Validate mode and its parameters
+	rule, err := engine.NewRule(req.Mode, engine.RuleConfig{
+		Version:  engineVersion,
+		Params:   req.ModeParams,
+		Interval: interval,
+	})
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid mode", err.Error())
+		return
+	}
+
 	// Generate session ID (format: sess_xxx)
 	sessionID := "sess_" + uuid.New().String()
 
-	// Generate seed (UUID as string for now, can be changed to uint64)
+	// Generate seed (UUID as string), or use the explicit numeric seed
+	// supplied by an admin for reproducible QA sessions
 	seed := uuid.New().String()
+	if req.Seed != "" {
+		if !h.isAdmin(r) {
+			h.respondError(w, http.StatusForbidden, "forbidden", "explicit seeds require an admin token")
+			return
+		}
+		value, err := engine.ParseNumericSeed(req.Seed)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "invalid seed", err.Error())
+			return
+		}
+		// Version 1 only treats seeds within int64 as numeric
+		if derived, _ := engine.ParseSeed(engineVersion, req.Seed); derived != value {
+			h.respondError(w, http.StatusBadRequest, "invalid seed", "seed must fit in int64 for engine_version 1")
+			return
+		}
+		seed = req.Seed
+	}
 
 	// Determine start time
 	var startAt time.Time
@@ -91,13 +304,29 @@ func (h *Handler) CreateSession(w http.ResponseWriter, r *http.Request) {
 
 	// Create session
 	session := &types.Session{
-		ID:        sessionID,
-		Seed:      seed,
-		StartAt:   startAt,
-		TickMs:    req.TickMs,
-		Metadata:  req.Metadata,
-		Status:    "running",
-		CreatedAt: time.Now(),
+		ID:                sessionID,
+		Seed:              seed,
+		SeedHash:          fairness.Commit(seed),
+		ClientSeed:        req.ClientSeed,
+		Nonce:             req.Nonce,
+		StartAt:           startAt,
+		TickMs:            req.TickMs,
+		TickSchedule:      req.TickSchedule,
+		EngineVersion:     engineVersion,
+		Mode:              rule.Mode(),
+		ModeParams:        rule.Params(),
+		Interval:          intervalConfig(interval),
+		Tracks:            req.Tracks,
+		LobbyTicks:        req.LobbyTicks,
+		IntermissionTicks: req.IntermissionTicks,
+		End:               req.End,
+		Metadata:          req.Metadata,
+		Status:            types.PhaseRunning,
+		CreatedAt:         time.Now(),
+	}
+	if h.maxDuration > 0 {
+		expiresAt := startAt.Add(h.maxDuration)
+		session.ExpiresAt = &expiresAt
 	}
 
 	// Store session
@@ -110,14 +339,27 @@ func (h *Handler) CreateSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Return response
+	// Return response (the seed stays secret until the session is stopped)
 	response := types.CreateSessionResponse{
-		ID:       session.ID,
-		Seed:     session.Seed,
-		StartAt:  session.StartAt.Format(time.RFC3339),
-		TickMs:   session.TickMs,
-		Metadata: session.Metadata,
-		Status:   session.Status,
+		ID:                session.ID,
+		SeedHash:          session.SeedHash,
+		ClientSeed:        session.ClientSeed,
+		Nonce:             session.Nonce,
+		StartAt:           session.StartAt.Format(time.RFC3339),
+		TickMs:            session.TickMs,
+		TickSchedule:      session.TickSchedule,
+		EngineVersion:     sessionEngineVersion(session),
+		Mode:              sessionMode(session),
+		ModeParams:        session.ModeParams,
+		Interval:          session.Interval,
+		Tracks:            sessionTracks(session),
+		LobbyTicks:        session.LobbyTicks,
+		IntermissionTicks: session.IntermissionTicks,
+		End:               session.End,
+		Metadata:          session.Metadata,
+		Status:            session.Status,
+		Phase:             session.Phase(session.CreatedAt),
+		ExpiresAt:         formatTime(session.ExpiresAt),
 	}
 
 	h.respondJSON(w, http.StatusCreated, response)
@@ -135,7 +377,8 @@ func (h *Handler) GetSession(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get session from store
-	session, err := h.store.GetSession(ctx, sessionID)
+	now := time.Now()
+	session, err := h.getSession(ctx, sessionID, now)
 	if err != nil {
 		if err == store.ErrSessionNotFound {
 			h.respondError(w, http.StatusNotFound, "session not found", err.Error())
@@ -145,20 +388,145 @@ func (h *Handler) GetSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Return response
-	response := types.GetSessionResponse{
-		ID:       session.ID,
-		Seed:     session.Seed,
-		StartAt:  session.StartAt.Format(time.RFC3339),
-		TickMs:   session.TickMs,
-		Metadata: session.Metadata,
-		Status:   session.Status,
+	h.respondJSON(w, http.StatusOK, sessionResponse(session, now))
+}
+
+// ListSessions handles GET /v1/sessions
+//
+// It lists sessions newest first, filtered by status, creation time and
+// metadata labels (label=key=value, repeatable; all must match), one page
+// of up to limit sessions at a time. Pass next_cursor as cursor to get the
+// next page. The status filter matches the current phase, and listed
+// sessions that expired or finished since they were stored are settled.
+func (h *Handler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	params := r.URL.Query()
+	query := store.SessionQuery{
+		Cursor: params.Get("cursor"),
+		Limit:  defaultListLimit,
+		Phase:  func(session *types.Session) string { return currentPhase(session, now) },
+	}
+
+	if v := params.Get("status"); v != "" {
+		switch v {
+		case types.PhaseScheduled, types.PhaseRunning, types.PhasePaused, types.PhaseStopped, types.PhaseExpired, types.PhaseFinished:
+			query.Status = v
+		default:
+			h.respondError(w, http.StatusBadRequest, "invalid status", "status must be scheduled, running, paused, stopped, expired or finished")
+			return
+		}
+	}
+
+	if v := params.Get("created_after"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "invalid created_after", "created_after must be an RFC3339 time")
+			return
+		}
+		query.CreatedAfter = &parsed
+	}
+
+	if v := params.Get("created_before"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "invalid created_before", "created_before must be an RFC3339 time")
+			return
+		}
+		query.CreatedBefore = &parsed
+	}
+
+	for _, v := range params["label"] {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok || key == "" {
+			h.respondError(w, http.StatusBadRequest, "invalid label", "label must be key=value")
+			return
+		}
+		if query.Labels == nil {
+			query.Labels = make(map[string]string)
+		}
+		query.Labels[key] = value
+	}
+
+	if v := params.Get("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 || parsed > maxListLimit {
+			h.respondError(w, http.StatusBadRequest, "invalid limit", "limit must be between 1 and 100")
+			return
+		}
+		query.Limit = parsed
+	}
+
+	page, err := h.store.ListSessions(ctx, query)
+	if err != nil {
+		if err == store.ErrInvalidCursor {
+			h.respondError(w, http.StatusBadRequest, "invalid cursor", err.Error())
+			return
+		}
+		h.respondError(w, http.StatusInternalServerError, "failed to list sessions", err.Error())
+		return
+	}
+
+	response := types.ListSessionsResponse{
+		Sessions:   make([]types.GetSessionResponse, 0, len(page.Sessions)),
+		NextCursor: page.NextCursor,
+	}
+	for _, session := range page.Sessions {
+		if err := h.settleSession(ctx, session, now); err != nil {
+			h.respondError(w, http.StatusInternalServerError, "failed to list sessions", err.Error())
+			return
+		}
+		response.Sessions = append(response.Sessions, sessionResponse(session, now))
 	}
 
 	h.respondJSON(w, http.StatusOK, response)
 }
 
+// sessionResponse builds the public view of a session. The seed is
+// revealed only once the session has ended.
+func sessionResponse(session *types.Session, now time.Time) types.GetSessionResponse {
+	response := types.GetSessionResponse{
+		ID:                session.ID,
+		SeedHash:          seedHash(session),
+		ClientSeed:        session.ClientSeed,
+		Nonce:             session.Nonce,
+		StartAt:           session.StartAt.Format(time.RFC3339),
+		TickMs:            session.TickMs,
+		TickSchedule:      session.TickSchedule,
+		EngineVersion:     sessionEngineVersion(session),
+		Mode:              sessionMode(session),
+		ModeParams:        session.ModeParams,
+		Interval:          session.Interval,
+		Tracks:            sessionTracks(session),
+		LobbyTicks:        session.LobbyTicks,
+		IntermissionTicks: session.IntermissionTicks,
+		End:               session.End,
+		Metadata:          session.Metadata,
+		Status:            session.Status,
+		Phase:             session.Phase(now),
+		Pauses:            session.Pauses,
+		ExpiresAt:         formatTime(session.ExpiresAt),
+		StoppedAt:         formatTime(session.StoppedAt),
+		FinalState:        session.FinalState,
+		FinalTracks:       session.FinalTracks,
+		CreatedAt:         session.CreatedAt.Format(time.RFC3339),
+	}
+
+	// Reveal the seed only once the session has ended
+	if session.Ended() {
+		response.Seed = session.Seed
+	}
+	return response
+}
+
 // GetSessionState handles GET /v1/sessions/{id}/state
+//
+// By default it returns the current state. ?at=<RFC3339> returns the state
+// at that time and ?step=<n> the state at that step. Until the session has
+// ended, and its seed is revealed, only admins may query a time or step it
+// has not reached yet.
 func (h *Handler) GetSessionState(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
@@ -169,8 +537,34 @@ func (h *Handler) GetSessionState(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var at *time.Time
+	if v := r.URL.Query().Get("at"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "invalid at", "at must be an RFC3339 time")
+			return
+		}
+		at = &parsed
+	}
+
+	step := int64(-1)
+	if v := r.URL.Query().Get("step"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || parsed < 0 {
+			h.respondError(w, http.StatusBadRequest, "invalid step", "step must be a non-negative integer")
+			return
+		}
+		step = parsed
+	}
+
+	if at != nil && step >= 0 {
+		h.respondError(w, http.StatusBadRequest, "invalid query", "at and step are mutually exclusive")
+		return
+	}
+
 	// Get session from store
-	session, err := h.store.GetSession(ctx, sessionID)
+	now := time.Now()
+	session, err := h.getSession(ctx, sessionID, now)
 	if err != nil {
 		if err == store.ErrSessionNotFound {
 			h.respondError(w, http.StatusNotFound, "session not found", err.Error())
@@ -180,35 +574,126 @@ func (h *Handler) GetSessionState(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse seed from string to int64
-	// If seed is UUID, convert to int64 hash; if already numeric, parse directly
-	seed, err := parseSeedToInt64(session.Seed)
+	// Derive the engine's int64 seed from the session seed
+	seed, err := engineSeed(session)
 	if err != nil {
 		h.respondError(w, http.StatusInternalServerError, "invalid seed format", err.Error())
 		return
 	}
 
-	// Compute current state using deterministic engine
-	now := time.Now()
-	state := engine.StateAt(
-		seed,
-		session.StartAt,
-		int64(session.TickMs),
-		now,
-	)
+	// Select the game rule for this session
+	rule, err := sessionRule(session)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "invalid session mode", err.Error())
+		return
+	}
+
+	// Compute state of every track using deterministic engine. Queried
+	// times and steps are bounded by the session's last step, or for
+	// admins by maxStateLookahead, as computing them costs O(rounds)
+	states := sessionStates(session, rule, seed, now)
+	phase := session.Phase(now)
+	switch {
+	case at != nil:
+		if at.After(now) && !session.Ended() {
+			if !h.isAdmin(r) {
+				h.respondError(w, http.StatusForbidden, "future state", "only admins may query the state after the current time")
+				return
+			}
+			if at.After(now.Add(maxStateLookahead)) {
+				h.respondError(w, http.StatusBadRequest, "invalid at", "at must be at most 24h after the current time")
+				return
+			}
+		}
+		states = sessionStatesAt(session, rule, seed, *at, now)
+		phase = session.PhaseAt(*at)
+	case step >= 0:
+		if !stepReached(states, step) {
+			switch {
+			case session.Ended():
+				h.respondError(w, http.StatusBadRequest, "invalid step", "step is after the session's last step")
+				return
+			case !h.isAdmin(r):
+				h.respondError(w, http.StatusForbidden, "future state", "only admins may query a step the session has not reached")
+				return
+			case !stepReached(sessionStatesAt(session, rule, seed, now.Add(maxStateLookahead), now), step):
+				h.respondError(w, http.StatusBadRequest, "invalid step", "step must be reached within 24h of the current time")
+				return
+			}
+		}
+		states = trackStatesAtStep(session, rule, seed, step)
+	}
 
 	// Return response
-	response := types.SessionStateResponse{
-		Step:       state.Step,
-		Value:      state.Value,
-		Round:      state.Round,
-		Broken:     state.Broken,
-		ComputedAt: now.Format(time.RFC3339),
+	response := stateResponse(session, rule, seed, states, phase, now)
+	response.At = formatTime(at)
+	h.respondJSON(w, http.StatusOK, response)
+}
+
+// GetSessionStates handles POST /v1/sessions/states
+//
+// It returns the current state of up to maxBatchSessions sessions, loaded
+// with one store call, in request order. A session that cannot be loaded
+// gets an error instead of a state; the request itself still succeeds.
+func (h *Handler) GetSessionStates(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	var req types.BatchStateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	if len(req.IDs) == 0 || len(req.IDs) > maxBatchSessions {
+		h.respondError(w, http.StatusBadRequest, "invalid ids", fmt.Sprintf("ids must have between 1 and %d entries", maxBatchSessions))
+		return
+	}
+
+	sessions, err := h.store.GetSessions(ctx, req.IDs)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "failed to get sessions", err.Error())
+		return
+	}
+
+	now := time.Now()
+	response := types.BatchStateResponse{States: make([]types.BatchStateItem, len(req.IDs))}
+	for i, id := range req.IDs {
+		item := &response.States[i]
+		item.ID = id
+		state, errorMsg, err := h.batchState(ctx, sessions[i], now)
+		if err != nil {
+			item.Error = &types.ErrorResponse{Error: errorMsg, Message: err.Error()}
+			continue
+		}
+		item.State = state
 	}
 
 	h.respondJSON(w, http.StatusOK, response)
 }
 
+// batchState computes the current state of one session of a batch. On
+// failure it returns the error type to report and the error.
+func (h *Handler) batchState(ctx context.Context, session *types.Session, now time.Time) (*types.SessionStateResponse, string, error) {
+	if session == nil {
+		return nil, "session not found", store.ErrSessionNotFound
+	}
+	if err := h.settleSession(ctx, session, now); err != nil {
+		return nil, "failed to get session", err
+	}
+
+	seed, err := engineSeed(session)
+	if err != nil {
+		return nil, "invalid seed format", err
+	}
+	rule, err := sessionRule(session)
+	if err != nil {
+		return nil, "invalid session mode", err
+	}
+
+	response := stateResponse(session, rule, seed, sessionStates(session, rule, seed, now), session.Phase(now), now)
+	return &response, "", nil
+}
+
 // StopSession handles POST /v1/sessions/{id}/stop
 func (h *Handler) StopSession(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
@@ -221,7 +706,8 @@ func (h *Handler) StopSession(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get session
-	session, err := h.store.GetSession(ctx, sessionID)
+	now := time.Now()
+	session, err := h.getSession(ctx, sessionID, now)
 	if err != nil {
 		if err == store.ErrSessionNotFound {
 			h.respondError(w, http.StatusNotFound, "session not found", err.Error())
@@ -231,31 +717,309 @@ func (h *Handler) StopSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if already stopped
-	if session.Status == "stopped" {
-		h.respondError(w, http.StatusBadRequest, "session already stopped", "session is already stopped")
+	// Check if already ended
+	if session.Ended() {
+		h.respondError(w, http.StatusBadRequest, "session already "+session.Status, "session is already "+session.Status)
 		return
 	}
 
-	// Update session status
-	session.Status = "stopped"
-	now := time.Now()
-	session.StoppedAt = &now
+	// Update session status, freezing its final state
+	if err := finishSession(session, types.PhaseStopped, now); err != nil {
+		h.respondError(w, http.StatusInternalServerError, "failed to stop session", err.Error())
+		return
+	}
 
 	if err := h.store.UpdateSession(ctx, session); err != nil {
+		if err == store.ErrSessionConflict {
+			h.respondError(w, http.StatusConflict, "session changed", "the session was updated concurrently; retry")
+			return
+		}
 		h.respondError(w, http.StatusInternalServerError, "failed to stop session", err.Error())
 		return
 	}
 
-	// Return response
+	// Return response, revealing the seed
 	response := types.StopSessionResponse{
+		ID:       session.ID,
+		Status:   session.Status,
+		Seed:     session.Seed,
+		SeedHash: seedHash(session),
+	}
+
+	h.respondJSON(w, http.StatusOK, response)
+}
+
+// PauseSession handles POST /v1/sessions/{id}/pause
+//
+// Admin only. Freezes a running session: the time until it is resumed does
+// not count towards its steps, so value and round stay where they were.
+func (h *Handler) PauseSession(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if !h.isAdmin(r) {
+		h.respondError(w, http.StatusForbidden, "forbidden", "pausing a session requires an admin token")
+		return
+	}
+
+	sessionID := chi.URLParam(r, "id")
+	if sessionID == "" {
+		h.respondError(w, http.StatusBadRequest, "invalid session id", "session id is required")
+		return
+	}
+
+	now := time.Now()
+	session, err := h.getSession(ctx, sessionID, now)
+	if err != nil {
+		if err == store.ErrSessionNotFound {
+			h.respondError(w, http.StatusNotFound, "session not found", err.Error())
+			return
+		}
+		h.respondError(w, http.StatusInternalServerError, "failed to get session", err.Error())
+		return
+	}
+
+	switch session.Phase(now) {
+	case types.PhaseRunning:
+	case types.PhaseScheduled:
+		h.respondError(w, http.StatusConflict, "session not started", "session has not started yet")
+		return
+	default:
+		h.respondError(w, http.StatusConflict, "session not running", "only running sessions can be paused")
+		return
+	}
+
+	session.Status = types.PhasePaused
+	session.Pauses = append(session.Pauses, types.PauseWindow{PausedAt: now})
+
+	step, err := sessionStepAt(session, now)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "invalid session", err.Error())
+		return
+	}
+
+	if err := h.store.UpdateSession(ctx, session); err != nil {
+		if err == store.ErrSessionConflict {
+			h.respondError(w, http.StatusConflict, "session changed", "the session was updated concurrently; retry")
+			return
+		}
+		h.respondError(w, http.StatusInternalServerError, "failed to pause session", err.Error())
+		return
+	}
+
+	response := types.PauseSessionResponse{
 		ID:     session.ID,
 		Status: session.Status,
+		Step:   step,
 	}
 
 	h.respondJSON(w, http.StatusOK, response)
 }
 
+// ResumeSession handles POST /v1/sessions/{id}/resume
+//
+// Admin only.
+func (h *Handler) ResumeSession(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if !h.isAdmin(r) {
+		h.respondError(w, http.StatusForbidden, "forbidden", "resuming a session requires an admin token")
+		return
+	}
+
+	sessionID := chi.URLParam(r, "id")
+	if sessionID == "" {
+		h.respondError(w, http.StatusBadRequest, "invalid session id", "session id is required")
+		return
+	}
+
+	now := time.Now()
+	session, err := h.getSession(ctx, sessionID, now)
+	if err != nil {
+		if err == store.ErrSessionNotFound {
+			h.respondError(w, http.StatusNotFound, "session not found", err.Error())
+			return
+		}
+		h.respondError(w, http.StatusInternalServerError, "failed to get session", err.Error())
+		return
+	}
+
+	if session.Phase(now) != types.PhasePaused {
+		h.respondError(w, http.StatusConflict, "session not paused", "only paused sessions can be resumed")
+		return
+	}
+
+	closePause(session, now)
+	session.Status = types.PhaseRunning
+
+	step, err := sessionStepAt(session, now)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "invalid session", err.Error())
+		return
+	}
+
+	if err := h.store.UpdateSession(ctx, session); err != nil {
+		if err == store.ErrSessionConflict {
+			h.respondError(w, http.StatusConflict, "session changed", "the session was updated concurrently; retry")
+			return
+		}
+		h.respondError(w, http.StatusInternalServerError, "failed to resume session", err.Error())
+		return
+	}
+
+	response := types.PauseSessionResponse{
+		ID:     session.ID,
+		Status: session.Status,
+		Step:   step,
+	}
+
+	h.respondJSON(w, http.StatusOK, response)
+}
+
+// VerifySession handles GET /v1/sessions/{id}/verify
+//
+// Recomputes every round of a stopped session from its revealed seed and
+// checks the seed against the commitment published at creation.
+// Query parameters:
+//   - from_round: First round to return (default 0)
+//   - limit: Maximum number of rounds to return (default 1000, max 10000)
+func (h *Handler) VerifySession(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	sessionID := chi.URLParam(r, "id")
+	if sessionID == "" {
+		h.respondError(w, http.StatusBadRequest, "invalid session id", "session id is required")
+		return
+	}
+
+	fromRound := int64(0)
+	if v := r.URL.Query().Get("from_round"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || parsed < 0 {
+			h.respondError(w, http.StatusBadRequest, "invalid from_round", "from_round must be a non-negative integer")
+			return
+		}
+		fromRound = parsed
+	}
+
+	limit := defaultVerifyLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 || parsed > maxVerifyLimit {
+			h.respondError(w, http.StatusBadRequest, "invalid limit", "limit must be between 1 and 10000")
+			return
+		}
+		limit = parsed
+	}
+
+	track := 0
+	if v := r.URL.Query().Get("track"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 0 || parsed >= maxTracks {
+			h.respondError(w, http.StatusBadRequest, "invalid track", "track must be between 0 and 15")
+			return
+		}
+		track = parsed
+	}
+
+	// Get session from store
+	now := time.Now()
+	session, err := h.getSession(ctx, sessionID, now)
+	if err != nil {
+		if err == store.ErrSessionNotFound {
+			h.respondError(w, http.StatusNotFound, "session not found", err.Error())
+			return
+		}
+		h.respondError(w, http.StatusInternalServerError, "failed to get session", err.Error())
+		return
+	}
+
+	// The seed is only revealed once the session has ended
+	if !session.Ended() || session.StoppedAt == nil {
+		h.respondError(w, http.StatusConflict, "session not stopped", "the seed is revealed once the session is stopped or expired")
+		return
+	}
+
+	if track >= sessionTracks(session) {
+		h.respondError(w, http.StatusBadRequest, "invalid track", "session has no such track")
+		return
+	}
+
+	sessionSeed, err := engineSeed(session)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "invalid seed format", err.Error())
+		return
+	}
+	seed := engine.TrackSeed(sessionSeed, track)
+
+	rule, err := sessionRule(session)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "invalid session mode", err.Error())
+		return
+	}
+
+	// Last step reached before the session stopped (-1 if it never left
+	// the lobby)
+	stopStep := int64(-1)
+	clock := sessionClock(session)
+	if position := clock.PositionAt(rule, seed, *session.StoppedAt); clock.Started(*session.StoppedAt) && position.Phase != engine.GamePhaseLobby {
+		stopStep = position.Step
+	}
+
+	// Fetch one extra round to know whether there are more
+	rounds := engine.RuleRounds(rule, seed, stopStep, fromRound, limit+1)
+
+	response := types.VerifySessionResponse{
+		ID:              session.ID,
+		Seed:            session.Seed,
+		SeedHash:        seedHash(session),
+		CommitmentValid: fairness.Verify(session.Seed, seedHash(session)),
+		ClientSeed:      session.ClientSeed,
+		Nonce:           session.Nonce,
+		EngineVersion:   rule.Version(),
+		Mode:            rule.Mode(),
+		Track:           track,
+		StopStep:        stopStep,
+		Rounds:          make([]types.RoundResult, 0, len(rounds)),
+	}
+
+	if len(rounds) > limit {
+		next := rounds[limit].Round
+		response.NextRound = &next
+		rounds = rounds[:limit]
+	}
+
+	for _, info := range rounds {
+		// The break step is the first step of the next round
+		breakState := engine.State{
+			Step:   info.BreakStep,
+			Round:  info.Round + 1,
+			Broken: true,
+		}
+		response.Rounds = append(response.Rounds, types.RoundResult{
+			Round:     info.Round,
+			StartStep: info.StartStep,
+			BreakStep: info.BreakStep,
+			Interval:  info.Interval,
+			Completed: info.BreakStep <= stopStep,
+			Outcome:   rule.Outcome(seed, breakState),
+		})
+	}
+
+	h.respondJSON(w, http.StatusOK, response)
+}
+
+// isAdmin reports whether the request carries the configured admin token.
+func (h *Handler) isAdmin(r *http.Request) bool {
+	if h.adminToken == "" {
+		return false
+	}
+	token := r.Header.Get("X-Admin-Token")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.adminToken)) == 1
+}
+
 // respondJSON sends a JSON response
 func (h *Handler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
@@ -273,24 +1037,444 @@ func (h *Handler) respondError(w http.ResponseWriter, status int, errorMsg, mess
 	})
 }
 
-// parseSeedToInt64 converts a seed string (UUID or numeric) to int64.
-// This is a helper function to convert the stored seed string to the int64
-// format required by the engine.
-func parseSeedToInt64(seedStr string) (int64, error) {
-	// Try parsing as numeric first
-	if seed, err := strconv.ParseInt(seedStr, 10, 64); err == nil {
-		return seed, nil
+// seedHash returns the commitment of the session's server seed.
+// Sessions created before commitments were stored get it computed on the fly.
+func seedHash(session *types.Session) string {
+	if session.SeedHash != "" {
+		return session.SeedHash
+	}
+	return fairness.Commit(session.Seed)
+}
+
+// engineSeed returns the int64 seed the engine runs the session with,
+// derived as documented on engine.ParseSeed for the session's version.
+// When a client seed or nonce is set, it is mixed into the server seed.
+func engineSeed(session *types.Session) (int64, error) {
+	seedStr := session.Seed
+	if session.ClientSeed != "" || session.Nonce != 0 {
+		seedStr = fairness.MixSeed(session.Seed, session.ClientSeed, session.Nonce)
 	}
+	return engine.ParseSeed(sessionEngineVersion(session), seedStr)
+}
 
-	// If it's a UUID, convert to int64 by hashing
-	// Simple hash: sum of all bytes
-	var hash int64
-	for _, b := range []byte(seedStr) {
-		hash = hash*31 + int64(b)
+// getSession loads a session, persisting its end if its lifetime ran out
+// or its end condition was met since it was last stored.
+func (h *Handler) getSession(ctx context.Context, id string, now time.Time) (*types.Session, error) {
+	session, err := h.store.GetSession(ctx, id)
+	if err != nil {
+		return nil, err
 	}
-	// Ensure positive
-	if hash < 0 {
-		hash = -hash
+	if err := h.settleSession(ctx, session, now); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// maxSettleAttempts bounds how often settleSession reloads a session
+// updated concurrently
+const maxSettleAttempts = 3
+
+// settleSession persists the end of a loaded session if its lifetime ran
+// out or its end condition was met since it was last stored. A session
+// updated concurrently is reloaded and settled again.
+func (h *Handler) settleSession(ctx context.Context, session *types.Session, now time.Time) error {
+	for attempt := 1; ; attempt++ {
+		err := h.settleSessionOnce(ctx, session, now)
+		if err != store.ErrSessionConflict || attempt == maxSettleAttempts {
+			return err
+		}
+		reloaded, err := h.store.GetSession(ctx, session.ID)
+		if err != nil {
+			return err
+		}
+		*session = *reloaded
+	}
+}
+
+// settleSessionOnce is settleSession for the session as loaded.
+func (h *Handler) settleSessionOnce(ctx context.Context, session *types.Session, now time.Time) error {
+	if session.Ended() {
+		return nil
+	}
+
+	// The end condition may have been met before the expiry
+	at, status := now, ""
+	if session.Phase(now) == types.PhaseExpired {
+		at, status = *session.ExpiresAt, types.PhaseExpired
+	}
+	finished, err := sessionFinished(session, at)
+	if err != nil {
+		return err
+	}
+	if finished {
+		status = types.PhaseFinished
+	}
+	if status == "" {
+		return nil
+	}
+
+	if err := finishSession(session, status, at); err != nil {
+		return err
+	}
+	if err := h.store.UpdateSession(ctx, session); err != nil {
+		if err == store.ErrSessionConflict {
+			return err
+		}
+		return fmt.Errorf("failed to end session: %w", err)
+	}
+	return nil
+}
+
+// currentPhase returns a session's phase at now, counting an end
+// condition met since it was last stored as finished, like settleSession.
+func currentPhase(session *types.Session, now time.Time) string {
+	phase := session.Phase(now)
+	if session.Ended() {
+		return phase
+	}
+
+	at := now
+	if phase == types.PhaseExpired {
+		at = *session.ExpiresAt
+	}
+	if finished, err := sessionFinished(session, at); err == nil && finished {
+		return types.PhaseFinished
+	}
+	return phase
+}
+
+// sessionFinished reports whether every track of the session met its end
+// condition at the given time. The state freezes at the end whether or not
+// this has been persisted.
+func sessionFinished(session *types.Session, at time.Time) (bool, error) {
+	if session.End == nil {
+		return false, nil
+	}
+	seed, err := engineSeed(session)
+	if err != nil {
+		return false, err
+	}
+	rule, err := sessionRule(session)
+	if err != nil {
+		return false, err
+	}
+
+	clock := sessionClock(session)
+	for i := 0; i < sessionTracks(session); i++ {
+		if !clock.Ended(rule, engine.TrackSeed(seed, i), at) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// finishSession ends a session at the given time with status "stopped",
+// "expired" or "finished", closing an open pause window and freezing its
+// final state.
+func finishSession(session *types.Session, status string, at time.Time) error {
+	seed, err := engineSeed(session)
+	if err != nil {
+		return err
+	}
+	rule, err := sessionRule(session)
+	if err != nil {
+		return err
+	}
+
+	closePause(session, at)
+	states := trackStatesAt(session, rule, seed, at)
+
+	session.Status = status
+	session.StoppedAt = &at
+	final := finalState(states[0])
+	session.FinalState = &final
+	if len(states) > 1 {
+		session.FinalTracks = make([]types.FinalState, len(states))
+		for i, state := range states {
+			session.FinalTracks[i] = finalState(state)
+		}
+	}
+	return nil
+}
+
+// sessionStates returns the state of every track at the given time.
+// Ended sessions are frozen at their persisted final state (or at
+// StoppedAt if it predates persisted states); others advance with active
+// time only.
+func sessionStates(session *types.Session, rule engine.Rule, seed int64, now time.Time) []engine.State {
+	tracks := sessionTracks(session)
+	if session.FinalState != nil && tracks == 1 {
+		return []engine.State{engineState(*session.FinalState)}
+	}
+	if len(session.FinalTracks) == tracks {
+		states := make([]engine.State, tracks)
+		for i, final := range session.FinalTracks {
+			states[i] = engineState(final)
+		}
+		return states
+	}
+
+	if session.Ended() && session.StoppedAt != nil {
+		now = *session.StoppedAt
+	}
+	return trackStatesAt(session, rule, seed, now)
+}
+
+// stateResponse builds a state response from the state of every track;
+// the top-level fields are track 0.
+func stateResponse(session *types.Session, rule engine.Rule, seed int64, states []engine.State, phase string, now time.Time) types.SessionStateResponse {
+	state := states[0]
+	response := types.SessionStateResponse{
+		Step:          state.Step,
+		Value:         state.Value,
+		Round:         state.Round,
+		Broken:        state.Broken,
+		Status:        session.Status,
+		Phase:         phase,
+		GamePhase:     string(state.Phase),
+		TicksLeft:     state.TicksLeft,
+		EngineVersion: rule.Version(),
+		Mode:          rule.Mode(),
+		Outcome:       rule.Outcome(seed, state),
+		ComputedAt:    now.Format(time.RFC3339),
+	}
+	if len(states) > 1 {
+		for i, track := range states {
+			response.Tracks = append(response.Tracks, types.TrackState{
+				Track:     i,
+				Step:      track.Step,
+				Value:     track.Value,
+				Round:     track.Round,
+				Broken:    track.Broken,
+				GamePhase: string(track.Phase),
+				TicksLeft: track.TicksLeft,
+				Outcome:   rule.Outcome(engine.TrackSeed(seed, i), track),
+			})
+		}
+	}
+	return response
+}
+
+// sessionStatesAt returns the state of every track at a time that may be
+// past or future. After the session ended it is frozen at the end, after
+// ExpiresAt at the expiry; a future time assumes no further pauses.
+func sessionStatesAt(session *types.Session, rule engine.Rule, seed int64, at, now time.Time) []engine.State {
+	if session.Ended() && session.StoppedAt != nil && !at.Before(*session.StoppedAt) {
+		return sessionStates(session, rule, seed, now)
+	}
+	if session.ExpiresAt != nil && at.After(*session.ExpiresAt) {
+		at = *session.ExpiresAt
+	}
+	return trackStatesAt(session, rule, seed, at)
+}
+
+// trackStatesAtStep computes the state of every track at the given step.
+func trackStatesAtStep(session *types.Session, rule engine.Rule, seed int64, step int64) []engine.State {
+	states := make([]engine.State, sessionTracks(session))
+	for i := range states {
+		states[i] = engine.RuleStateAtStep(rule, engine.TrackSeed(seed, i), step)
+		states[i].Phase = engine.GamePhaseRunning
+	}
+	return states
+}
+
+// stepReached reports whether every track has reached the given step.
+// Before start and in the lobby no step has been reached.
+func stepReached(states []engine.State, step int64) bool {
+	for _, state := range states {
+		if state.Phase == "" || state.Phase == engine.GamePhaseLobby || step > state.Step {
+			return false
+		}
+	}
+	return true
+}
+
+// trackStatesAt computes the state of every track at the given time.
+// Track i runs the session rule on engine.TrackSeed(seed, i).
+func trackStatesAt(session *types.Session, rule engine.Rule, seed int64, at time.Time) []engine.State {
+	clock := sessionClock(session)
+	states := make([]engine.State, sessionTracks(session))
+	for i := range states {
+		states[i] = engine.ClockStateAt(rule, engine.TrackSeed(seed, i), clock, at)
+	}
+	return states
+}
+
+// finalState converts an engine state to its persisted form.
+func finalState(state engine.State) types.FinalState {
+	return types.FinalState{
+		Step:      state.Step,
+		Value:     state.Value,
+		Round:     state.Round,
+		Broken:    state.Broken,
+		GamePhase: string(state.Phase),
+		TicksLeft: state.TicksLeft,
+	}
+}
+
+// engineState converts a persisted final state back to an engine state.
+func engineState(final types.FinalState) engine.State {
+	return engine.State{
+		Step:      final.Step,
+		Value:     final.Value,
+		Round:     final.Round,
+		Broken:    final.Broken,
+		Phase:     engine.GamePhase(final.GamePhase),
+		TicksLeft: final.TicksLeft,
+	}
+}
+
+// formatTime formats an optional time as RFC3339.
+func formatTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format(time.RFC3339)
+	return &formatted
+}
+
+// sessionClock returns the clock that maps wall time to the session's steps.
+func sessionClock(session *types.Session) engine.Clock {
+	clock := engine.Clock{
+		StartAt:  session.StartAt,
+		TickMs:   int64(session.TickMs),
+		Schedule: tickSchedule(session.TickSchedule),
+		Phases: engine.Phases{
+			LobbyTicks:        session.LobbyTicks,
+			IntermissionTicks: session.IntermissionTicks,
+		},
+		End: endCondition(session.End),
+	}
+	for _, p := range session.Pauses {
+		pause := engine.Pause{From: p.PausedAt}
+		if p.ResumedAt != nil {
+			pause.Until = *p.ResumedAt
+		}
+		clock.Pauses = append(clock.Pauses, pause)
+	}
+	return clock
+}
+
+// sessionStepAt returns the session's step at the given time.
+func sessionStepAt(session *types.Session, at time.Time) (int64, error) {
+	seed, err := engineSeed(session)
+	if err != nil {
+		return 0, err
+	}
+	rule, err := sessionRule(session)
+	if err != nil {
+		return 0, err
+	}
+	return sessionClock(session).StepAt(rule, seed, at), nil
+}
+
+// tickSchedule converts a session tick schedule to its engine form.
+func tickSchedule(config *types.TickSchedule) *engine.TickSchedule {
+	if config == nil {
+		return nil
+	}
+	schedule := &engine.TickSchedule{RoundTicks: config.RoundTicks}
+	for _, segment := range config.Segments {
+		schedule.Segments = append(schedule.Segments, engine.TickSegment{
+			DurationMs: segment.DurationMs,
+			TickMs:     segment.TickMs,
+		})
+	}
+	return schedule
+}
+
+// endCondition converts session end conditions to their engine form.
+func endCondition(config *types.EndCondition) *engine.EndCondition {
+	if config == nil {
+		return nil
+	}
+	return &engine.EndCondition{
+		MaxRounds:     config.MaxRounds,
+		MaxDurationMs: config.MaxDurationMs,
+		StopAtStep:    config.StopAtStep,
+	}
+}
+
+// closePause ends the session's open pause window, if any.
+func closePause(session *types.Session, now time.Time) {
+	if n := len(session.Pauses); n > 0 && session.Pauses[n-1].ResumedAt == nil {
+		session.Pauses[n-1].ResumedAt = &now
+	}
+}
+
+// sessionRule creates the game rule configured on a session.
+func sessionRule(session *types.Session) (engine.Rule, error) {
+	return engine.NewRule(session.Mode, engine.RuleConfig{
+		Version:  session.EngineVersion,
+		Params:   session.ModeParams,
+		Interval: intervalSpec(session.Interval),
+	})
+}
+
+// intervalSpec converts an API interval configuration to the engine's spec.
+func intervalSpec(config *types.IntervalConfig) *engine.IntervalSpec {
+	if config == nil {
+		return nil
+	}
+
+	spec := &engine.IntervalSpec{
+		Min:          config.Min,
+		Max:          config.Max,
+		Distribution: config.Distribution,
+		P:            config.P,
+	}
+	for _, w := range config.Weights {
+		spec.Weights = append(spec.Weights, engine.WeightedInterval{
+			Interval: w.Interval,
+			Weight:   w.Weight,
+		})
+	}
+	return spec
+}
+
+// intervalConfig converts the engine's interval spec to its API representation.
+func intervalConfig(spec *engine.IntervalSpec) *types.IntervalConfig {
+	if spec == nil {
+		return nil
+	}
+
+	config := &types.IntervalConfig{
+		Min:          spec.Min,
+		Max:          spec.Max,
+		Distribution: spec.Distribution,
+		P:            spec.P,
+	}
+	for _, w := range spec.Weights {
+		config.Weights = append(config.Weights, types.WeightedInterval{
+			Interval: w.Interval,
+			Weight:   w.Weight,
+		})
+	}
+	return config
+}
+
+// sessionEngineVersion returns the engine version a session is pinned to.
+// Sessions created before versions were recorded run on version 1.
+func sessionEngineVersion(session *types.Session) int {
+	if session.EngineVersion == 0 {
+		return engine.Version1
+	}
+	return session.EngineVersion
+}
+
+// sessionTracks returns the number of tracks (sessions without tracks have one).
+func sessionTracks(session *types.Session) int {
+	if session.Tracks < 1 {
+		return 1
+	}
+	return session.Tracks
+}
+
+// sessionMode returns the session's game mode.
+// Sessions created before modes existed use the default mode.
+func sessionMode(session *types.Session) string {
+	if session.Mode == "" {
+		return engine.DefaultMode
 	}
-	return hash, nil
+	return session.Mode
 }
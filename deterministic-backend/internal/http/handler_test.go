@@ -4,123 +4,1471 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/engine"
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/fairness"
 	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/store"
 	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/types"
 )
 
-// mockStore implements the Store interface for testing
+// mockStore implements the Store interface for testing. Like Redis it
+// stores copies, so handlers running concurrently (streams) never share a
+// session.
 type mockStore struct {
-	sessions map[string]*types.Session
+	mu       sync.Mutex
+	sessions map[string][]byte
+	entries  map[string][]byte
+	streaks  map[string]store.Streak
+	boards   map[string]map[string]int64 // Board/scope → player → best score
+	keys     map[string][]byte           // Idempotency records (never expire)
+	keyTTLs  map[string]time.Duration    // TTL each idempotency record was last stored with
 }
 
 func newMockStore() *mockStore {
 	return &mockStore{
-		sessions: make(map[string]*types.Session),
+		sessions: make(map[string][]byte),
+		entries:  make(map[string][]byte),
+		streaks:  make(map[string]store.Streak),
+		boards:   make(map[string]map[string]int64),
+		keys:     make(map[string][]byte),
+		keyTTLs:  make(map[string]time.Duration),
 	}
 }
 
 func (m *mockStore) CreateSession(ctx context.Context, session *types.Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if _, exists := m.sessions[session.ID]; exists {
 		return store.ErrSessionExists
 	}
-	m.sessions[session.ID] = session
+	m.sessions[session.ID], _ = json.Marshal(session)
 	return nil
 }
 
 func (m *mockStore) GetSession(ctx context.Context, id string) (*types.Session, error) {
-	session, exists := m.sessions[id]
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, exists := m.sessions[id]
 	if !exists {
 		return nil, store.ErrSessionNotFound
 	}
-	return session, nil
+	var session types.Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (m *mockStore) GetSessions(ctx context.Context, ids []string) ([]*types.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sessions := make([]*types.Session, len(ids))
+	for i, id := range ids {
+		data, exists := m.sessions[id]
+		if !exists {
+			continue
+		}
+		var session types.Session
+		if err := json.Unmarshal(data, &session); err != nil {
+			return nil, err
+		}
+		sessions[i] = &session
+	}
+	return sessions, nil
+}
+
+// ListSessions pages through every session newest first; its cursor is
+// the ID of the last session listed.
+func (m *mockStore) ListSessions(ctx context.Context, query store.SessionQuery) (*store.SessionPage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var sessions []*types.Session
+	for _, data := range m.sessions {
+		var session types.Session
+		if err := json.Unmarshal(data, &session); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, &session)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].CreatedAt.Equal(sessions[j].CreatedAt) {
+			return sessions[i].CreatedAt.After(sessions[j].CreatedAt)
+		}
+		return sessions[i].ID > sessions[j].ID
+	})
+
+	start := 0
+	if query.Cursor != "" {
+		start = -1
+		for i, session := range sessions {
+			if session.ID == query.Cursor {
+				start = i + 1
+			}
+		}
+		if start < 0 {
+			return nil, store.ErrInvalidCursor
+		}
+	}
+
+	page := &store.SessionPage{}
+	for _, session := range sessions[start:] {
+		if !query.Matches(session) {
+			continue
+		}
+		if len(page.Sessions) == query.Limit {
+			page.NextCursor = page.Sessions[len(page.Sessions)-1].ID
+			break
+		}
+		page.Sessions = append(page.Sessions, session)
+	}
+	return page, nil
+}
+
+func (m *mockStore) UpdateSession(ctx context.Context, session *types.Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, exists := m.sessions[session.ID]
+	if !exists {
+		return store.ErrSessionNotFound
+	}
+	var current types.Session
+	if err := json.Unmarshal(data, &current); err != nil {
+		return err
+	}
+	if current.Revision != session.Revision {
+		return store.ErrSessionConflict
+	}
+	session.Revision++
+	m.sessions[session.ID], _ = json.Marshal(session)
+	return nil
+}
+
+func (m *mockStore) DeleteSession(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+	return nil
+}
+
+func (m *mockStore) CreateEntry(ctx context.Context, entry *types.Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := mockEntryKey(entry.SessionID, entry.Track, entry.Round, entry.PlayerID)
+	if _, exists := m.entries[key]; exists {
+		return store.ErrEntryExists
+	}
+	m.entries[key], _ = json.Marshal(entry)
+	return nil
+}
+
+func (m *mockStore) GetEntry(ctx context.Context, sessionID string, track int, round int64, playerID string) (*types.Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, exists := m.entries[mockEntryKey(sessionID, track, round, playerID)]
+	if !exists {
+		return nil, store.ErrEntryNotFound
+	}
+	var entry types.Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (m *mockStore) DeleteEntry(ctx context.Context, sessionID string, track int, round int64, playerID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, mockEntryKey(sessionID, track, round, playerID))
+	return nil
+}
+
+func (m *mockStore) ListEntries(ctx context.Context, sessionID string, track int, round int64) ([]*types.Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prefix := mockEntryKey(sessionID, track, round, "")
+	entries := []*types.Entry{}
+	for key, data := range m.entries {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		var entry types.Entry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].PlayerID < entries[j].PlayerID })
+	return entries, nil
+}
+
+func (m *mockStore) SettleEntry(ctx context.Context, entry *types.Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := mockEntryKey(entry.SessionID, entry.Track, entry.Round, entry.PlayerID)
+	data, exists := m.entries[key]
+	if !exists {
+		return store.ErrEntryNotFound
+	}
+	var current types.Entry
+	if err := json.Unmarshal(data, &current); err != nil {
+		return err
+	}
+	if current.Settled() {
+		return store.ErrEntrySettled
+	}
+	m.entries[key], _ = json.Marshal(entry)
+	return nil
+}
+
+func (m *mockStore) RecordCashOut(ctx context.Context, entry *types.Entry, mode string, score int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := fmt.Sprintf("%s:%d:%s", entry.SessionID, entry.Track, entry.PlayerID)
+	m.streaks[key] = m.streaks[key].Extend(entry.Round)
+	for _, scope := range store.CashOutScopes(entry, mode) {
+		for board, score := range map[string]int64{types.BoardCashOut: score, types.BoardSurvival: m.streaks[key].Length} {
+			boardKey := mockBoardKey(board, scope)
+			if m.boards[boardKey] == nil {
+				m.boards[boardKey] = make(map[string]int64)
+			}
+			if best, exists := m.boards[boardKey][entry.PlayerID]; !exists || score > best {
+				m.boards[boardKey][entry.PlayerID] = score
+			}
+		}
+	}
+	return nil
+}
+
+func (m *mockStore) Leaderboard(ctx context.Context, board string, scope store.LeaderboardScope, limit int) ([]types.LeaderboardEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entries := []types.LeaderboardEntry{}
+	for playerID, score := range m.boards[mockBoardKey(board, scope)] {
+		entries = append(entries, types.LeaderboardEntry{PlayerID: playerID, Score: score})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Score != entries[j].Score {
+			return entries[i].Score > entries[j].Score
+		}
+		return entries[i].PlayerID > entries[j].PlayerID // Like ZREVRANGE
+	})
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	for i := range entries {
+		entries[i].Rank = i + 1
+	}
+	return entries, nil
+}
+
+func (m *mockStore) ReserveIdempotencyKey(ctx context.Context, record *types.IdempotencyRecord, lease time.Duration) (*types.IdempotencyRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if data, exists := m.keys[record.Key]; exists {
+		var existing types.IdempotencyRecord
+		if err := json.Unmarshal(data, &existing); err != nil {
+			return nil, err
+		}
+		return &existing, store.ErrIdempotencyKeyExists
+	}
+	m.keys[record.Key], _ = json.Marshal(record)
+	m.keyTTLs[record.Key] = lease
+	return record, nil
+}
+
+func (m *mockStore) CompleteIdempotencyKey(ctx context.Context, record *types.IdempotencyRecord, window time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.keys[record.Key]; exists {
+		m.keys[record.Key], _ = json.Marshal(record)
+		m.keyTTLs[record.Key] = window
+	}
+	return nil
+}
+
+func (m *mockStore) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.keys, key)
+	return nil
+}
+
+func mockBoardKey(board string, scope store.LeaderboardScope) string {
+	return fmt.Sprintf("%s:%s:%s:%s:%s", board, scope.Scope, scope.SessionID, scope.Day, scope.Mode)
+}
+
+func mockEntryKey(sessionID string, track int, round int64, playerID string) string {
+	return fmt.Sprintf("%s:%d:%d:%s", sessionID, track, round, playerID)
+}
+
+func TestHandler_CreateSession(t *testing.T) {
+	handler := NewHandler(newMockStore())
+
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		expectedStatus int
+		validate       func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name: "valid request",
+			requestBody: types.CreateSessionRequest{
+				TickMs: 100,
+			},
+			expectedStatus: http.StatusCreated,
+			validate: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var resp types.CreateSessionResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				if resp.ID == "" {
+					t.Error("Expected session ID, got empty")
+				}
+				if resp.SeedHash == "" {
+					t.Error("Expected seed hash, got empty string")
+				}
+				if resp.TickMs != 100 {
+					t.Errorf("Expected tickMs 100, got %d", resp.TickMs)
+				}
+				if resp.Mode != "counter" {
+					t.Errorf("Expected default mode counter, got %s", resp.Mode)
+				}
+				if resp.EngineVersion != engine.LatestVersion {
+					t.Errorf("Expected engine version %d, got %d", engine.LatestVersion, resp.EngineVersion)
+				}
+			},
+		},
+		{
+			name: "crash mode",
+			requestBody: types.CreateSessionRequest{
+				TickMs:     100,
+				Mode:       "crash",
+				ModeParams: map[string]float64{"house_edge": 0.02},
+			},
+			expectedStatus: http.StatusCreated,
+			validate: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var resp types.CreateSessionResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				if resp.Mode != "crash" {
+					t.Errorf("Expected mode crash, got %s", resp.Mode)
+				}
+				if resp.ModeParams["house_edge"] != 0.02 {
+					t.Errorf("Expected house_edge 0.02, got %v", resp.ModeParams["house_edge"])
+				}
+			},
+		},
+		{
+			name: "crash mode with invalid house edge",
+			requestBody: types.CreateSessionRequest{
+				TickMs:     100,
+				Mode:       "crash",
+				ModeParams: map[string]float64{"house_edge": 1.5},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "geometric interval",
+			requestBody: types.CreateSessionRequest{
+				TickMs: 100,
+				Interval: &types.IntervalConfig{
+					Min:          20,
+					Max:          400,
+					Distribution: "geometric",
+					P:            0.01,
+				},
+			},
+			expectedStatus: http.StatusCreated,
+			validate: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var resp types.CreateSessionResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				if resp.Interval == nil || resp.Interval.Distribution != "geometric" || resp.Interval.Min != 20 {
+					t.Errorf("Expected geometric interval from 20, got %+v", resp.Interval)
+				}
+			},
+		},
+		{
+			name: "invalid interval range",
+			requestBody: types.CreateSessionRequest{
+				TickMs:   100,
+				Interval: &types.IntervalConfig{Min: 300, Max: 100},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "interval mean too short",
+			requestBody: types.CreateSessionRequest{
+				TickMs:   100,
+				Interval: &types.IntervalConfig{Min: 1, Max: 1000, Distribution: "geometric", P: 0.99},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "interval with crash mode",
+			requestBody: types.CreateSessionRequest{
+				TickMs:   100,
+				Mode:     "crash",
+				Interval: &types.IntervalConfig{Min: 10, Max: 20},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "tick schedule",
+			requestBody: types.CreateSessionRequest{
+				TickSchedule: &types.TickSchedule{
+					Segments: []types.TickSegment{{DurationMs: 300000, TickMs: 100}, {TickMs: 50}},
+				},
+			},
+			expectedStatus: http.StatusCreated,
+			validate: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var resp types.CreateSessionResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				if resp.TickMs != 100 {
+					t.Errorf("Expected tickMs 100 from the first segment, got %d", resp.TickMs)
+				}
+				if resp.TickSchedule == nil || len(resp.TickSchedule.Segments) != 2 {
+					t.Errorf("Expected 2 tick segments, got %+v", resp.TickSchedule)
+				}
+			},
+		},
+		{
+			name: "tick_ms differs from tick schedule",
+			requestBody: types.CreateSessionRequest{
+				TickMs:       200,
+				TickSchedule: &types.TickSchedule{RoundTicks: []int64{100, 90}},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "invalid tick schedule",
+			requestBody: types.CreateSessionRequest{
+				TickSchedule: &types.TickSchedule{
+					Segments: []types.TickSegment{{DurationMs: 250, TickMs: 100}, {TickMs: 50}},
+				},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "multiple tracks",
+			requestBody: types.CreateSessionRequest{
+				TickMs: 100,
+				Tracks: 3,
+			},
+			expectedStatus: http.StatusCreated,
+			validate: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var resp types.CreateSessionResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				if resp.Tracks != 3 {
+					t.Errorf("Expected 3 tracks, got %d", resp.Tracks)
+				}
+			},
+		},
+		{
+			name: "too many tracks",
+			requestBody: types.CreateSessionRequest{
+				TickMs: 100,
+				Tracks: 17,
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "too many metadata labels",
+			requestBody: types.CreateSessionRequest{
+				TickMs:   100,
+				Metadata: json.RawMessage(`{"a":"1","b":"2","c":"3","d":"4","e":"5","f":"6","g":"7","h":"8","i":"9","j":"10","k":"11","l":"12","m":"13","n":"14","o":"15","p":"16","q":"17"}`),
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "long metadata strings are not labels",
+			requestBody: types.CreateSessionRequest{
+				TickMs:   100,
+				Metadata: json.RawMessage(`{"region":"eu","notes":"` + strings.Repeat("x", 1000) + `","seats":6}`),
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "lobby and intermission",
+			requestBody: types.CreateSessionRequest{
+				TickMs:            100,
+				LobbyTicks:        50,
+				IntermissionTicks: 30,
+			},
+			expectedStatus: http.StatusCreated,
+			validate: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var resp types.CreateSessionResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				if resp.LobbyTicks != 50 || resp.IntermissionTicks != 30 {
+					t.Errorf("Expected 50 lobby and 30 intermission ticks, got %d and %d", resp.LobbyTicks, resp.IntermissionTicks)
+				}
+			},
+		},
+		{
+			name: "negative intermission",
+			requestBody: types.CreateSessionRequest{
+				TickMs:            100,
+				IntermissionTicks: -1,
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "end conditions",
+			requestBody: types.CreateSessionRequest{
+				TickMs: 100,
+				End:    &types.EndCondition{MaxRounds: 10, MaxDurationMs: 600000},
+			},
+			expectedStatus: http.StatusCreated,
+			validate: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var resp types.CreateSessionResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				if resp.End == nil || resp.End.MaxRounds != 10 {
+					t.Errorf("Expected end after 10 rounds, got %+v", resp.End)
+				}
+			},
+		},
+		{
+			name: "empty end",
+			requestBody: types.CreateSessionRequest{
+				TickMs: 100,
+				End:    &types.EndCondition{},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "pinned engine version",
+			requestBody: types.CreateSessionRequest{
+				TickMs:        100,
+				EngineVersion: 1,
+			},
+			expectedStatus: http.StatusCreated,
+			validate: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var resp types.CreateSessionResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				if resp.EngineVersion != 1 {
+					t.Errorf("Expected engine version 1, got %d", resp.EngineVersion)
+				}
+			},
+		},
+		{
+			name: "unknown engine version",
+			requestBody: types.CreateSessionRequest{
+				TickMs:        100,
+				EngineVersion: 99,
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "unknown mode",
+			requestBody: types.CreateSessionRequest{
+				TickMs: 100,
+				Mode:   "roulette",
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "invalid tickMs (zero)",
+			requestBody: types.CreateSessionRequest{
+				TickMs: 0,
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "invalid tickMs (negative)",
+			requestBody: types.CreateSessionRequest{
+				TickMs: -10,
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid JSON",
+			requestBody:    "not json",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body []byte
+			var err error
+
+			if str, ok := tt.requestBody.(string); ok {
+				body = []byte(str)
+			} else {
+				body, err = json.Marshal(tt.requestBody)
+				if err != nil {
+					t.Fatalf("Failed to marshal request: %v", err)
+				}
+			}
+
+			req := httptest.NewRequest("POST", "/v1/sessions", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router := chi.NewRouter()
+			router.Mount("/", handler.Routes())
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+
+			if tt.validate != nil {
+				tt.validate(t, w)
+			}
+		})
+	}
+}
+
+func TestHandler_CreateSessionExplicitSeed(t *testing.T) {
+	store := newMockStore()
+	handler := NewHandler(store, WithAdminToken("secret"))
+
+	tests := []struct {
+		name           string
+		adminToken     string
+		requestBody    types.CreateSessionRequest
+		expectedStatus int
+	}{
+		{
+			name:           "admin with numeric seed",
+			adminToken:     "secret",
+			requestBody:    types.CreateSessionRequest{TickMs: 100, Seed: "12345"},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "admin with uint64 seed",
+			adminToken:     "secret",
+			requestBody:    types.CreateSessionRequest{TickMs: 100, Seed: "18446744073709551615"},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "uint64 seed on engine version 1",
+			adminToken:     "secret",
+			requestBody:    types.CreateSessionRequest{TickMs: 100, Seed: "18446744073709551615", EngineVersion: 1},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "admin with non-numeric seed",
+			adminToken:     "secret",
+			requestBody:    types.CreateSessionRequest{TickMs: 100, Seed: "my-seed"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "without admin token",
+			requestBody:    types.CreateSessionRequest{TickMs: 100, Seed: "12345"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "wrong admin token",
+			adminToken:     "guess",
+			requestBody:    types.CreateSessionRequest{TickMs: 100, Seed: "12345"},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := json.Marshal(tt.requestBody)
+			if err != nil {
+				t.Fatalf("Failed to marshal request: %v", err)
+			}
+
+			req := httptest.NewRequest("POST", "/v1/sessions", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.adminToken != "" {
+				req.Header.Set("X-Admin-Token", tt.adminToken)
+			}
+			w := httptest.NewRecorder()
+
+			router := chi.NewRouter()
+			router.Mount("/", handler.Routes())
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+
+			if w.Code == http.StatusCreated {
+				var resp types.CreateSessionResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				stored, _ := store.GetSession(context.Background(), resp.ID)
+				if got := stored.Seed; got != tt.requestBody.Seed {
+					t.Errorf("Expected stored seed %s, got %s", tt.requestBody.Seed, got)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_CreateSessionTracks(t *testing.T) {
+	handler := NewHandler(newMockStore())
+	router := chi.NewRouter()
+	router.Mount("/", handler.Routes())
+
+	tests := []struct {
+		tracks         int
+		expectedStatus int
+		expectedTracks int
+	}{
+		{tracks: -1, expectedStatus: http.StatusBadRequest},
+		{tracks: 0, expectedStatus: http.StatusCreated, expectedTracks: 1}, // Same as omitted
+		{tracks: 1, expectedStatus: http.StatusCreated, expectedTracks: 1},
+		{tracks: 16, expectedStatus: http.StatusCreated, expectedTracks: 16},
+		{tracks: 17, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d tracks", tt.tracks), func(t *testing.T) {
+			body, _ := json.Marshal(types.CreateSessionRequest{TickMs: 100, Tracks: tt.tracks})
+			req := httptest.NewRequest("POST", "/v1/sessions", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedStatus != http.StatusCreated {
+				return
+			}
+			var resp types.CreateSessionResponse
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if resp.Tracks != tt.expectedTracks {
+				t.Errorf("Expected %d tracks, got %d", tt.expectedTracks, resp.Tracks)
+			}
+		})
+	}
+}
+
+func TestHandler_GetSession(t *testing.T) {
+	store := newMockStore()
+	handler := NewHandler(store)
+
+	// Create a test session
+	session := &types.Session{
+		ID:        "test-session-123",
+		Seed:      "test-seed-987654321",
+		StartAt:   time.Now().Add(3 * time.Second),
+		TickMs:    100,
+		Status:    "running",
+		CreatedAt: time.Now(),
+	}
+	store.CreateSession(context.Background(), session)
+
+	tests := []struct {
+		name           string
+		sessionID      string
+		expectedStatus int
+		validate       func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name:           "existing session",
+			sessionID:      "test-session-123",
+			expectedStatus: http.StatusOK,
+			validate: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var resp types.GetSessionResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				if resp.ID != "test-session-123" {
+					t.Errorf("Expected ID test-session-123, got %s", resp.ID)
+				}
+				if resp.Seed != "" {
+					t.Errorf("Expected seed to stay secret while running, got %s", resp.Seed)
+				}
+				if resp.SeedHash != fairness.Commit("test-seed-987654321") {
+					t.Errorf("Expected seed hash of test-seed-987654321, got %s", resp.SeedHash)
+				}
+			},
+		},
+		{
+			name:           "non-existent session",
+			sessionID:      "non-existent",
+			expectedStatus: http.StatusNotFound,
+		},
+		// Note: Empty session ID results in 404 from chi router
+		// This is acceptable behavior - empty IDs are invalid anyway
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := "/v1/sessions/" + tt.sessionID
+			req := httptest.NewRequest("GET", url, nil)
+			w := httptest.NewRecorder()
+
+			router := chi.NewRouter()
+			router.Mount("/", handler.Routes())
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+
+			if tt.validate != nil {
+				tt.validate(t, w)
+			}
+		})
+	}
+}
+
+func TestHandler_ListSessions(t *testing.T) {
+	store := newMockStore()
+	handler := NewHandler(store)
+
+	// Five sessions created a minute apart, newest last
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	sessions := []struct {
+		id       string
+		status   string
+		metadata string
+	}{
+		{id: "sess-a", status: "running", metadata: `{"region":"eu","tier":"vip"}`},
+		{id: "sess-b", status: "stopped", metadata: `{"region":"us"}`},
+		{id: "sess-c", status: "running", metadata: `{"region":"eu"}`},
+		{id: "sess-d", status: "running", metadata: `{"region":"eu","tier":"vip"}`},
+		{id: "sess-e", status: "running"},
+	}
+	for i, s := range sessions {
+		session := &types.Session{
+			ID:        s.id,
+			Seed:      "12345",
+			StartAt:   base,
+			TickMs:    100,
+			Status:    s.status,
+			CreatedAt: base.Add(time.Duration(i) * time.Minute),
+		}
+		if s.metadata != "" {
+			session.Metadata = json.RawMessage(s.metadata)
+		}
+		store.CreateSession(context.Background(), session)
+	}
+
+	list := func(t *testing.T, query string) (int, types.ListSessionsResponse) {
+		req := httptest.NewRequest("GET", "/v1/sessions?"+query, nil)
+		w := httptest.NewRecorder()
+		router := chi.NewRouter()
+		router.Mount("/", handler.Routes())
+		router.ServeHTTP(w, req)
+
+		var resp types.ListSessionsResponse
+		if w.Code == http.StatusOK {
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+		}
+		return w.Code, resp
+	}
+	ids := func(resp types.ListSessionsResponse) string {
+		var ids []string
+		for _, session := range resp.Sessions {
+			ids = append(ids, session.ID)
+		}
+		return strings.Join(ids, ",")
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedIDs    string
+	}{
+		{name: "all", query: "", expectedStatus: http.StatusOK, expectedIDs: "sess-e,sess-d,sess-c,sess-b,sess-a"},
+		{name: "status", query: "status=stopped", expectedStatus: http.StatusOK, expectedIDs: "sess-b"},
+		{name: "created range", query: "created_after=2024-01-15T10:01:00Z&created_before=2024-01-15T10:03:00Z", expectedStatus: http.StatusOK, expectedIDs: "sess-c,sess-b"},
+		{name: "label", query: "label=region=eu", expectedStatus: http.StatusOK, expectedIDs: "sess-d,sess-c,sess-a"},
+		{name: "labels and status", query: "label=region=eu&label=tier=vip&status=running", expectedStatus: http.StatusOK, expectedIDs: "sess-d,sess-a"},
+		{name: "no match", query: "label=region=apac", expectedStatus: http.StatusOK, expectedIDs: ""},
+		{name: "invalid status", query: "status=ended", expectedStatus: http.StatusBadRequest},
+		{name: "invalid created_after", query: "created_after=yesterday", expectedStatus: http.StatusBadRequest},
+		{name: "invalid label", query: "label=region", expectedStatus: http.StatusBadRequest},
+		{name: "invalid limit", query: "limit=101", expectedStatus: http.StatusBadRequest},
+		{name: "invalid cursor", query: "cursor=unknown", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := list(t, tt.query)
+			if status != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d", tt.expectedStatus, status)
+			}
+			if status == http.StatusOK && ids(resp) != tt.expectedIDs {
+				t.Errorf("Expected sessions %q, got %q", tt.expectedIDs, ids(resp))
+			}
+		})
+	}
+
+	t.Run("pagination", func(t *testing.T) {
+		var pages []string
+		query := "limit=2&label=region=eu"
+		for {
+			status, resp := list(t, query)
+			if status != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", status)
+			}
+			pages = append(pages, ids(resp))
+			if resp.NextCursor == "" {
+				break
+			}
+			query = "limit=2&label=region=eu&cursor=" + resp.NextCursor
+		}
+		if got := strings.Join(pages, "|"); got != "sess-d,sess-c|sess-a" {
+			t.Errorf("Expected pages sess-d,sess-c|sess-a, got %s", got)
+		}
+	})
+}
+
+func TestHandler_ListSessionsPhase(t *testing.T) {
+	store := newMockStore()
+	handler := NewHandler(store)
+	router := chi.NewRouter()
+	router.Mount("/", handler.Routes())
+
+	// All stored as running: one expired and one finished since, one not
+	// started yet
+	base := time.Now().Add(-time.Hour)
+	expiresAt := base.Add(time.Minute)
+	for i, session := range []*types.Session{
+		{ID: "sess-running", StartAt: base},
+		{ID: "sess-expired", StartAt: base, ExpiresAt: &expiresAt},
+		{ID: "sess-finished", StartAt: base, End: &types.EndCondition{StopAtStep: 10}},
+		{ID: "sess-scheduled", StartAt: time.Now().Add(time.Hour)},
+	} {
+		session.Seed = "12345"
+		session.TickMs = 100
+		session.Status = "running"
+		session.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		store.CreateSession(context.Background(), session)
+	}
+
+	for status, expected := range map[string]string{
+		"running":   "sess-running",
+		"expired":   "sess-expired",
+		"finished":  "sess-finished",
+		"scheduled": "sess-scheduled",
+	} {
+		t.Run(status, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/v1/sessions?status="+status, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var resp types.ListSessionsResponse
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if len(resp.Sessions) != 1 || resp.Sessions[0].ID != expected {
+				t.Fatalf("Expected %s, got %+v", expected, resp.Sessions)
+			}
+			if phase := resp.Sessions[0].Phase; phase != status {
+				t.Errorf("Expected phase %s, got %s", status, phase)
+			}
+		})
+	}
+
+	// Listed sessions that ended were settled
+	for id, status := range map[string]string{"sess-expired": "expired", "sess-finished": "finished"} {
+		if session, _ := store.GetSession(context.Background(), id); session.Status != status {
+			t.Errorf("Expected %s stored as %s, got %s", id, status, session.Status)
+		}
+	}
+}
+func TestHandler_StopSession(t *testing.T) {
+	store := newMockStore()
+	handler := NewHandler(store)
+
+	// Create a test session
+	session := &types.Session{
+		ID:        "test-session-456",
+		Seed:      "test-seed-123456789",
+		StartAt:   time.Now().Add(3 * time.Second),
+		TickMs:    100,
+		Status:    "running",
+		CreatedAt: time.Now(),
+	}
+	store.CreateSession(context.Background(), session)
+
+	tests := []struct {
+		name           string
+		sessionID      string
+		expectedStatus int
+		validate       func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name:           "stop active session",
+			sessionID:      "test-session-456",
+			expectedStatus: http.StatusOK,
+			validate: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var resp types.StopSessionResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				if resp.ID != "test-session-456" {
+					t.Errorf("Expected ID test-session-456, got %s", resp.ID)
+				}
+				if resp.Status != "stopped" {
+					t.Errorf("Expected status stopped, got %s", resp.Status)
+				}
+				if resp.Seed != "test-seed-123456789" {
+					t.Errorf("Expected revealed seed test-seed-123456789, got %s", resp.Seed)
+				}
+			},
+		},
+		{
+			name:           "stop non-existent session",
+			sessionID:      "non-existent",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "stop already stopped session",
+			sessionID:      "test-session-456", // Will be stopped by previous test
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := "/v1/sessions/" + tt.sessionID + "/stop"
+			req := httptest.NewRequest("POST", url, nil)
+			w := httptest.NewRecorder()
+
+			router := chi.NewRouter()
+			router.Mount("/", handler.Routes())
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+
+			if tt.validate != nil {
+				tt.validate(t, w)
+			}
+		})
+	}
+}
+
+func TestHandler_PauseResumeSession(t *testing.T) {
+	store := newMockStore()
+	handler := NewHandler(store, WithAdminToken("secret"))
+
+	session := &types.Session{
+		ID:        "test-session-pause",
+		Seed:      "12345",
+		StartAt:   time.Now().Add(-10 * time.Second),
+		TickMs:    100,
+		Status:    "running",
+		CreatedAt: time.Now(),
+	}
+	store.CreateSession(context.Background(), session)
+
+	scheduled := &types.Session{
+		ID:        "test-session-scheduled",
+		Seed:      "12345",
+		StartAt:   time.Now().Add(time.Minute),
+		TickMs:    100,
+		Status:    "running",
+		CreatedAt: time.Now(),
+	}
+	store.CreateSession(context.Background(), scheduled)
+
+	// Only admins pause and resume sessions
+	for _, action := range []string{"pause", "resume"} {
+		for _, token := range []string{"", "wrong"} {
+			req := httptest.NewRequest("POST", "/v1/sessions/test-session-pause/"+action, nil)
+			if token != "" {
+				req.Header.Set("X-Admin-Token", token)
+			}
+			w := httptest.NewRecorder()
+			router := chi.NewRouter()
+			router.Mount("/", handler.Routes())
+			router.ServeHTTP(w, req)
+			if w.Code != http.StatusForbidden {
+				t.Errorf("%s with token %q: expected status 403, got %d", action, token, w.Code)
+			}
+		}
+	}
+	if current, _ := store.GetSession(context.Background(), "test-session-pause"); current.Status != "running" || len(current.Pauses) != 0 {
+		t.Fatalf("Expected the session untouched, got %s with %d pauses", current.Status, len(current.Pauses))
+	}
+
+	// Steps run in order against the same session
+	tests := []struct {
+		name           string
+		sessionID      string
+		action         string
+		expectedStatus int
+		wantStatus     string
+	}{
+		{name: "resume running session", sessionID: "test-session-pause", action: "resume", expectedStatus: http.StatusConflict},
+		{name: "pause running session", sessionID: "test-session-pause", action: "pause", expectedStatus: http.StatusOK, wantStatus: "paused"},
+		{name: "pause paused session", sessionID: "test-session-pause", action: "pause", expectedStatus: http.StatusConflict},
+		{name: "resume paused session", sessionID: "test-session-pause", action: "resume", expectedStatus: http.StatusOK, wantStatus: "running"},
+		{name: "pause again", sessionID: "test-session-pause", action: "pause", expectedStatus: http.StatusOK, wantStatus: "paused"},
+		{name: "pause before start", sessionID: "test-session-scheduled", action: "pause", expectedStatus: http.StatusConflict},
+		{name: "pause non-existent session", sessionID: "non-existent", action: "pause", expectedStatus: http.StatusNotFound},
+		{name: "resume non-existent session", sessionID: "non-existent", action: "resume", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := "/v1/sessions/" + tt.sessionID + "/" + tt.action
+			req := httptest.NewRequest("POST", url, nil)
+			req.Header.Set("X-Admin-Token", "secret")
+			w := httptest.NewRecorder()
+
+			router := chi.NewRouter()
+			router.Mount("/", handler.Routes())
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus == "" {
+				return
+			}
+
+			var resp types.PauseSessionResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if resp.Status != tt.wantStatus {
+				t.Errorf("Expected status %s, got %s", tt.wantStatus, resp.Status)
+			}
+			if resp.Step < 100 {
+				t.Errorf("Expected step >= 100, got %d", resp.Step)
+			}
+		})
+	}
+
+	// Stopping a paused session closes its pause window
+	req := httptest.NewRequest("POST", "/v1/sessions/test-session-pause/stop", nil)
+	w := httptest.NewRecorder()
+	router := chi.NewRouter()
+	router.Mount("/", handler.Routes())
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	stopped, _ := store.GetSession(context.Background(), "test-session-pause")
+	if len(stopped.Pauses) != 2 {
+		t.Fatalf("Expected 2 pause windows, got %d", len(stopped.Pauses))
+	}
+	for i, p := range stopped.Pauses {
+		if p.ResumedAt == nil {
+			t.Errorf("Pause window %d still open after stop", i)
+		}
+	}
+}
+
+// racingStore updates each session behind the handler's back right after
+// first handing it out, like a concurrent request would.
+type racingStore struct {
+	*mockStore
+	raced map[string]bool
+}
+
+func (s *racingStore) GetSession(ctx context.Context, id string) (*types.Session, error) {
+	session, err := s.mockStore.GetSession(ctx, id)
+	if err == nil && !s.raced[id] {
+		s.raced[id] = true
+		concurrent := *session
+		s.mockStore.UpdateSession(ctx, &concurrent)
+	}
+	return session, err
+}
+
+func TestHandler_SessionUpdateConflict(t *testing.T) {
+	mock := newMockStore()
+	handler := NewHandler(&racingStore{mockStore: mock, raced: make(map[string]bool)}, WithAdminToken("secret"))
+	router := chi.NewRouter()
+	router.Mount("/", handler.Routes())
+
+	expiresAt := time.Now().Add(-time.Second)
+	for _, session := range []*types.Session{
+		{ID: "test-session-race-pause"},
+		{ID: "test-session-race-stop"},
+		{ID: "test-session-race-expired", ExpiresAt: &expiresAt},
+	} {
+		session.Seed = "12345"
+		session.StartAt = time.Now().Add(-10 * time.Second)
+		session.TickMs = 100
+		session.Status = "running"
+		session.CreatedAt = time.Now()
+		mock.CreateSession(context.Background(), session)
+	}
+
+	post := func(path string) int {
+		req := httptest.NewRequest("POST", path, nil)
+		req.Header.Set("X-Admin-Token", "secret")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// An update from a stale revision is refused, not stored over the
+	// concurrent one; a retry from the new revision goes through
+	for _, path := range []string{"/v1/sessions/test-session-race-pause/pause", "/v1/sessions/test-session-race-stop/stop"} {
+		if code := post(path); code != http.StatusConflict {
+			t.Errorf("%s: expected status 409, got %d", path, code)
+		}
+		if code := post(path); code != http.StatusOK {
+			t.Errorf("%s: expected status 200 on retry, got %d", path, code)
+		}
+	}
+
+	// Settling an expired session reloads it instead of failing
+	req := httptest.NewRequest("GET", "/v1/sessions/test-session-race-expired", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if session, _ := mock.GetSession(context.Background(), "test-session-race-expired"); session.Status != types.PhaseExpired {
+		t.Errorf("Expected the session stored as expired, got %s", session.Status)
+	}
 }
 
-func (m *mockStore) UpdateSession(ctx context.Context, session *types.Session) error {
-	if _, exists := m.sessions[session.ID]; !exists {
-		return store.ErrSessionNotFound
+func TestHandler_GetSessionStatePaused(t *testing.T) {
+	store := newMockStore()
+	handler := NewHandler(store)
+
+	// Started 60s ago, paused for the last 50s: only 10s (100 steps) are active
+	now := time.Now()
+	session := &types.Session{
+		ID:        "test-session-paused",
+		Seed:      "12345",
+		StartAt:   now.Add(-60 * time.Second),
+		TickMs:    100,
+		Status:    "paused",
+		Pauses:    []types.PauseWindow{{PausedAt: now.Add(-50 * time.Second)}},
+		CreatedAt: now,
+	}
+	store.CreateSession(context.Background(), session)
+
+	req := httptest.NewRequest("GET", "/v1/sessions/test-session-paused/state", nil)
+	w := httptest.NewRecorder()
+	router := chi.NewRouter()
+	router.Mount("/", handler.Routes())
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var resp types.SessionStateResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if resp.Step != 100 {
+		t.Errorf("Expected step 100 frozen at pause, got %d", resp.Step)
+	}
+	if resp.Status != "paused" {
+		t.Errorf("Expected status paused, got %s", resp.Status)
 	}
-	m.sessions[session.ID] = session
-	return nil
 }
 
-func (m *mockStore) DeleteSession(ctx context.Context, id string) error {
-	delete(m.sessions, id)
-	return nil
+func TestHandler_GetSessionStateQuery(t *testing.T) {
+	store := newMockStore()
+	handler := NewHandler(store, WithAdminToken("secret"))
+
+	// Started 60s ago with a 10s pause from 30s to 40s: 50s (500 steps) are active
+	now := time.Now().Truncate(time.Second)
+	startAt := now.Add(-60 * time.Second)
+	resumedAt := startAt.Add(40 * time.Second)
+	store.CreateSession(context.Background(), &types.Session{
+		ID:        "test-session-query",
+		Seed:      "12345",
+		StartAt:   startAt,
+		TickMs:    100,
+		Status:    "running",
+		Pauses:    []types.PauseWindow{{PausedAt: startAt.Add(30 * time.Second), ResumedAt: &resumedAt}},
+		CreatedAt: startAt,
+	})
+	stoppedAt := startAt.Add(10 * time.Second)
+	store.CreateSession(context.Background(), &types.Session{
+		ID:        "test-session-query-stopped",
+		Seed:      "12345",
+		StartAt:   startAt,
+		TickMs:    100,
+		Status:    "stopped",
+		StoppedAt: &stoppedAt,
+		CreatedAt: startAt,
+	})
+
+	at := func(d time.Duration) string { return "at=" + startAt.Add(d).UTC().Format(time.RFC3339) }
+
+	tests := []struct {
+		name           string
+		sessionID      string
+		query          string
+		adminToken     string
+		expectedStatus int
+		expectedStep   int64
+		expectedPhase  string
+	}{
+		{name: "at a past time", query: at(10 * time.Second), expectedStatus: http.StatusOK, expectedStep: 100, expectedPhase: "running"},
+		{name: "at during a pause", query: at(35 * time.Second), expectedStatus: http.StatusOK, expectedStep: 300, expectedPhase: "paused"},
+		{name: "at after a pause", query: at(50 * time.Second), expectedStatus: http.StatusOK, expectedStep: 400, expectedPhase: "running"},
+		{name: "at before start", query: at(-time.Second), expectedStatus: http.StatusOK, expectedStep: 0, expectedPhase: "scheduled"},
+		{name: "at a future time", query: at(2 * time.Minute), expectedStatus: http.StatusForbidden},
+		{name: "admin at a future time", query: at(2 * time.Minute), adminToken: "secret", expectedStatus: http.StatusOK, expectedStep: 1100, expectedPhase: "running"},
+		{name: "past step", query: "step=50", expectedStatus: http.StatusOK, expectedStep: 50, expectedPhase: "running"},
+		{name: "future step", query: "step=100000", expectedStatus: http.StatusForbidden},
+		{name: "admin future step", query: "step=100000", adminToken: "secret", expectedStatus: http.StatusOK, expectedStep: 100000, expectedPhase: "running"},
+		{name: "ended session after the end", sessionID: "test-session-query-stopped", query: at(2 * time.Minute), expectedStatus: http.StatusOK, expectedStep: 100, expectedPhase: "stopped"},
+		{name: "ended session past step", sessionID: "test-session-query-stopped", query: "step=50", expectedStatus: http.StatusOK, expectedStep: 50, expectedPhase: "stopped"},
+		{name: "ended session after the last step", sessionID: "test-session-query-stopped", query: "step=5000", expectedStatus: http.StatusBadRequest},
+		{name: "ended session huge step", sessionID: "test-session-query-stopped", query: "step=1099511627776", expectedStatus: http.StatusBadRequest},
+		{name: "admin step beyond the lookahead", query: "step=1000000", adminToken: "secret", expectedStatus: http.StatusBadRequest},
+		{name: "admin at beyond the lookahead", query: at(25 * time.Hour), adminToken: "secret", expectedStatus: http.StatusBadRequest},
+		{name: "invalid at", query: "at=yesterday", expectedStatus: http.StatusBadRequest},
+		{name: "negative step", query: "step=-1", expectedStatus: http.StatusBadRequest},
+		{name: "at and step", query: at(10*time.Second) + "&step=50", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessionID := tt.sessionID
+			if sessionID == "" {
+				sessionID = "test-session-query"
+			}
+			req := httptest.NewRequest("GET", "/v1/sessions/"+sessionID+"/state?"+tt.query, nil)
+			if tt.adminToken != "" {
+				req.Header.Set("X-Admin-Token", tt.adminToken)
+			}
+			w := httptest.NewRecorder()
+
+			router := chi.NewRouter()
+			router.Mount("/", handler.Routes())
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			var resp types.SessionStateResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if resp.Step != tt.expectedStep {
+				t.Errorf("Expected step %d, got %d", tt.expectedStep, resp.Step)
+			}
+			if resp.Phase != tt.expectedPhase {
+				t.Errorf("Expected phase %s, got %s", tt.expectedPhase, resp.Phase)
+			}
+			if strings.HasPrefix(tt.query, "at=") && resp.At == nil {
+				t.Error("Expected the queried time in the response")
+			}
+		})
+	}
 }
 
-func TestHandler_CreateSession(t *testing.T) {
-	handler := NewHandler(newMockStore())
+func TestHandler_GetSessionStates(t *testing.T) {
+	store := newMockStore()
+	handler := NewHandler(store)
+
+	now := time.Now()
+	stoppedAt := now.Add(-5 * time.Second)
+	store.CreateSession(context.Background(), &types.Session{
+		ID:        "test-batch-running",
+		Seed:      "12345",
+		StartAt:   now.Add(-10 * time.Second),
+		TickMs:    100,
+		Status:    "running",
+		CreatedAt: now,
+	})
+	store.CreateSession(context.Background(), &types.Session{
+		ID:        "test-batch-stopped",
+		Seed:      "12345",
+		StartAt:   now.Add(-10 * time.Second),
+		TickMs:    100,
+		Status:    "stopped",
+		StoppedAt: &stoppedAt,
+		CreatedAt: now,
+	})
+
+	tooMany := make([]string, maxBatchSessions+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("sess-%d", i)
+	}
 
 	tests := []struct {
 		name           string
 		requestBody    interface{}
 		expectedStatus int
-		validate       func(*testing.T, *httptest.ResponseRecorder)
+		validate       func(*testing.T, types.BatchStateResponse)
 	}{
 		{
-			name: "valid request",
-			requestBody: types.CreateSessionRequest{
-				TickMs: 100,
-			},
-			expectedStatus: http.StatusCreated,
-			validate: func(t *testing.T, w *httptest.ResponseRecorder) {
-				var resp types.CreateSessionResponse
-				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
-					t.Fatalf("Failed to unmarshal response: %v", err)
+			name:           "mixed sessions",
+			requestBody:    types.BatchStateRequest{IDs: []string{"test-batch-running", "non-existent", "test-batch-stopped"}},
+			expectedStatus: http.StatusOK,
+			validate: func(t *testing.T, resp types.BatchStateResponse) {
+				if len(resp.States) != 3 {
+					t.Fatalf("Expected 3 states, got %d", len(resp.States))
 				}
-				if resp.ID == "" {
-					t.Error("Expected session ID, got empty")
+				running, missing, stopped := resp.States[0], resp.States[1], resp.States[2]
+				if running.ID != "test-batch-running" || running.State == nil || running.State.Step < 100 || running.Error != nil {
+					t.Errorf("Expected the running state, got %+v", running)
 				}
-				if resp.Seed == "" {
-					t.Error("Expected seed, got empty string")
+				if missing.ID != "non-existent" || missing.State != nil || missing.Error == nil || missing.Error.Error != "session not found" {
+					t.Errorf("Expected session not found, got %+v", missing)
 				}
-				if resp.TickMs != 100 {
-					t.Errorf("Expected tickMs 100, got %d", resp.TickMs)
+				if stopped.State == nil || stopped.State.Step != 50 || stopped.State.Status != "stopped" {
+					t.Errorf("Expected the frozen state at step 50, got %+v", stopped)
 				}
 			},
 		},
 		{
-			name: "invalid tickMs (zero)",
-			requestBody: types.CreateSessionRequest{
-				TickMs: 0,
-			},
+			name:           "no ids",
+			requestBody:    types.BatchStateRequest{},
 			expectedStatus: http.StatusBadRequest,
 		},
 		{
-			name: "invalid tickMs (negative)",
-			requestBody: types.CreateSessionRequest{
-				TickMs: -10,
-			},
+			name:           "too many ids",
+			requestBody:    types.BatchStateRequest{IDs: tooMany},
 			expectedStatus: http.StatusBadRequest,
 		},
 		{
-			name:           "invalid JSON",
-			requestBody:    "not json",
+			name:           "invalid body",
+			requestBody:    "not an object",
 			expectedStatus: http.StatusBadRequest,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			var body []byte
-			var err error
-
-			if str, ok := tt.requestBody.(string); ok {
-				body = []byte(str)
-			} else {
-				body, err = json.Marshal(tt.requestBody)
-				if err != nil {
-					t.Fatalf("Failed to marshal request: %v", err)
-				}
+			body, err := json.Marshal(tt.requestBody)
+			if err != nil {
+				t.Fatalf("Failed to marshal request: %v", err)
 			}
 
-			req := httptest.NewRequest("POST", "/v1/sessions", bytes.NewReader(body))
+			req := httptest.NewRequest("POST", "/v1/sessions/states", bytes.NewReader(body))
 			req.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
 
@@ -129,25 +1477,28 @@ func TestHandler_CreateSession(t *testing.T) {
 			router.ServeHTTP(w, req)
 
 			if w.Code != tt.expectedStatus {
-				t.Errorf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+				t.Fatalf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
 			}
-
 			if tt.validate != nil {
-				tt.validate(t, w)
+				var resp types.BatchStateResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				tt.validate(t, resp)
 			}
 		})
 	}
 }
 
-func TestHandler_GetSession(t *testing.T) {
+func TestHandler_GetSessionState(t *testing.T) {
 	store := newMockStore()
 	handler := NewHandler(store)
 
-	// Create a test session
+	// Session without a mode (created before modes existed)
 	session := &types.Session{
-		ID:        "test-session-123",
-		Seed:      "test-seed-987654321",
-		StartAt:   time.Now().Add(3 * time.Second),
+		ID:        "test-session-789",
+		Seed:      "12345",
+		StartAt:   time.Now().Add(-10 * time.Second),
 		TickMs:    100,
 		Status:    "running",
 		CreatedAt: time.Now(),
@@ -161,19 +1512,22 @@ func TestHandler_GetSession(t *testing.T) {
 		validate       func(*testing.T, *httptest.ResponseRecorder)
 	}{
 		{
-			name:           "existing session",
-			sessionID:      "test-session-123",
+			name:           "default mode",
+			sessionID:      "test-session-789",
 			expectedStatus: http.StatusOK,
 			validate: func(t *testing.T, w *httptest.ResponseRecorder) {
-				var resp types.GetSessionResponse
+				var resp types.SessionStateResponse
 				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
 					t.Fatalf("Failed to unmarshal response: %v", err)
 				}
-				if resp.ID != "test-session-123" {
-					t.Errorf("Expected ID test-session-123, got %s", resp.ID)
+				if resp.Mode != "counter" {
+					t.Errorf("Expected mode counter, got %s", resp.Mode)
 				}
-				if resp.Seed != "test-seed-987654321" {
-					t.Errorf("Expected seed test-seed-987654321, got %s", resp.Seed)
+				if resp.EngineVersion != engine.Version1 {
+					t.Errorf("Expected legacy session on engine version 1, got %d", resp.EngineVersion)
+				}
+				if resp.Step < 100 {
+					t.Errorf("Expected step >= 100, got %d", resp.Step)
 				}
 			},
 		},
@@ -182,13 +1536,11 @@ func TestHandler_GetSession(t *testing.T) {
 			sessionID:      "non-existent",
 			expectedStatus: http.StatusNotFound,
 		},
-		// Note: Empty session ID results in 404 from chi router
-		// This is acceptable behavior - empty IDs are invalid anyway
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			url := "/v1/sessions/" + tt.sessionID
+			url := "/v1/sessions/" + tt.sessionID + "/state"
 			req := httptest.NewRequest("GET", url, nil)
 			w := httptest.NewRecorder()
 
@@ -207,60 +1559,395 @@ func TestHandler_GetSession(t *testing.T) {
 	}
 }
 
-func TestHandler_StopSession(t *testing.T) {
+func TestHandler_SessionLifecycle(t *testing.T) {
+	store := newMockStore()
+	handler := NewHandler(store, WithMaxSessionDuration(time.Minute), WithAdminToken("secret"))
+
+	now := time.Now()
+	stoppedAt := now.Add(-5 * time.Second)
+	expiresAt := now.Add(-30 * time.Second)
+	sessions := []*types.Session{
+		{ID: "scheduled", Seed: "12345", StartAt: now.Add(time.Minute), TickMs: 100, Status: "running"},
+		{ID: "running", Seed: "12345", StartAt: now.Add(-10 * time.Second), TickMs: 100, Status: "running"},
+		// Stopped before final states were persisted: frozen at stopped_at
+		{ID: "stopped", Seed: "12345", StartAt: now.Add(-10 * time.Second), TickMs: 100, Status: "stopped", StoppedAt: &stoppedAt},
+		// Expired 30s ago, after 60s of activity, but not yet marked as such
+		{ID: "expired", Seed: "12345", StartAt: now.Add(-90 * time.Second), TickMs: 100, Status: "running", ExpiresAt: &expiresAt},
+		// End conditions met without a write: frozen at the end step
+		{ID: "finished", Seed: "12345", StartAt: now.Add(-10 * time.Second), TickMs: 100, Status: "running", End: &types.EndCondition{StopAtStep: 50}},
+		{ID: "finished-early", Seed: "12345", StartAt: now.Add(-90 * time.Second), TickMs: 100, Status: "running", ExpiresAt: &expiresAt, End: &types.EndCondition{MaxDurationMs: 10000}},
+	}
+	for _, session := range sessions {
+		store.CreateSession(context.Background(), session)
+	}
+
+	tests := []struct {
+		name       string
+		sessionID  string
+		wantPhase  string
+		wantStep   int64
+		exactStep  bool
+		wantStatus string
+	}{
+		{name: "scheduled", sessionID: "scheduled", wantPhase: "scheduled", wantStep: 0, exactStep: true, wantStatus: "running"},
+		{name: "running", sessionID: "running", wantPhase: "running", wantStep: 100, wantStatus: "running"},
+		{name: "stopped is frozen", sessionID: "stopped", wantPhase: "stopped", wantStep: 50, exactStep: true, wantStatus: "stopped"},
+		{name: "expired is frozen", sessionID: "expired", wantPhase: "expired", wantStep: 600, exactStep: true, wantStatus: "expired"},
+		{name: "finished at stop_at_step", sessionID: "finished", wantPhase: "finished", wantStep: 50, exactStep: true, wantStatus: "finished"},
+		{name: "finished before expiry", sessionID: "finished-early", wantPhase: "finished", wantStep: 100, exactStep: true, wantStatus: "finished"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/v1/sessions/"+tt.sessionID+"/state", nil)
+			w := httptest.NewRecorder()
+
+			router := chi.NewRouter()
+			router.Mount("/", handler.Routes())
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+			}
+
+			var resp types.SessionStateResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if resp.Phase != tt.wantPhase {
+				t.Errorf("Expected phase %s, got %s", tt.wantPhase, resp.Phase)
+			}
+			if resp.Status != tt.wantStatus {
+				t.Errorf("Expected status %s, got %s", tt.wantStatus, resp.Status)
+			}
+			if tt.exactStep && resp.Step != tt.wantStep {
+				t.Errorf("Expected step %d, got %d", tt.wantStep, resp.Step)
+			}
+			if !tt.exactStep && resp.Step < tt.wantStep {
+				t.Errorf("Expected step >= %d, got %d", tt.wantStep, resp.Step)
+			}
+		})
+	}
+
+	// Expiry is persisted with the final state
+	expired, _ := store.GetSession(context.Background(), "expired")
+	if expired.Status != "expired" || expired.StoppedAt == nil || !expired.StoppedAt.Equal(expiresAt) {
+		t.Errorf("Expected expiry persisted at %v, got status %s stopped_at %v", expiresAt, expired.Status, expired.StoppedAt)
+	}
+	if expired.FinalState == nil || expired.FinalState.Step != 600 {
+		t.Errorf("Expected final state at step 600, got %+v", expired.FinalState)
+	}
+
+	// Transitions out of ended sessions are rejected
+	transitions := []struct {
+		sessionID      string
+		action         string
+		expectedStatus int
+	}{
+		{sessionID: "expired", action: "pause", expectedStatus: http.StatusConflict},
+		{sessionID: "expired", action: "resume", expectedStatus: http.StatusConflict},
+		{sessionID: "expired", action: "stop", expectedStatus: http.StatusBadRequest},
+		{sessionID: "stopped", action: "pause", expectedStatus: http.StatusConflict},
+		{sessionID: "finished", action: "stop", expectedStatus: http.StatusBadRequest},
+		{sessionID: "scheduled", action: "stop", expectedStatus: http.StatusOK},
+		{sessionID: "running", action: "stop", expectedStatus: http.StatusOK},
+	}
+	for _, tt := range transitions {
+		req := httptest.NewRequest("POST", "/v1/sessions/"+tt.sessionID+"/"+tt.action, nil)
+		req.Header.Set("X-Admin-Token", "secret")
+		w := httptest.NewRecorder()
+		router := chi.NewRouter()
+		router.Mount("/", handler.Routes())
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.expectedStatus {
+			t.Errorf("%s %s: expected status %d, got %d. Body: %s", tt.action, tt.sessionID, tt.expectedStatus, w.Code, w.Body.String())
+		}
+	}
+
+	// Stopping persists the final state at stopped_at
+	stopped, _ := store.GetSession(context.Background(), "running")
+	if stopped.FinalState == nil || stopped.FinalState.Step < 100 {
+		t.Errorf("Expected final state after step 100, got %+v", stopped.FinalState)
+	}
+	scheduled, _ := store.GetSession(context.Background(), "scheduled")
+	if scheduled.FinalState == nil || *scheduled.FinalState != (types.FinalState{}) {
+		t.Errorf("Expected zero final state for a session stopped before start, got %+v", scheduled.FinalState)
+	}
+}
+
+func TestHandler_GetSessionStateTickSchedule(t *testing.T) {
 	store := newMockStore()
 	handler := NewHandler(store)
 
-	// Create a test session
+	// 5s at 100ms (50 steps), then 50ms
 	session := &types.Session{
-		ID:        "test-session-456",
-		Seed:      "test-seed-123456789",
-		StartAt:   time.Now().Add(3 * time.Second),
-		TickMs:    100,
+		ID:      "test-session-schedule",
+		Seed:    "12345",
+		StartAt: time.Now().Add(-10 * time.Second),
+		TickMs:  100,
+		TickSchedule: &types.TickSchedule{
+			Segments: []types.TickSegment{{DurationMs: 5000, TickMs: 100}, {TickMs: 50}},
+		},
 		Status:    "running",
 		CreatedAt: time.Now(),
 	}
 	store.CreateSession(context.Background(), session)
 
+	req := httptest.NewRequest("GET", "/v1/sessions/test-session-schedule/state", nil)
+	w := httptest.NewRecorder()
+	router := chi.NewRouter()
+	router.Mount("/", handler.Routes())
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var resp types.SessionStateResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	// 10s: 50 steps in the first segment + at least 100 in the second
+	if resp.Step < 150 || resp.Step > 160 {
+		t.Errorf("Expected step around 150, got %d", resp.Step)
+	}
+}
+
+func TestHandler_GetSessionStatePhases(t *testing.T) {
+	store := newMockStore()
+	handler := NewHandler(store)
+
+	tests := []struct {
+		name          string
+		startAt       time.Time
+		lobbyTicks    int64
+		wantGamePhase string
+	}{
+		{name: "before start", startAt: time.Now().Add(time.Minute), lobbyTicks: 100, wantGamePhase: ""},
+		{name: "lobby", startAt: time.Now().Add(-time.Second), lobbyTicks: 100, wantGamePhase: "lobby"},
+		{name: "running after the lobby", startAt: time.Now().Add(-20 * time.Second), lobbyTicks: 100, wantGamePhase: "running"},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := fmt.Sprintf("test-session-phases-%d", i)
+			store.CreateSession(context.Background(), &types.Session{
+				ID:                id,
+				Seed:              "12345",
+				StartAt:           tt.startAt,
+				TickMs:            100,
+				LobbyTicks:        tt.lobbyTicks,
+				IntermissionTicks: 30,
+				Status:            "running",
+				CreatedAt:         time.Now(),
+			})
+
+			req := httptest.NewRequest("GET", "/v1/sessions/"+id+"/state", nil)
+			w := httptest.NewRecorder()
+			router := chi.NewRouter()
+			router.Mount("/", handler.Routes())
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+			}
+
+			var resp types.SessionStateResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if resp.GamePhase != tt.wantGamePhase {
+				t.Errorf("Expected game phase %q, got %q", tt.wantGamePhase, resp.GamePhase)
+			}
+			if tt.wantGamePhase == "lobby" {
+				// 1s into a 10s lobby, the counter has not started
+				if resp.TicksLeft < 85 || resp.TicksLeft > 90 || resp.Step != 0 || resp.Value != 0 {
+					t.Errorf("Expected about 90 lobby ticks left at step 0, got %+v", resp)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_MultiTrackSession(t *testing.T) {
+	store := newMockStore()
+	handler := NewHandler(store)
+
+	session := &types.Session{
+		ID:            "test-session-tracks",
+		Seed:          "12345",
+		StartAt:       time.Now().Add(-time.Minute),
+		TickMs:        100,
+		EngineVersion: engine.Version2,
+		Tracks:        3,
+		Status:        "running",
+		CreatedAt:     time.Now(),
+	}
+	store.CreateSession(context.Background(), session)
+
+	serve := func(method, url string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, nil)
+		w := httptest.NewRecorder()
+		router := chi.NewRouter()
+		router.Mount("/", handler.Routes())
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Every track is computed by the engine on its sub-seed
+	w := serve("GET", "/v1/sessions/test-session-tracks/state")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	var state types.SessionStateResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &state); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(state.Tracks) != 3 {
+		t.Fatalf("Expected 3 tracks, got %d", len(state.Tracks))
+	}
+	if state.Tracks[0].Step != state.Step || state.Tracks[0].Value != state.Value || state.Tracks[0].Round != state.Round {
+		t.Errorf("Expected track 0 to match the top-level state, got %+v vs %+v", state.Tracks[0], state)
+	}
+	rule, _ := engine.NewRule(engine.ModeCounter, engine.RuleConfig{Version: engine.Version2})
+	for _, track := range state.Tracks {
+		expected := engine.RuleStateAtStep(rule, engine.TrackSeed(12345, track.Track), track.Step)
+		if track.Value != expected.Value || track.Round != expected.Round || track.Broken != expected.Broken {
+			t.Errorf("Track %d: expected %+v, got %+v", track.Track, expected, track)
+		}
+	}
+
+	// Stopping freezes every track
+	if w := serve("POST", "/v1/sessions/test-session-tracks/stop"); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	stopped, _ := store.GetSession(context.Background(), "test-session-tracks")
+	if len(stopped.FinalTracks) != 3 || stopped.FinalTracks[0] != *stopped.FinalState {
+		t.Errorf("Expected 3 final track states, got %+v", stopped.FinalTracks)
+	}
+
+	// Each track can be verified on its own
+	w = serve("GET", "/v1/sessions/test-session-tracks/verify?track=2")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	var verify types.VerifySessionResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &verify); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	expected := engine.RuleRounds(rule, engine.TrackSeed(12345, 2), verify.StopStep, 0, 1)[0]
+	if verify.Track != 2 || len(verify.Rounds) == 0 || verify.Rounds[0].BreakStep != expected.BreakStep {
+		t.Errorf("Expected track 2 breaking at %d, got track %d rounds %+v", expected.BreakStep, verify.Track, verify.Rounds)
+	}
+
+	if w := serve("GET", "/v1/sessions/test-session-tracks/verify?track=3"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a missing track, got %d", w.Code)
+	}
+}
+
+func TestHandler_VerifySession(t *testing.T) {
+	store := newMockStore()
+	handler := NewHandler(store)
+
+	startAt := time.Now().Add(-time.Minute)
+	stoppedAt := startAt.Add(60 * time.Second) // 600 steps at 100ms
+
+	running := &types.Session{
+		ID:        "test-session-running",
+		Seed:      "seed-running",
+		SeedHash:  fairness.Commit("seed-running"),
+		StartAt:   startAt,
+		TickMs:    100,
+		Status:    "running",
+		CreatedAt: startAt,
+	}
+	stopped := &types.Session{
+		ID:         "test-session-stopped",
+		Seed:       "seed-stopped",
+		SeedHash:   fairness.Commit("seed-stopped"),
+		ClientSeed: "player-seed",
+		Nonce:      3,
+		StartAt:    startAt,
+		TickMs:     100,
+		Mode:       "crash",
+		Status:     "stopped",
+		CreatedAt:  startAt,
+		StoppedAt:  &stoppedAt,
+	}
+	store.CreateSession(context.Background(), running)
+	store.CreateSession(context.Background(), stopped)
+
 	tests := []struct {
 		name           string
-		sessionID      string
+		url            string
 		expectedStatus int
 		validate       func(*testing.T, *httptest.ResponseRecorder)
 	}{
 		{
-			name:           "stop active session",
-			sessionID:      "test-session-456",
+			name:           "stopped session",
+			url:            "/v1/sessions/test-session-stopped/verify",
 			expectedStatus: http.StatusOK,
 			validate: func(t *testing.T, w *httptest.ResponseRecorder) {
-				var resp types.StopSessionResponse
+				var resp types.VerifySessionResponse
 				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
 					t.Fatalf("Failed to unmarshal response: %v", err)
 				}
-				if resp.ID != "test-session-456" {
-					t.Errorf("Expected ID test-session-456, got %s", resp.ID)
+				if !resp.CommitmentValid {
+					t.Error("Expected commitment to be valid")
 				}
-				if resp.Status != "stopped" {
-					t.Errorf("Expected status stopped, got %s", resp.Status)
+				if resp.Seed != "seed-stopped" {
+					t.Errorf("Expected revealed seed, got %s", resp.Seed)
+				}
+				if resp.StopStep != 600 {
+					t.Errorf("Expected stop step 600, got %d", resp.StopStep)
+				}
+				if len(resp.Rounds) == 0 {
+					t.Fatal("Expected rounds, got none")
+				}
+				last := resp.Rounds[len(resp.Rounds)-1]
+				if last.StartStep > resp.StopStep {
+					t.Errorf("Last round starts after stop: %+v", last)
+				}
+				if resp.Rounds[0].Outcome == nil {
+					t.Error("Expected crash outcome for round 0")
 				}
 			},
 		},
 		{
-			name:           "stop non-existent session",
-			sessionID:      "non-existent",
-			expectedStatus: http.StatusNotFound,
+			name:           "pagination",
+			url:            "/v1/sessions/test-session-stopped/verify?from_round=1&limit=1",
+			expectedStatus: http.StatusOK,
+			validate: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var resp types.VerifySessionResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				if len(resp.Rounds) != 1 || resp.Rounds[0].Round != 1 {
+					t.Fatalf("Expected only round 1, got %+v", resp.Rounds)
+				}
+			},
 		},
 		{
-			name:           "stop already stopped session",
-			sessionID:      "test-session-456", // Will be stopped by previous test
+			name:           "running session",
+			url:            "/v1/sessions/test-session-running/verify",
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "invalid limit",
+			url:            "/v1/sessions/test-session-stopped/verify?limit=0",
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name:           "non-existent session",
+			url:            "/v1/sessions/non-existent/verify",
+			expectedStatus: http.StatusNotFound,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			url := "/v1/sessions/" + tt.sessionID + "/stop"
-			req := httptest.NewRequest("POST", url, nil)
+			req := httptest.NewRequest("GET", tt.url, nil)
 			w := httptest.NewRecorder()
 
 			router := chi.NewRouter()
@@ -277,4 +1964,3 @@ func TestHandler_StopSession(t *testing.T) {
 		})
 	}
 }
-
@@ -0,0 +1,134 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/store"
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/types"
+)
+
+// maxIdempotencyKeyLength bounds client-chosen Idempotency-Keys
+const maxIdempotencyKeyLength = 255
+
+// idempotencyLease is how long a key stays in progress when its request
+// neither stores its response nor releases it, e.g. because the store
+// failed or the process died. It outlasts any request.
+const idempotencyLease = 30 * time.Second
+
+// createSessionOnce creates a session at most once per Idempotency-Key.
+//
+// Keys are scoped to the client (see idempotencyScope): authenticated
+// clients cannot replay or block each other's keys, anonymous ones only
+// share keys with clients behind the same address. The key is reserved with a hash of
+// the decoded request before the session is created, for a short lease,
+// and the response stored for the idempotency window once it is. A retry
+// with the same request replays that response; one with a different
+// request, or one arriving while the first is still in progress, is
+// refused. A failed request releases its key so it can be retried.
+func (h *Handler) createSessionOnce(ctx context.Context, w http.ResponseWriter, r *http.Request, key string, req types.CreateSessionRequest) {
+	if len(key) > maxIdempotencyKeyLength {
+		h.respondError(w, http.StatusBadRequest, "invalid idempotency key", "Idempotency-Key must be at most 255 characters")
+		return
+	}
+
+	// Hash the decoded request, so formatting and field order don't matter
+	data, err := json.Marshal(req)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "failed to hash request", err.Error())
+		return
+	}
+	sum := sha256.Sum256(data)
+	record := &types.IdempotencyRecord{
+		Key:         h.idempotencyScope(r) + ":" + key,
+		RequestHash: hex.EncodeToString(sum[:]),
+		CreatedAt:   time.Now(),
+	}
+
+	lease := idempotencyLease
+	if h.idempotencyWindow < lease {
+		lease = h.idempotencyWindow
+	}
+	existing, err := h.store.ReserveIdempotencyKey(ctx, record, lease)
+	if err == store.ErrIdempotencyKeyExists {
+		switch {
+		case existing.RequestHash != record.RequestHash:
+			h.respondError(w, http.StatusConflict, "idempotency key reused", "Idempotency-Key was already used with a different request")
+		case !existing.Completed():
+			h.respondError(w, http.StatusConflict, "request in progress", "a request with this Idempotency-Key is still in progress")
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(existing.Status)
+			w.Write(existing.Body)
+		}
+		return
+	}
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "failed to reserve idempotency key", err.Error())
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: w}
+	h.createSession(ctx, recorder, r, req)
+
+	if recorder.status != http.StatusCreated {
+		_ = h.store.ReleaseIdempotencyKey(ctx, record.Key)
+		return
+	}
+	// Should this fail the key stays in progress until its lease expires:
+	// retries are refused until then rather than creating a second session
+	record.Status = recorder.status
+	record.Body = recorder.body.Bytes()
+	_ = h.store.CompleteIdempotencyKey(ctx, record, h.idempotencyWindow)
+}
+
+// idempotencyScope returns the client an Idempotency-Key belongs to: the
+// admin, the player of a valid X-Player-Token, else the client address.
+// The address is the peer's unless it is a trusted proxy (TrustedRealIP),
+// so clients cannot pick it with X-Forwarded-For. The scope is hashed, so
+// scoped keys are unambiguous and bounded in length.
+func (h *Handler) idempotencyScope(r *http.Request) string {
+	client := "addr:" + r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		client = "addr:" + host
+	}
+	if token := r.Header.Get("X-Player-Token"); token != "" && h.playerSecret != "" {
+		if playerID, err := verifyPlayerToken(h.playerSecret, token, time.Now()); err == nil {
+			client = "player:" + playerID
+		}
+	}
+	if h.isAdmin(r) {
+		client = "admin"
+	}
+
+	sum := sha256.Sum256([]byte(client))
+	return hex.EncodeToString(sum[:16])
+}
+
+// responseRecorder passes a response through while keeping a copy of its
+// status and body.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(data []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	rec.body.Write(data)
+	return rec.ResponseWriter.Write(data)
+}
@@ -0,0 +1,157 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/types"
+	"github.com/go-chi/chi/v5"
+)
+
+func TestHandler_CreateSessionIdempotency(t *testing.T) {
+	store := newMockStore()
+	handler := NewHandler(store)
+	router := chi.NewRouter()
+	router.Mount("/", handler.Routes())
+
+	create := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/v1/sessions", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := create("key-1", `{"tick_ms": 100, "metadata": {"a": 1}}`)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", first.Code, first.Body.String())
+	}
+
+	// A retry, formatted differently, replays the first response
+	retry := create("key-1", `{"metadata":{"a":1},"tick_ms":100}`)
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Errorf("Expected the first response replayed, got %d: %s", retry.Code, retry.Body.String())
+	}
+	if retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("Expected Idempotent-Replayed header on the replay")
+	}
+	if len(store.sessions) != 1 {
+		t.Errorf("Expected 1 session, got %d", len(store.sessions))
+	}
+
+	tests := []struct {
+		name           string
+		key            string
+		body           string
+		expectedStatus int
+	}{
+		{name: "different body", key: "key-1", body: `{"tick_ms": 200}`, expectedStatus: http.StatusConflict},
+		{name: "key too long", key: strings.Repeat("k", 256), body: `{"tick_ms": 100}`, expectedStatus: http.StatusBadRequest},
+		{name: "invalid request", key: "key-2", body: `{"tick_ms": 0}`, expectedStatus: http.StatusBadRequest},
+		{name: "retry after invalid request", key: "key-2", body: `{"tick_ms": 100}`, expectedStatus: http.StatusCreated},
+		{name: "no key", body: `{"tick_ms": 100}`, expectedStatus: http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := create(tt.key, tt.body); w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+	if len(store.sessions) != 3 {
+		t.Errorf("Expected 3 sessions, got %d", len(store.sessions))
+	}
+
+	// Keys are stored per client; a completed one is kept for the window
+	scoped := func(key string) string {
+		return handler.idempotencyScope(httptest.NewRequest("POST", "/v1/sessions", nil)) + ":" + key
+	}
+	if ttl := store.keyTTLs[scoped("key-1")]; ttl != defaultIdempotencyWindow {
+		t.Errorf("Expected a completed key kept for %v, got %v", defaultIdempotencyWindow, ttl)
+	}
+
+	// A request still in progress is refused rather than run twice
+	var record types.IdempotencyRecord
+	json.Unmarshal(store.keys[scoped("key-1")], &record)
+	record.Key, record.Status, record.Body = scoped("key-3"), 0, nil
+	store.ReserveIdempotencyKey(context.Background(), &record, time.Hour)
+	if w := create("key-3", `{"tick_ms": 100, "metadata": {"a": 1}}`); w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 while in progress, got %d", w.Code)
+	}
+}
+
+func TestHandler_CreateSessionIdempotencyScope(t *testing.T) {
+	store := newMockStore()
+	handler := NewHandler(store, WithAdminToken("admin-secret"), WithPlayerSecret(testPlayerSecret))
+	router := chi.NewRouter()
+	router.Mount("/", handler.Routes())
+
+	create := func(header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/v1/sessions", strings.NewReader(`{"tick_ms": 100}`))
+		req.Header.Set("Idempotency-Key", "shared-key")
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// The same key from different clients creates one session each
+	for _, client := range []struct {
+		name   string
+		header string
+		value  string
+	}{
+		{name: "anonymous"},
+		{name: "admin", header: "X-Admin-Token", value: "admin-secret"},
+		{name: "player", header: "X-Player-Token", value: signPlayerToken(testPlayerSecret, "alice", time.Now().Add(time.Hour))},
+	} {
+		if w := create(client.header, client.value); w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "" {
+			t.Errorf("%s: expected a new session, got %d: %s", client.name, w.Code, w.Body.String())
+		}
+	}
+	if len(store.sessions) != 3 {
+		t.Errorf("Expected 3 sessions, got %d", len(store.sessions))
+	}
+}
+
+// incompleteStore fails to store idempotent responses.
+type incompleteStore struct {
+	*mockStore
+}
+
+func (incompleteStore) CompleteIdempotencyKey(ctx context.Context, record *types.IdempotencyRecord, window time.Duration) error {
+	return errors.New("redis down")
+}
+
+func TestHandler_CreateSessionIdempotencyLease(t *testing.T) {
+	store := newMockStore()
+	handler := NewHandler(incompleteStore{store})
+	router := chi.NewRouter()
+	router.Mount("/", handler.Routes())
+
+	req := httptest.NewRequest("POST", "/v1/sessions", strings.NewReader(`{"tick_ms": 100}`))
+	req.Header.Set("Idempotency-Key", "key-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// The response was not stored: the key stays in progress for its short
+	// lease only, not the whole window
+	key := handler.idempotencyScope(req) + ":key-1"
+	if ttl := store.keyTTLs[key]; ttl != idempotencyLease {
+		t.Errorf("Expected the key leased for %v, got %v", idempotencyLease, ttl)
+	}
+}
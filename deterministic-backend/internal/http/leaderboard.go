@@ -0,0 +1,137 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/engine"
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/store"
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/types"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// defaultLeaderboardLimit and maxLeaderboardLimit bound players per leaderboard response
+	defaultLeaderboardLimit = 10
+	maxLeaderboardLimit     = 100
+)
+
+// GetLeaderboard handles GET /v1/leaderboards/{board}
+//
+// Serves the daily (?scope=day, &day=YYYY-MM-DD, default today in UTC) or
+// all-time (?scope=all, the default) leaderboard of a game mode (?mode=,
+// default counter).
+func (h *Handler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	board, limit, ok := h.leaderboardParams(w, r)
+	if !ok {
+		return
+	}
+
+	params := r.URL.Query()
+	mode := params.Get("mode")
+	if mode == "" {
+		mode = engine.DefaultMode
+	}
+	if !slices.Contains(engine.Modes(), mode) {
+		h.respondError(w, http.StatusBadRequest, "invalid mode", fmt.Sprintf("mode must be one of %v", engine.Modes()))
+		return
+	}
+
+	scope := store.LeaderboardScope{Scope: types.ScopeAllTime, Mode: mode}
+	switch params.Get("scope") {
+	case "", types.ScopeAllTime:
+		if params.Get("day") != "" {
+			h.respondError(w, http.StatusBadRequest, "invalid day", "day requires scope=day")
+			return
+		}
+	case types.ScopeDay:
+		scope = store.LeaderboardScope{Scope: types.ScopeDay, Day: time.Now().UTC().Format("2006-01-02"), Mode: mode}
+		if v := params.Get("day"); v != "" {
+			if _, err := time.Parse("2006-01-02", v); err != nil {
+				h.respondError(w, http.StatusBadRequest, "invalid day", "day must be a date (YYYY-MM-DD)")
+				return
+			}
+			scope.Day = v
+		}
+	default:
+		h.respondError(w, http.StatusBadRequest, "invalid scope", "scope must be day or all")
+		return
+	}
+
+	entries, err := h.store.Leaderboard(ctx, board, scope, limit)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "failed to get leaderboard", err.Error())
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, types.LeaderboardResponse{
+		Board:   board,
+		Scope:   scope.Scope,
+		Day:     scope.Day,
+		Mode:    scope.Mode,
+		Entries: entries,
+	})
+}
+
+// GetSessionLeaderboard handles GET /v1/sessions/{id}/leaderboards/{board}
+func (h *Handler) GetSessionLeaderboard(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	board, limit, ok := h.leaderboardParams(w, r)
+	if !ok {
+		return
+	}
+
+	session, err := h.getSession(ctx, chi.URLParam(r, "id"), time.Now())
+	if err != nil {
+		if err == store.ErrSessionNotFound {
+			h.respondError(w, http.StatusNotFound, "session not found", err.Error())
+			return
+		}
+		h.respondError(w, http.StatusInternalServerError, "failed to get session", err.Error())
+		return
+	}
+
+	scope := store.LeaderboardScope{Scope: types.ScopeSession, SessionID: session.ID}
+	entries, err := h.store.Leaderboard(ctx, board, scope, limit)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "failed to get leaderboard", err.Error())
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, types.LeaderboardResponse{
+		Board:     board,
+		Scope:     scope.Scope,
+		SessionID: session.ID,
+		Entries:   entries,
+	})
+}
+
+// leaderboardParams parses the board and limit of a leaderboard request.
+// On failure it responds and returns false.
+func (h *Handler) leaderboardParams(w http.ResponseWriter, r *http.Request) (string, int, bool) {
+	board := chi.URLParam(r, "board")
+	if board != types.BoardCashOut && board != types.BoardSurvival {
+		h.respondError(w, http.StatusBadRequest, "invalid board", "board must be cashout or survival")
+		return "", 0, false
+	}
+
+	limit := defaultLeaderboardLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 || parsed > maxLeaderboardLimit {
+			h.respondError(w, http.StatusBadRequest, "invalid limit", "limit must be between 1 and 100")
+			return "", 0, false
+		}
+		limit = parsed
+	}
+	return board, limit, true
+}
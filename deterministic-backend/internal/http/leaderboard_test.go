@@ -0,0 +1,154 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/engine"
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/types"
+	"github.com/go-chi/chi/v5"
+)
+
+func TestHandler_Leaderboards(t *testing.T) {
+	store := newMockStore()
+	handler := NewHandler(store, WithPlayerSecret(testPlayerSecret))
+	router := chi.NewRouter()
+	router.Mount("/", handler.Routes())
+
+	state := runningAtRound(t, store, "test-session-leaderboard", engine.ModeCrash)
+
+	// Cash-outs of the previous round, as recorded by earlier requests
+	settledAt := time.Now()
+	for _, cashOut := range []struct {
+		playerID string
+		score    int64
+	}{
+		{playerID: "alice", score: 101},
+		{playerID: "bob", score: 50000},
+	} {
+		store.RecordCashOut(context.Background(), &types.Entry{
+			SessionID: "test-session-leaderboard",
+			Round:     state.Round - 1,
+			PlayerID:  cashOut.playerID,
+			Status:    types.EntryCashedOut,
+			SettledAt: &settledAt,
+		}, engine.ModeCrash, cashOut.score)
+	}
+
+	// Alice cashes out again in the next round
+	store.CreateEntry(context.Background(), &types.Entry{
+		SessionID: "test-session-leaderboard",
+		Round:     state.Round,
+		PlayerID:  "alice",
+		Status:    types.EntryJoined,
+		JoinedAt:  time.Now(),
+	})
+	path := "/v1/sessions/test-session-leaderboard/rounds/" + strconv.FormatInt(state.Round, 10) + "/cashout"
+	if w, _ := playerRequest(router, "POST", path, "alice", types.CashOutRequest{PlayerID: "alice"}); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Crash cash-outs score the multiplier in hundredths, not the tick
+	aliceBest := engine.CrashRule{HouseEdge: engine.DefaultHouseEdge, Growth: engine.DefaultGrowth}.Payout(100, state)
+	if aliceBest < 101 {
+		aliceBest = 101
+	}
+	today := time.Now().UTC().Format("2006-01-02")
+
+	tests := []struct {
+		name     string
+		path     string
+		expected []types.LeaderboardEntry
+	}{
+		{
+			name:     "session cash-outs",
+			path:     "/v1/sessions/test-session-leaderboard/leaderboards/cashout",
+			expected: []types.LeaderboardEntry{{Rank: 1, PlayerID: "bob", Score: 50000}, {Rank: 2, PlayerID: "alice", Score: aliceBest}},
+		},
+		{
+			name:     "session survival",
+			path:     "/v1/sessions/test-session-leaderboard/leaderboards/survival",
+			expected: []types.LeaderboardEntry{{Rank: 1, PlayerID: "alice", Score: 2}, {Rank: 2, PlayerID: "bob", Score: 1}},
+		},
+		{
+			name:     "today",
+			path:     "/v1/leaderboards/survival?mode=crash&scope=day&day=" + today,
+			expected: []types.LeaderboardEntry{{Rank: 1, PlayerID: "alice", Score: 2}, {Rank: 2, PlayerID: "bob", Score: 1}},
+		},
+		{
+			name:     "another day",
+			path:     "/v1/leaderboards/cashout?mode=crash&scope=day&day=2020-01-01",
+			expected: []types.LeaderboardEntry{},
+		},
+		{
+			name:     "all time, limited",
+			path:     "/v1/leaderboards/cashout?mode=crash&limit=1",
+			expected: []types.LeaderboardEntry{{Rank: 1, PlayerID: "bob", Score: 50000}},
+		},
+		{
+			// Counter values are not multipliers: other modes rank apart
+			name:     "all time, another mode",
+			path:     "/v1/leaderboards/cashout",
+			expected: []types.LeaderboardEntry{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+			var response types.LeaderboardResponse
+			json.NewDecoder(w.Body).Decode(&response)
+			if len(response.Entries) != len(tt.expected) {
+				t.Fatalf("Expected entries %+v, got %+v", tt.expected, response.Entries)
+			}
+			for i := range tt.expected {
+				if response.Entries[i] != tt.expected[i] {
+					t.Errorf("Expected entries %+v, got %+v", tt.expected, response.Entries)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_LeaderboardsInvalid(t *testing.T) {
+	handler := NewHandler(newMockStore())
+	router := chi.NewRouter()
+	router.Mount("/", handler.Routes())
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+	}{
+		{name: "unknown board", path: "/v1/leaderboards/wins", expectedStatus: http.StatusBadRequest},
+		{name: "unknown mode", path: "/v1/leaderboards/cashout?mode=poker", expectedStatus: http.StatusBadRequest},
+		{name: "unknown scope", path: "/v1/leaderboards/cashout?scope=week", expectedStatus: http.StatusBadRequest},
+		{name: "invalid day", path: "/v1/leaderboards/cashout?scope=day&day=15-01-2024", expectedStatus: http.StatusBadRequest},
+		{name: "day without day scope", path: "/v1/leaderboards/cashout?day=2024-01-15", expectedStatus: http.StatusBadRequest},
+		{name: "invalid limit", path: "/v1/leaderboards/cashout?limit=101", expectedStatus: http.StatusBadRequest},
+		{name: "session not found", path: "/v1/sessions/non-existent/leaderboards/cashout", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,441 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/engine"
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/store"
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/types"
+	"github.com/go-chi/chi/v5"
+)
+
+// maxPlayerIDLength bounds player IDs
+const maxPlayerIDLength = 128
+
+// JoinRound handles POST /v1/sessions/{id}/rounds/{round}/join
+//
+// A player may only join the round that is open: the one that starts next
+// on the track. That is the current round before start, in the lobby and
+// during intermission, and the round after it while it is running, so no
+// player joins a round that is already under way.
+//
+// Like every player action, it requires the player's X-Player-Token or the
+// admin token.
+func (h *Handler) JoinRound(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	sessionID := chi.URLParam(r, "id")
+	round, err := strconv.ParseInt(chi.URLParam(r, "round"), 10, 64)
+	if err != nil || round < 0 {
+		h.respondError(w, http.StatusBadRequest, "invalid round", "round must be a non-negative integer")
+		return
+	}
+
+	var req types.JoinRoundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	if req.PlayerID == "" || len(req.PlayerID) > maxPlayerIDLength {
+		h.respondError(w, http.StatusBadRequest, "invalid player_id", "player_id must be between 1 and 128 characters")
+		return
+	}
+	if !h.authorizePlayer(w, r, req.PlayerID) {
+		return
+	}
+	if req.Stake < 0 {
+		h.respondError(w, http.StatusBadRequest, "invalid stake", "stake must not be negative")
+		return
+	}
+	if req.Stake > 0 && h.ledger == nil {
+		h.respondError(w, http.StatusNotImplemented, "wallets disabled", "stakes require a wallet ledger")
+		return
+	}
+
+	now := time.Now()
+	session, rule, seed, ok := h.playerSession(w, ctx, sessionID, req.Track, now)
+	if !ok {
+		return
+	}
+	if _, pays := rule.(engine.Payer); req.Stake > 0 && !pays {
+		h.respondError(w, http.StatusBadRequest, "invalid stake", fmt.Sprintf("mode %q has no payouts", rule.Mode()))
+		return
+	}
+	if session.Ended() {
+		h.respondError(w, http.StatusConflict, "session ended", "the session has ended")
+		return
+	}
+
+	state := sessionStates(session, rule, seed, now)[req.Track]
+	open := openRound(state)
+	if session.End != nil && session.End.MaxRounds > 0 && open >= session.End.MaxRounds {
+		h.respondError(w, http.StatusConflict, "round not open", "the session ends before another round starts")
+		return
+	}
+	if round != open {
+		h.respondError(w, http.StatusConflict, "round not open", fmt.Sprintf("round %d is open for joining", open))
+		return
+	}
+
+	entry := &types.Entry{
+		SessionID: session.ID,
+		Track:     req.Track,
+		Round:     round,
+		PlayerID:  req.PlayerID,
+		Status:    types.EntryJoined,
+		JoinedAt:  now,
+		Stake:     req.Stake,
+	}
+
+	// The entry is created before its stake is debited, so every stake
+	// has an entry that reconciliation finds
+	if err := h.store.CreateEntry(ctx, entry); err != nil {
+		if err == store.ErrEntryExists {
+			h.respondError(w, http.StatusConflict, "already joined", err.Error())
+			return
+		}
+		h.respondError(w, http.StatusInternalServerError, "failed to join round", err.Error())
+		return
+	}
+	if entry.Stake > 0 {
+		if err := h.debitStake(ctx, entry, now); err != nil {
+			switch err {
+			case store.ErrInsufficientFunds:
+				h.respondError(w, http.StatusConflict, "insufficient funds", err.Error())
+			case store.ErrTransactionConflict:
+				h.respondError(w, http.StatusConflict, "already joined", "the player already staked a different amount on this round")
+			default:
+				h.respondError(w, http.StatusInternalServerError, "failed to debit stake", err.Error())
+			}
+			return
+		}
+	}
+
+	h.respondJSON(w, http.StatusCreated, entryResponse(entry, rule, engine.TrackSeed(seed, req.Track)))
+}
+
+// debitStake posts the stake of a new entry. If it cannot be posted the
+// entry is deleted again, so no entry plays without its stake. An error
+// after which the stake may have posted keeps the entry unless the stake
+// is found missing; reconciliation reports an entry left without one.
+func (h *Handler) debitStake(ctx context.Context, entry *types.Entry, now time.Time) error {
+	_, err := h.ledger.Post(ctx, roundTransaction(types.TransactionStake, entry, entry.Stake, now))
+	if err == nil {
+		return nil
+	}
+	if err != store.ErrInsufficientFunds && err != store.ErrTransactionConflict {
+		stake, getErr := h.ledgerAmount(ctx, types.TransactionStake, entry, types.AccountHouse)
+		if getErr != nil {
+			return err
+		}
+		if stake == entry.Stake {
+			return nil // Posted before the error
+		}
+	}
+	if delErr := h.store.DeleteEntry(ctx, entry.SessionID, entry.Track, entry.Round, entry.PlayerID); delErr != nil {
+		return fmt.Errorf("%v; failed to delete the unstaked entry: %w", err, delErr)
+	}
+	return err
+}
+
+// CashOut handles POST /v1/sessions/{id}/rounds/{round}/cashout
+//
+// The cash-out step is the session's step when the request arrives, so
+// clients cannot pick a step after seeing the break. It is rejected if the
+// round has not started or has already broken (or, under rules such as
+// crash, cannot be cashed out at that step); in the latter cases the entry
+// is recorded as lost (voided if the session ended first). Otherwise
+// the entry is settled with the value at that step, the payout of its
+// stake is credited and the leaderboards are updated.
+func (h *Handler) CashOut(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	sessionID := chi.URLParam(r, "id")
+	round, err := strconv.ParseInt(chi.URLParam(r, "round"), 10, 64)
+	if err != nil || round < 0 {
+		h.respondError(w, http.StatusBadRequest, "invalid round", "round must be a non-negative integer")
+		return
+	}
+
+	var req types.CashOutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	if req.PlayerID == "" || len(req.PlayerID) > maxPlayerIDLength {
+		h.respondError(w, http.StatusBadRequest, "invalid player_id", "player_id must be between 1 and 128 characters")
+		return
+	}
+	if !h.authorizePlayer(w, r, req.PlayerID) {
+		return
+	}
+
+	now := time.Now()
+	session, rule, seed, ok := h.playerSession(w, ctx, sessionID, req.Track, now)
+	if !ok {
+		return
+	}
+	entry, ok := h.playerEntry(w, ctx, session, req.Track, round, req.PlayerID)
+	if !ok {
+		return
+	}
+	if entry.Settled() {
+		h.respondError(w, http.StatusConflict, "entry settled", fmt.Sprintf("the entry is already %s", entry.Status))
+		return
+	}
+
+	trackSeed := engine.TrackSeed(seed, req.Track)
+	state := sessionStates(session, rule, seed, now)[req.Track]
+	if status := closedStatus(session, state, round); status != "" {
+		entry, err := h.closeEntry(ctx, entry, status, now)
+		if err != nil {
+			h.respondError(w, http.StatusInternalServerError, "failed to settle entry", err.Error())
+			return
+		}
+		switch entry.Status {
+		case types.EntryLost:
+			h.respondError(w, http.StatusConflict, "round broke", fmt.Sprintf("round %d has already broken", round))
+		case types.EntryVoided:
+			h.respondError(w, http.StatusConflict, "round voided", fmt.Sprintf("the session ended before round %d broke", round))
+		default:
+			h.respondError(w, http.StatusConflict, "entry settled", fmt.Sprintf("the entry is already %s", entry.Status))
+		}
+		return
+	}
+	switch {
+	case state.Round < round || state.Phase != engine.GamePhaseRunning || state.Broken:
+		h.respondError(w, http.StatusConflict, "round not started", fmt.Sprintf("round %d has not started", round))
+		return
+	case session.Phase(now) != types.PhaseRunning:
+		h.respondError(w, http.StatusConflict, "session not running", fmt.Sprintf("cannot cash out while the session is %s", session.Phase(now)))
+		return
+	}
+
+	// A round that crashes instantly has already bust at its only tick
+	if cashOuter, ok := rule.(engine.CashOuter); ok && !cashOuter.CanCashOut(trackSeed, state) {
+		if _, err := h.closeEntry(ctx, entry, types.EntryLost, now); err != nil {
+			h.respondError(w, http.StatusInternalServerError, "failed to settle entry", err.Error())
+			return
+		}
+		h.respondError(w, http.StatusConflict, "round broke", fmt.Sprintf("round %d crashed at its first tick", round))
+		return
+	}
+
+	if payer, ok := rule.(engine.Payer); ok && entry.Stake > 0 {
+		entry.Payout = payer.Payout(entry.Stake, state)
+	}
+	if err := h.settleEntry(ctx, entry, types.EntryCashedOut, state, now); err != nil {
+		if err == store.ErrEntrySettled {
+			h.respondError(w, http.StatusConflict, "entry settled", err.Error())
+			return
+		}
+		h.respondError(w, http.StatusInternalServerError, "failed to cash out", err.Error())
+		return
+	}
+
+	// A payout that fails to post is found and posted by reconciliation
+	if entry.Payout > 0 {
+		if _, err := h.ledger.Post(ctx, roundTransaction(types.TransactionPayout, entry, entry.Payout, now)); err != nil {
+			h.respondError(w, http.StatusInternalServerError, "failed to credit payout", err.Error())
+			return
+		}
+	}
+
+	// Leaderboards are best effort: the cash-out stands either way
+	_ = h.store.RecordCashOut(ctx, entry, rule.Mode(), cashOutScore(rule, state))
+
+	h.respondJSON(w, http.StatusOK, entryResponse(entry, rule, trackSeed))
+}
+
+// GetEntry handles GET /v1/sessions/{id}/rounds/{round}/players/{player}
+//
+// An entry still joined after its round broke is recorded as lost, or
+// voided with its stake refunded if the session ended first.
+func (h *Handler) GetEntry(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	sessionID := chi.URLParam(r, "id")
+	round, err := strconv.ParseInt(chi.URLParam(r, "round"), 10, 64)
+	if err != nil || round < 0 {
+		h.respondError(w, http.StatusBadRequest, "invalid round", "round must be a non-negative integer")
+		return
+	}
+
+	track := 0
+	if v := r.URL.Query().Get("track"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 0 || parsed >= maxTracks {
+			h.respondError(w, http.StatusBadRequest, "invalid track", "track must be between 0 and 15")
+			return
+		}
+		track = parsed
+	}
+
+	playerID := chi.URLParam(r, "player")
+	if !h.authorizePlayer(w, r, playerID) {
+		return
+	}
+
+	now := time.Now()
+	session, rule, seed, ok := h.playerSession(w, ctx, sessionID, track, now)
+	if !ok {
+		return
+	}
+	entry, ok := h.playerEntry(w, ctx, session, track, round, playerID)
+	if !ok {
+		return
+	}
+
+	state := sessionStates(session, rule, seed, now)[track]
+	if status := closedStatus(session, state, round); !entry.Settled() && status != "" {
+		if entry, err = h.closeEntry(ctx, entry, status, now); err != nil {
+			h.respondError(w, http.StatusInternalServerError, "failed to settle entry", err.Error())
+			return
+		}
+	}
+
+	h.respondJSON(w, http.StatusOK, entryResponse(entry, rule, engine.TrackSeed(seed, track)))
+}
+
+// playerSession loads a session for a player action on one of its tracks,
+// with its rule and seed. On failure it responds and returns false.
+func (h *Handler) playerSession(w http.ResponseWriter, ctx context.Context, sessionID string, track int, now time.Time) (*types.Session, engine.Rule, int64, bool) {
+	session, err := h.getSession(ctx, sessionID, now)
+	if err != nil {
+		if err == store.ErrSessionNotFound {
+			h.respondError(w, http.StatusNotFound, "session not found", err.Error())
+			return nil, nil, 0, false
+		}
+		h.respondError(w, http.StatusInternalServerError, "failed to get session", err.Error())
+		return nil, nil, 0, false
+	}
+
+	if track < 0 || track >= sessionTracks(session) {
+		h.respondError(w, http.StatusBadRequest, "invalid track", "session has no such track")
+		return nil, nil, 0, false
+	}
+
+	seed, err := engineSeed(session)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "invalid seed format", err.Error())
+		return nil, nil, 0, false
+	}
+	rule, err := sessionRule(session)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "invalid session mode", err.Error())
+		return nil, nil, 0, false
+	}
+	return session, rule, seed, true
+}
+
+// playerEntry loads a player's entry in a round. On failure it responds and
+// returns false.
+func (h *Handler) playerEntry(w http.ResponseWriter, ctx context.Context, session *types.Session, track int, round int64, playerID string) (*types.Entry, bool) {
+	entry, err := h.store.GetEntry(ctx, session.ID, track, round, playerID)
+	if err != nil {
+		if err == store.ErrEntryNotFound {
+			h.respondError(w, http.StatusNotFound, "entry not found", err.Error())
+			return nil, false
+		}
+		h.respondError(w, http.StatusInternalServerError, "failed to get entry", err.Error())
+		return nil, false
+	}
+	return entry, true
+}
+
+// settleEntry settles an entry with the given status; a cash-out records
+// the state it happened at.
+func (h *Handler) settleEntry(ctx context.Context, entry *types.Entry, status string, state engine.State, now time.Time) error {
+	entry.Status = status
+	entry.SettledAt = &now
+	if status == types.EntryCashedOut {
+		entry.Step, entry.Value = state.Step, state.Value
+	}
+	return h.store.SettleEntry(ctx, entry)
+}
+
+// cashOutScore returns the cashout leaderboard score of a cash-out at the
+// given state: under rules with a payout, the payout of a stake of 100,
+// i.e. the multiplier in hundredths, so scores compare across sessions
+// whatever their tick rate or stakes; otherwise the value. Scores only
+// compare within a mode, which keeps its own day and all-time boards.
+func cashOutScore(rule engine.Rule, state engine.State) int64 {
+	if payer, ok := rule.(engine.Payer); ok {
+		return payer.Payout(100, state)
+	}
+	return state.Value
+}
+
+// closedStatus returns the status a joined entry in the given round is
+// settled with once it can no longer cash out: lost once the round broke,
+// voided if the session ended before that. Empty while it is still open.
+func closedStatus(session *types.Session, state engine.State, round int64) string {
+	switch {
+	case state.Round > round:
+		return types.EntryLost
+	case session.Ended():
+		return types.EntryVoided
+	}
+	return ""
+}
+
+// closeEntry settles a joined entry that can no longer cash out with the
+// given status and refunds the stake of a voided one. It returns the
+// settled entry, reloaded if it was settled concurrently.
+func (h *Handler) closeEntry(ctx context.Context, entry *types.Entry, status string, now time.Time) (*types.Entry, error) {
+	err := h.settleEntry(ctx, entry, status, engine.State{}, now)
+	if err == store.ErrEntrySettled {
+		return h.store.GetEntry(ctx, entry.SessionID, entry.Track, entry.Round, entry.PlayerID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if status == types.EntryVoided && entry.Stake > 0 && h.ledger != nil {
+		if _, err := h.ledger.Post(ctx, roundTransaction(types.TransactionRefund, entry, entry.Stake, now)); err != nil {
+			return nil, err
+		}
+	}
+	return entry, nil
+}
+
+// openRound returns the round players can join on a track in the given
+// state: the current round until it is running, else the next one.
+func openRound(state engine.State) int64 {
+	if state.Phase == engine.GamePhaseRunning {
+		return state.Round + 1
+	}
+	return state.Round
+}
+
+// entryResponse builds the public view of an entry; seed is the track's.
+func entryResponse(entry *types.Entry, rule engine.Rule, seed int64) types.EntryResponse {
+	response := types.EntryResponse{
+		SessionID: entry.SessionID,
+		Track:     entry.Track,
+		Round:     entry.Round,
+		PlayerID:  entry.PlayerID,
+		Status:    entry.Status,
+		JoinedAt:  entry.JoinedAt.Format(time.RFC3339),
+		SettledAt: formatTime(entry.SettledAt),
+		Stake:     entry.Stake,
+	}
+	if entry.Status == types.EntryCashedOut {
+		step, value := entry.Step, entry.Value
+		response.Step, response.Value = &step, &value
+		response.Outcome = rule.Outcome(seed, engine.State{Step: step, Value: value, Round: entry.Round})
+		if entry.Stake > 0 {
+			payout := entry.Payout
+			response.Payout = &payout
+		}
+	}
+	return response
+}
@@ -0,0 +1,277 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/engine"
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/types"
+	"github.com/go-chi/chi/v5"
+)
+
+// playerTickMs is long enough that a test never sees the step change
+const playerTickMs = 3600000
+
+// runningAtRound creates a session of the given mode whose track 0 is
+// mid-round at the current time, at least one round in, and returns that
+// state.
+func runningAtRound(t *testing.T, s *mockStore, id, mode string) engine.State {
+	return runningWhere(t, s, id, mode, func(engine.Rule, int64, engine.State) bool { return true })
+}
+
+// runningWhere is runningAtRound for the first such state that also
+// satisfies match.
+func runningWhere(t *testing.T, s *mockStore, id, mode string, match func(rule engine.Rule, seed int64, state engine.State) bool) engine.State {
+	session := &types.Session{
+		ID:        id,
+		Seed:      "12345",
+		Mode:      mode,
+		TickMs:    playerTickMs,
+		Status:    "running",
+		CreatedAt: time.Now(),
+	}
+	seed, _ := engineSeed(session)
+	rule, _ := sessionRule(session)
+
+	step := int64(400)
+	for state := engine.RuleStateAtStep(rule, seed, step); state.Broken || state.Round < 1 || !match(rule, seed, state); state = engine.RuleStateAtStep(rule, seed, step) {
+		step++
+	}
+	// Half a tick in, so the step holds for the rest of the test
+	session.StartAt = time.Now().Add(-time.Duration(step)*playerTickMs*time.Millisecond - playerTickMs/2*time.Millisecond)
+	s.CreateSession(context.Background(), session)
+
+	state := trackStatesAt(session, rule, seed, time.Now())[0]
+	if state.Step != step || state.Phase != engine.GamePhaseRunning {
+		t.Fatalf("Expected running state at step %d, got %+v", step, state)
+	}
+	return state
+}
+
+// testPlayerSecret signs the player tokens of tests
+const testPlayerSecret = "player-secret"
+
+// playerRequest sends a player request with the player's token and decodes
+// an entry response.
+func playerRequest(router http.Handler, method, path, playerID string, body interface{}) (*httptest.ResponseRecorder, types.EntryResponse) {
+	var data []byte
+	if body != nil {
+		data, _ = json.Marshal(body)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	req.Header.Set("X-Player-Token", signPlayerToken(testPlayerSecret, playerID, time.Now().Add(time.Hour)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response types.EntryResponse
+	if w.Code < 300 {
+		json.NewDecoder(w.Body).Decode(&response)
+	}
+	return w, response
+}
+
+func TestHandler_JoinRound(t *testing.T) {
+	store := newMockStore()
+	handler := NewHandler(store, WithPlayerSecret(testPlayerSecret))
+	router := chi.NewRouter()
+	router.Mount("/", handler.Routes())
+
+	store.CreateSession(context.Background(), &types.Session{
+		ID:        "test-session-join",
+		Seed:      "12345",
+		StartAt:   time.Now().Add(time.Hour),
+		TickMs:    100,
+		Status:    "running",
+		CreatedAt: time.Now(),
+	})
+	stoppedAt := time.Now()
+	store.CreateSession(context.Background(), &types.Session{
+		ID:        "test-session-join-stopped",
+		Seed:      "12345",
+		StartAt:   time.Now().Add(-time.Minute),
+		TickMs:    100,
+		Status:    "stopped",
+		StoppedAt: &stoppedAt,
+		CreatedAt: time.Now(),
+	})
+
+	tests := []struct {
+		name           string
+		path           string
+		body           types.JoinRoundRequest
+		expectedStatus int
+	}{
+		{name: "join before start", path: "/v1/sessions/test-session-join/rounds/0/join", body: types.JoinRoundRequest{PlayerID: "alice"}, expectedStatus: http.StatusCreated},
+		{name: "already joined", path: "/v1/sessions/test-session-join/rounds/0/join", body: types.JoinRoundRequest{PlayerID: "alice"}, expectedStatus: http.StatusConflict},
+		{name: "round not open", path: "/v1/sessions/test-session-join/rounds/1/join", body: types.JoinRoundRequest{PlayerID: "bob"}, expectedStatus: http.StatusConflict},
+		{name: "invalid round", path: "/v1/sessions/test-session-join/rounds/-1/join", body: types.JoinRoundRequest{PlayerID: "bob"}, expectedStatus: http.StatusBadRequest},
+		{name: "missing player_id", path: "/v1/sessions/test-session-join/rounds/0/join", body: types.JoinRoundRequest{}, expectedStatus: http.StatusBadRequest},
+		{name: "no such track", path: "/v1/sessions/test-session-join/rounds/0/join", body: types.JoinRoundRequest{PlayerID: "bob", Track: 1}, expectedStatus: http.StatusBadRequest},
+		{name: "session ended", path: "/v1/sessions/test-session-join-stopped/rounds/0/join", body: types.JoinRoundRequest{PlayerID: "bob"}, expectedStatus: http.StatusConflict},
+		{name: "not found", path: "/v1/sessions/non-existent/rounds/0/join", body: types.JoinRoundRequest{PlayerID: "bob"}, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, response := playerRequest(router, "POST", tt.path, tt.body.PlayerID, tt.body)
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code == http.StatusCreated && (response.Status != types.EntryJoined || response.Round != 0 || response.SettledAt != nil) {
+				t.Errorf("Expected a joined entry in round 0, got %+v", response)
+			}
+		})
+	}
+}
+
+func TestHandler_JoinRoundRunning(t *testing.T) {
+	store := newMockStore()
+	handler := NewHandler(store, WithPlayerSecret(testPlayerSecret))
+	router := chi.NewRouter()
+	router.Mount("/", handler.Routes())
+
+	state := runningAtRound(t, store, "test-session-join-running", "")
+
+	// The running round is closed; the next one is open
+	path := "/v1/sessions/test-session-join-running/rounds/"
+	if w, _ := playerRequest(router, "POST", path+strconv.FormatInt(state.Round, 10)+"/join", "alice", types.JoinRoundRequest{PlayerID: "alice"}); w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 joining the running round, got %d", w.Code)
+	}
+	if w, _ := playerRequest(router, "POST", path+strconv.FormatInt(state.Round+1, 10)+"/join", "alice", types.JoinRoundRequest{PlayerID: "alice"}); w.Code != http.StatusCreated {
+		t.Errorf("Expected status 201 joining the next round, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandler_CashOut(t *testing.T) {
+	store := newMockStore()
+	handler := NewHandler(store, WithPlayerSecret(testPlayerSecret))
+	router := chi.NewRouter()
+	router.Mount("/", handler.Routes())
+
+	state := runningAtRound(t, store, "test-session-cashout", "")
+	for _, entry := range []types.Entry{
+		{PlayerID: "winner", Round: state.Round},
+		{PlayerID: "late", Round: state.Round - 1},
+		{PlayerID: "early", Round: state.Round + 1},
+	} {
+		entry.SessionID = "test-session-cashout"
+		entry.Status = types.EntryJoined
+		entry.JoinedAt = time.Now()
+		store.CreateEntry(context.Background(), &entry)
+	}
+	path := func(round int64) string {
+		return "/v1/sessions/test-session-cashout/rounds/" + strconv.FormatInt(round, 10) + "/cashout"
+	}
+
+	// Cashing out in the running round settles at the current step
+	w, response := playerRequest(router, "POST", path(state.Round), "winner", types.CashOutRequest{PlayerID: "winner"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if response.Status != types.EntryCashedOut || response.Step == nil || *response.Step != state.Step || *response.Value != state.Value || response.SettledAt == nil {
+		t.Errorf("Expected cash-out at step %d value %d, got %+v", state.Step, state.Value, response)
+	}
+
+	tests := []struct {
+		name           string
+		round          int64
+		playerID       string
+		expectedStatus int
+	}{
+		{name: "already cashed out", round: state.Round, playerID: "winner", expectedStatus: http.StatusConflict},
+		{name: "round broke", round: state.Round - 1, playerID: "late", expectedStatus: http.StatusConflict},
+		{name: "round not started", round: state.Round + 1, playerID: "early", expectedStatus: http.StatusConflict},
+		{name: "not joined", round: state.Round, playerID: "stranger", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, _ := playerRequest(router, "POST", path(tt.round), tt.playerID, types.CashOutRequest{PlayerID: tt.playerID})
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	// A cash-out after the break records the loss
+	entry, _ := store.GetEntry(context.Background(), "test-session-cashout", 0, state.Round-1, "late")
+	if entry.Status != types.EntryLost || entry.SettledAt == nil {
+		t.Errorf("Expected the late entry lost, got %+v", entry)
+	}
+	if entry, _ := store.GetEntry(context.Background(), "test-session-cashout", 0, state.Round+1, "early"); entry.Settled() {
+		t.Errorf("Expected the early entry still joined, got %+v", entry)
+	}
+}
+
+func TestHandler_CashOutInstantCrash(t *testing.T) {
+	store := newMockStore()
+	handler := NewHandler(store, WithPlayerSecret(testPlayerSecret))
+	router := chi.NewRouter()
+	router.Mount("/", handler.Routes())
+
+	// The only tick of a round that crashes at 1.00x
+	state := runningWhere(t, store, "test-session-instant", engine.ModeCrash, func(rule engine.Rule, seed int64, state engine.State) bool {
+		return rule.(engine.CrashRule).CrashPoint(seed, state.Round) <= 1
+	})
+	store.CreateEntry(context.Background(), &types.Entry{
+		SessionID: "test-session-instant",
+		Round:     state.Round,
+		PlayerID:  "alice",
+		Status:    types.EntryJoined,
+		JoinedAt:  time.Now(),
+	})
+
+	path := "/v1/sessions/test-session-instant/rounds/" + strconv.FormatInt(state.Round, 10) + "/cashout"
+	if w, _ := playerRequest(router, "POST", path, "alice", types.CashOutRequest{PlayerID: "alice"}); w.Code != http.StatusConflict {
+		t.Fatalf("Expected status 409, got %d: %s", w.Code, w.Body.String())
+	}
+	if entry, _ := store.GetEntry(context.Background(), "test-session-instant", 0, state.Round, "alice"); entry.Status != types.EntryLost {
+		t.Errorf("Expected the entry lost, got %+v", entry)
+	}
+}
+
+func TestHandler_GetEntry(t *testing.T) {
+	store := newMockStore()
+	handler := NewHandler(store, WithPlayerSecret(testPlayerSecret))
+	router := chi.NewRouter()
+	router.Mount("/", handler.Routes())
+
+	state := runningAtRound(t, store, "test-session-entry", "")
+	for _, round := range []int64{state.Round - 1, state.Round} {
+		store.CreateEntry(context.Background(), &types.Entry{
+			SessionID: "test-session-entry",
+			Round:     round,
+			PlayerID:  "alice",
+			Status:    types.EntryJoined,
+			JoinedAt:  time.Now(),
+		})
+	}
+	path := func(round int64) string {
+		return "/v1/sessions/test-session-entry/rounds/" + strconv.FormatInt(round, 10) + "/players/alice"
+	}
+
+	// An entry in the running round is still joined
+	w, response := playerRequest(router, "GET", path(state.Round), "alice", nil)
+	if w.Code != http.StatusOK || response.Status != types.EntryJoined {
+		t.Errorf("Expected a joined entry, got %d %+v", w.Code, response)
+	}
+
+	// One whose round broke is found lost
+	w, response = playerRequest(router, "GET", path(state.Round-1), "alice", nil)
+	if w.Code != http.StatusOK || response.Status != types.EntryLost || response.SettledAt == nil || response.Step != nil {
+		t.Errorf("Expected a lost entry, got %d %+v", w.Code, response)
+	}
+	if entry, _ := store.GetEntry(context.Background(), "test-session-entry", 0, state.Round-1, "alice"); entry.Status != types.EntryLost {
+		t.Errorf("Expected the loss to be stored, got %+v", entry)
+	}
+
+	if w, _ := playerRequest(router, "GET", path(state.Round+1), "alice", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
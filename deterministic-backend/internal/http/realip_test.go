@@ -0,0 +1,63 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	tests := []struct {
+		name     string
+		list     string
+		expected int
+		wantErr  bool
+	}{
+		{name: "empty", list: "", expected: 0},
+		{name: "ranges and addresses", list: "10.0.0.0/8, 192.168.1.10,::1", expected: 3},
+		{name: "invalid address", list: "10.0.0.300", wantErr: true},
+		{name: "invalid range", list: "10.0.0.0/33", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxies, err := ParseTrustedProxies(tt.list)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if len(proxies) != tt.expected {
+				t.Errorf("Expected %d proxies, got %d", tt.expected, len(proxies))
+			}
+		})
+	}
+}
+
+func TestTrustedRealIP(t *testing.T) {
+	proxies, _ := ParseTrustedProxies("10.0.0.0/8,192.168.1.10")
+	var remoteAddr string
+	handler := TrustedRealIP(proxies)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remoteAddr = r.RemoteAddr
+	}))
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		expected   string
+	}{
+		{name: "trusted range", remoteAddr: "10.1.2.3:4000", expected: "203.0.113.7"},
+		{name: "trusted address", remoteAddr: "192.168.1.10:4000", expected: "203.0.113.7"},
+		{name: "untrusted peer", remoteAddr: "198.51.100.1:4000", expected: "198.51.100.1:4000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", "203.0.113.7")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			if remoteAddr != tt.expected {
+				t.Errorf("Expected RemoteAddr %s, got %s", tt.expected, remoteAddr)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/engine"
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/store"
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/types"
+	"github.com/go-chi/chi/v5"
+)
+
+// StreamSessionEvents handles GET /v1/sessions/{id}/events
+//
+// It streams one track as Server-Sent Events, for clients that cannot use
+// WebSockets. Messages are the same as on /stream; ticks and states carry
+// their step as event ID. A client reconnecting with Last-Event-ID gets
+// the breaks it missed after that step, then the current state. Once the
+// session has ended and the client has seen its last step, reconnects get
+// 204 No Content, which tells EventSource to stop.
+func (h *Handler) StreamSessionEvents(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "id")
+	if sessionID == "" {
+		h.respondError(w, http.StatusBadRequest, "invalid session id", "session id is required")
+		return
+	}
+
+	track := 0
+	if v := r.URL.Query().Get("track"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 0 || parsed >= maxTracks {
+			h.respondError(w, http.StatusBadRequest, "invalid track", "track must be between 0 and 15")
+			return
+		}
+		track = parsed
+	}
+
+	resume := int64(-1)
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || parsed < 0 {
+			h.respondError(w, http.StatusBadRequest, "invalid Last-Event-ID", "Last-Event-ID must be a step")
+			return
+		}
+		resume = parsed
+	}
+
+	// Get session from store
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	now := time.Now()
+	session, err := h.getSession(ctx, sessionID, now)
+	cancel()
+	if err != nil {
+		if err == store.ErrSessionNotFound {
+			h.respondError(w, http.StatusNotFound, "session not found", err.Error())
+			return
+		}
+		h.respondError(w, http.StatusInternalServerError, "failed to get session", err.Error())
+		return
+	}
+
+	if track >= sessionTracks(session) {
+		h.respondError(w, http.StatusBadRequest, "invalid track", "session has no such track")
+		return
+	}
+
+	seed, err := engineSeed(session)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "invalid seed format", err.Error())
+		return
+	}
+	rule, err := sessionRule(session)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "invalid session mode", err.Error())
+		return
+	}
+
+	// Nothing left to send
+	current := sessionStates(session, rule, seed, now)[track].Step
+	if session.Ended() && resume >= 0 && resume >= current {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	// No client has seen a step after the current one. Clamped, a bogus
+	// Last-Event-ID cannot make the stream compute the steps up to it
+	if resume > current {
+		resume = current
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // Disable nginx response buffering
+	w.WriteHeader(http.StatusOK)
+
+	stream := newEventStream(r.Context(), w)
+	defer stream.cancel()
+	stream.flush()
+
+	tracks := streamTracks(session, seed)[track : track+1]
+	tracks[0].resume = resume
+	h.runStream(stream, session, rule, seed, tracks)
+}
+
+// eventStream is one Server-Sent Events client of a session stream.
+//
+// Messages are written directly from the stream's goroutine, so a slow
+// client slows the stream down instead of missing messages; a client that
+// takes longer than streamWriteWait for a write is dropped.
+type eventStream struct {
+	ctx        context.Context
+	cancel     context.CancelFunc
+	w          http.ResponseWriter
+	controller *http.ResponseController
+	lastWrite  time.Time
+}
+
+// newEventStream creates a stream writing to w until ctx is done.
+func newEventStream(ctx context.Context, w http.ResponseWriter) *eventStream {
+	ctx, cancel := context.WithCancel(ctx)
+	return &eventStream{
+		ctx:        ctx,
+		cancel:     cancel,
+		w:          w,
+		controller: http.NewResponseController(w),
+		lastWrite:  time.Now(),
+	}
+}
+
+// send writes a message as an event named after its type.
+func (s *eventStream) send(message types.StreamMessage) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return
+	}
+	var event []byte
+	if id, ok := eventID(message); ok {
+		event = fmt.Appendf(event, "id: %d\n", id)
+	}
+	event = fmt.Appendf(event, "event: %s\ndata: %s\n\n", message.Type, data)
+	s.write(event)
+}
+
+// sendFinal writes a message; writes never drop messages.
+func (s *eventStream) sendFinal(message types.StreamMessage) {
+	s.send(message)
+}
+
+// gone returns a channel closed when the client is gone.
+func (s *eventStream) gone() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+// resync reports false: event streams never drop messages.
+func (s *eventStream) resync() bool {
+	return false
+}
+
+// idle writes a comment after streamKeepAlive without events, so proxies
+// do not close a paused session's stream.
+func (s *eventStream) idle(now time.Time) {
+	if now.Sub(s.lastWrite) >= streamKeepAlive {
+		s.write([]byte(": keep-alive\n\n"))
+	}
+}
+
+// write writes and flushes raw event data, dropping the client on error.
+func (s *eventStream) write(data []byte) {
+	if s.ctx.Err() != nil {
+		return
+	}
+	s.controller.SetWriteDeadline(time.Now().Add(streamWriteWait))
+	if _, err := s.w.Write(data); err != nil {
+		s.cancel()
+		return
+	}
+	s.flush()
+	s.lastWrite = time.Now()
+}
+
+// flush sends buffered data to the client, dropping it on error.
+func (s *eventStream) flush() {
+	if err := s.controller.Flush(); err != nil {
+		s.cancel()
+	}
+}
+
+// eventID returns the step a message completes, if any. Breaks come
+// before their step's tick and carry no ID, so a client resuming after a
+// break it saw gets it again rather than missing the tick. States in the
+// lobby and before start carry none either: step 0 is still to come. End
+// messages always do, so reconnecting after the end gets 204.
+func eventID(message types.StreamMessage) (int64, bool) {
+	switch {
+	case message.Type == "break":
+		return 0, false
+	case message.Type == "tick" || message.Type == "end":
+		return message.Step, true
+	case message.GamePhase == "" || message.GamePhase == string(engine.GamePhaseLobby):
+		return 0, false
+	}
+	return message.Step, true
+}
@@ -0,0 +1,266 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/engine"
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/types"
+)
+
+// sseEvent is one parsed Server-Sent Event.
+type sseEvent struct {
+	id      string
+	message types.StreamMessage
+}
+
+// openEvents requests a session's event stream.
+func openEvents(t *testing.T, server *httptest.Server, path, lastEventID string) *http.Response {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, "GET", server.URL+path, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// readEvent reads the next event, skipping comments. ok is false at the
+// end of the stream.
+func readEvent(t *testing.T, reader *bufio.Reader) (event sseEvent, ok bool) {
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return event, false
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			if event.message.Type != "" {
+				return event, true
+			}
+		case strings.HasPrefix(line, "id: "):
+			event.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event.message); err != nil {
+				t.Fatalf("Failed to decode event data %q: %v", line, err)
+			}
+		}
+	}
+}
+
+func TestHandler_StreamSessionEvents(t *testing.T) {
+	store := newMockStore()
+	server := newStreamServer(t, NewHandler(store))
+
+	store.CreateSession(context.Background(), &types.Session{
+		ID:        "test-session-events",
+		Seed:      "12345",
+		StartAt:   time.Now().Add(-time.Minute),
+		TickMs:    50,
+		Status:    "running",
+		CreatedAt: time.Now(),
+	})
+
+	resp := openEvents(t, server, "/v1/sessions/test-session-events/events", "")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Expected an event stream, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	reader := bufio.NewReader(resp.Body)
+
+	state, ok := readEvent(t, reader)
+	if !ok || state.message.Type != "state" || state.id == "" || state.message.Step < 1000 {
+		t.Fatalf("Expected the running state around step 1200, got %+v", state)
+	}
+
+	// Ticks carry their step as ID; breaks carry none
+	next := state.message.Step + 1
+	for ticks := 0; ticks < 10; {
+		event, ok := readEvent(t, reader)
+		if !ok {
+			t.Fatal("Stream ended early")
+		}
+		switch event.message.Type {
+		case "tick":
+			if event.message.Step != next || event.id != strconv.FormatInt(event.message.Step, 10) {
+				t.Fatalf("Expected tick with ID %d, got %+v", next, event)
+			}
+			next++
+			ticks++
+		case "break":
+			if event.message.Step != next || event.id != "" {
+				t.Fatalf("Expected break without ID at step %d, got %+v", next, event)
+			}
+		default:
+			t.Fatalf("Unexpected event %+v", event)
+		}
+	}
+}
+
+func TestHandler_StreamSessionEventsResume(t *testing.T) {
+	store := newMockStore()
+	server := newStreamServer(t, NewHandler(store))
+
+	session := &types.Session{
+		ID:        "test-session-events-resume",
+		Seed:      "12345",
+		StartAt:   time.Now().Add(-time.Minute),
+		TickMs:    50,
+		Status:    "running",
+		CreatedAt: time.Now(),
+	}
+	store.CreateSession(context.Background(), session)
+
+	resp := openEvents(t, server, "/v1/sessions/test-session-events-resume/events", "100")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	reader := bufio.NewReader(resp.Body)
+
+	// The breaks missed since step 100 come first, then the current state
+	var breaks []int64
+	var state sseEvent
+	for {
+		event, ok := readEvent(t, reader)
+		if !ok {
+			t.Fatal("Stream ended early")
+		}
+		if event.message.Type != "break" {
+			state = event
+			break
+		}
+		breaks = append(breaks, event.message.Step)
+	}
+	if state.message.Type != "state" {
+		t.Fatalf("Expected the state after the missed breaks, got %+v", state)
+	}
+
+	seed, _ := engineSeed(session)
+	rule, _ := sessionRule(session)
+	var expected []int64
+	missed := engine.NewEventIterator(rule, seed, 101, state.message.Step, engine.EventBreak)
+	for event, ok := missed.Next(); ok; event, ok = missed.Next() {
+		expected = append(expected, event.Step)
+	}
+	if len(expected) == 0 {
+		t.Fatal("Expected breaks between step 100 and the current step")
+	}
+	if len(breaks) != len(expected) {
+		t.Fatalf("Expected breaks %v, got %v", expected, breaks)
+	}
+	for i := range expected {
+		if breaks[i] != expected[i] {
+			t.Fatalf("Expected breaks %v, got %v", expected, breaks)
+		}
+	}
+}
+
+func TestHandler_StreamSessionEventsEnded(t *testing.T) {
+	store := newMockStore()
+	server := newStreamServer(t, NewHandler(store))
+
+	now := time.Now()
+	stoppedAt := now.Add(-5 * time.Second)
+	store.CreateSession(context.Background(), &types.Session{
+		ID:        "test-session-events-stopped",
+		Seed:      "12345",
+		StartAt:   now.Add(-10 * time.Second),
+		TickMs:    100,
+		Status:    "stopped",
+		StoppedAt: &stoppedAt,
+		CreatedAt: time.Now(),
+	})
+
+	resp := openEvents(t, server, "/v1/sessions/test-session-events-stopped/events", "")
+	reader := bufio.NewReader(resp.Body)
+	if event, _ := readEvent(t, reader); event.message.Type != "state" || event.message.Step != 50 {
+		t.Errorf("Expected the frozen state at step 50, got %+v", event)
+	}
+	if event, _ := readEvent(t, reader); event.message.Type != "end" || event.id != "50" {
+		t.Errorf("Expected end with ID 50, got %+v", event)
+	}
+	if event, ok := readEvent(t, reader); ok {
+		t.Errorf("Expected the stream to end, got %+v", event)
+	}
+
+	// Reconnecting after the end tells the client to stop
+	resp = openEvents(t, server, "/v1/sessions/test-session-events-stopped/events", "50")
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandler_StreamSessionEventsResumeAhead(t *testing.T) {
+	store := newMockStore()
+	server := newStreamServer(t, NewHandler(store))
+
+	store.CreateSession(context.Background(), &types.Session{
+		ID:        "test-session-events-ahead",
+		Seed:      "12345",
+		StartAt:   time.Now().Add(-10 * time.Second),
+		TickMs:    100,
+		Status:    "running",
+		CreatedAt: time.Now(),
+	})
+
+	// A Last-Event-ID far past the current step resumes from the current
+	// state instead of computing the steps up to it
+	resp := openEvents(t, server, "/v1/sessions/test-session-events-ahead/events", strconv.FormatInt(1<<40, 10))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	event, _ := readEvent(t, bufio.NewReader(resp.Body))
+	if event.message.Type != "state" || event.message.Step < 100 || event.message.Step > 200 {
+		t.Errorf("Expected the current state, got %+v", event)
+	}
+}
+
+func TestHandler_StreamSessionEventsInvalid(t *testing.T) {
+	store := newMockStore()
+	server := newStreamServer(t, NewHandler(store))
+
+	store.CreateSession(context.Background(), &types.Session{
+		ID:        "test-session-events-invalid",
+		Seed:      "12345",
+		StartAt:   time.Now(),
+		TickMs:    100,
+		Status:    "running",
+		CreatedAt: time.Now(),
+	})
+
+	tests := []struct {
+		name           string
+		path           string
+		lastEventID    string
+		expectedStatus int
+	}{
+		{name: "not found", path: "/v1/sessions/non-existent/events", expectedStatus: http.StatusNotFound},
+		{name: "invalid Last-Event-ID", path: "/v1/sessions/test-session-events-invalid/events", lastEventID: "abc", expectedStatus: http.StatusBadRequest},
+		{name: "negative Last-Event-ID", path: "/v1/sessions/test-session-events-invalid/events", lastEventID: "-1", expectedStatus: http.StatusBadRequest},
+		{name: "no such track", path: "/v1/sessions/test-session-events-invalid/events?track=1", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := openEvents(t, server, tt.path, tt.lastEventID)
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+		})
+	}
+}
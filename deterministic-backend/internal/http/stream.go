@@ -0,0 +1,429 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/engine"
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/store"
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/types"
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/websocket"
+)
+
+// Stream settings
+const (
+	streamWriteWait    = 10 * time.Second        // Max time to write a message
+	streamPongWait     = 60 * time.Second        // Max time between pongs from the client
+	streamPingPeriod   = streamPongWait * 9 / 10 // Ping interval, shorter than streamPongWait
+	streamReloadPeriod = 500 * time.Millisecond  // Session reloads, to pick up stop and pause
+	streamMinInterval  = 50 * time.Millisecond   // Fastest clock polling; faster ticks are batched
+	streamBuffer       = 256                     // Messages queued per client before it counts as slow
+	streamKeepAlive    = 15 * time.Second        // Longest silence on an event stream, for idle proxies
+)
+
+// streamUpgrader upgrades stream requests. Any origin may connect: the
+// stream is as public as GET /state.
+var streamUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// StreamSession handles GET /v1/sessions/{id}/stream
+//
+// It upgrades to WebSocket and pushes the state of every track on every
+// tick and break until the session ends, then closes normally. Stop and
+// pause made through other requests are picked up within
+// streamReloadPeriod.
+func (h *Handler) StreamSession(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "id")
+	if sessionID == "" {
+		h.respondError(w, http.StatusBadRequest, "invalid session id", "session id is required")
+		return
+	}
+
+	// Get session from store
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	session, err := h.getSession(ctx, sessionID, time.Now())
+	cancel()
+	if err != nil {
+		if err == store.ErrSessionNotFound {
+			h.respondError(w, http.StatusNotFound, "session not found", err.Error())
+			return
+		}
+		h.respondError(w, http.StatusInternalServerError, "failed to get session", err.Error())
+		return
+	}
+
+	seed, err := engineSeed(session)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "invalid seed format", err.Error())
+		return
+	}
+	rule, err := sessionRule(session)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "invalid session mode", err.Error())
+		return
+	}
+
+	conn, err := streamUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // The upgrader already replied
+	}
+
+	stream := newSessionStream(conn, streamBuffer)
+	go stream.readLoop()
+	go stream.writeLoop()
+	stream.close(h.runStream(stream, session, rule, seed, streamTracks(session, seed)))
+}
+
+// streamSink delivers stream messages to one client.
+type streamSink interface {
+	send(message types.StreamMessage)      // Deliver a break, tick or state
+	sendFinal(message types.StreamMessage) // Deliver an end message, waiting for room
+	gone() <-chan struct{}                 // Closed when the client is gone
+	resync() bool                          // Whether the client missed messages and needs the current state
+	idle(now time.Time)                    // Called on every poll, to keep quiet connections open
+}
+
+// streamTrack is the progress of a stream on one track.
+type streamTrack struct {
+	index     int
+	seed      int64
+	events    *engine.EventIterator // Breaks and ticks not sent yet
+	phase     engine.GamePhase      // Game phase last sent
+	ticksLeft int64                 // Lobby or intermission ticks left last sent
+	resume    int64                 // Last step the client has seen, or -1
+}
+
+// streamTracks returns the progress of a new stream on every track.
+func streamTracks(session *types.Session, seed int64) []*streamTrack {
+	tracks := make([]*streamTrack, sessionTracks(session))
+	for i := range tracks {
+		tracks[i] = &streamTrack{index: i, seed: engine.TrackSeed(seed, i), resume: -1}
+	}
+	return tracks
+}
+
+// runStream pushes the session's events on the given tracks until it ends
+// or the client goes away, and returns the reason to close the stream with.
+func (h *Handler) runStream(sink streamSink, session *types.Session, rule engine.Rule, seed int64, tracks []*streamTrack) string {
+	now := time.Now()
+	h.sendStates(sink, tracks, session, rule, seed, now, false)
+	phase := session.Phase(now)
+
+	ticker := time.NewTicker(streamInterval(session))
+	defer ticker.Stop()
+	reloadAt := now.Add(streamReloadPeriod)
+
+	for !session.Ended() {
+		select {
+		case <-sink.gone():
+			return ""
+		case now = <-ticker.C:
+		}
+
+		// Pick up stop, pause and resume made through other requests
+		if !now.Before(reloadAt) {
+			reloadAt = now.Add(streamReloadPeriod)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			reloaded, err := h.getSession(ctx, session.ID, now)
+			cancel()
+			if err == store.ErrSessionNotFound {
+				return "session not found"
+			}
+			if err == nil {
+				session = reloaded
+			}
+		}
+
+		// A slow client that drained its queue gets the current state
+		// instead of the ticks it missed
+		if sink.resync() {
+			h.sendStates(sink, tracks, session, rule, seed, now, true)
+			phase = session.Phase(now)
+			continue
+		}
+
+		at := now
+		if session.Ended() && session.StoppedAt != nil && session.StoppedAt.Before(at) {
+			at = *session.StoppedAt
+		}
+		clock := sessionClock(session)
+		for _, track := range tracks {
+			pushTrack(sink, track, session, clock, rule, at, now)
+		}
+
+		if current := session.Phase(now); current != phase && !session.Ended() {
+			phase = current
+			h.sendStates(sink, tracks, session, rule, seed, now, false)
+		}
+		sink.idle(now)
+	}
+
+	states := sessionStates(session, rule, seed, now)
+	for _, track := range tracks {
+		sink.sendFinal(stateMessage("end", track.index, states[track.index], session, rule, track.seed, now))
+	}
+	return "session " + session.Status
+}
+
+// pushTrack sends the breaks and ticks of a track up to the given time, and
+// the countdown of lobby and intermission ticks, which hold the step.
+func pushTrack(sink streamSink, track *streamTrack, session *types.Session, clock engine.Clock, rule engine.Rule, at, now time.Time) {
+	position := clock.PositionAt(rule, track.seed, at)
+	if clock.Started(at) && position.Phase != engine.GamePhaseLobby {
+		track.events.ExtendTo(position.Step)
+	}
+	for event, ok := track.events.Next(); ok; event, ok = track.events.Next() {
+		sink.send(eventMessage(track.index, event, rule, track.seed))
+	}
+
+	if position.Phase != engine.GamePhaseRunning && (position.Phase != track.phase || position.TicksLeft != track.ticksLeft) {
+		state := engine.ClockStateAt(rule, track.seed, clock, at)
+		sink.send(stateMessage("state", track.index, state, session, rule, track.seed, now))
+	}
+	track.phase, track.ticksLeft = position.Phase, position.TicksLeft
+}
+
+// sendStates sends the state of every track and restarts their events
+// after it. A resuming track first gets the breaks it missed.
+func (h *Handler) sendStates(sink streamSink, tracks []*streamTrack, session *types.Session, rule engine.Rule, seed int64, now time.Time, resync bool) {
+	states := sessionStates(session, rule, seed, now)
+	for _, track := range tracks {
+		state := states[track.index]
+
+		// Before start and in the lobby step 0 is still to come
+		next := int64(0)
+		if state.Phase != "" && state.Phase != engine.GamePhaseLobby {
+			next = state.Step + 1
+		}
+
+		if track.resume >= 0 && track.resume < next-1 {
+			missed := engine.NewEventIterator(rule, track.seed, track.resume+1, next-1, engine.EventBreak)
+			for event, ok := missed.Next(); ok; event, ok = missed.Next() {
+				sink.send(eventMessage(track.index, event, rule, track.seed))
+			}
+			track.resume = -1
+		}
+
+		message := stateMessage("state", track.index, state, session, rule, track.seed, now)
+		message.Resync = resync
+		sink.send(message)
+
+		track.events = engine.NewEventIterator(rule, track.seed, next, next-1, engine.EventBreak, engine.EventTick)
+		track.phase, track.ticksLeft = state.Phase, state.TicksLeft
+	}
+}
+
+// stateMessage builds a "state" or "end" message.
+func stateMessage(kind string, track int, state engine.State, session *types.Session, rule engine.Rule, seed int64, now time.Time) types.StreamMessage {
+	return types.StreamMessage{
+		Type:      kind,
+		Track:     track,
+		Step:      state.Step,
+		Value:     state.Value,
+		Round:     state.Round,
+		Broken:    state.Broken,
+		Status:    session.Status,
+		Phase:     session.Phase(now),
+		GamePhase: string(state.Phase),
+		TicksLeft: state.TicksLeft,
+		Outcome:   rule.Outcome(seed, state),
+	}
+}
+
+// eventMessage builds a "break" or "tick" message.
+func eventMessage(track int, event engine.Event, rule engine.Rule, seed int64) types.StreamMessage {
+	if event.Kind == engine.EventBreak {
+		return types.StreamMessage{Type: "break", Track: track, Step: event.Step, Round: event.Round}
+	}
+
+	state := engine.State{Step: event.Step, Value: event.Value, Round: event.Round, Broken: event.Broken}
+	return types.StreamMessage{
+		Type:      "tick",
+		Track:     track,
+		Step:      event.Step,
+		Value:     event.Value,
+		Round:     event.Round,
+		Broken:    event.Broken,
+		GamePhase: string(engine.GamePhaseRunning),
+		Outcome:   rule.Outcome(seed, state),
+	}
+}
+
+// streamInterval returns how often a stream polls the clock: every tick of
+// the fastest tick rate, but no faster than streamMinInterval.
+func streamInterval(session *types.Session) time.Duration {
+	tickMs := int64(session.TickMs)
+	if schedule := session.TickSchedule; schedule != nil {
+		for _, t := range schedule.RoundTicks {
+			if t < tickMs {
+				tickMs = t
+			}
+		}
+		for _, segment := range schedule.Segments {
+			if segment.TickMs < tickMs {
+				tickMs = segment.TickMs
+			}
+		}
+	}
+
+	interval := time.Duration(tickMs) * time.Millisecond
+	if interval < streamMinInterval {
+		return streamMinInterval
+	}
+	return interval
+}
+
+// sessionStream is one WebSocket client of a session stream.
+//
+// Messages are queued for a writer goroutine, which also sends pings; a
+// reader goroutine handles pongs and notices when the client goes away.
+// The queue is bounded: a client that falls behind misses messages until
+// it catches up and is then resynced with the current state.
+type sessionStream struct {
+	conn   *websocket.Conn
+	out    chan []byte   // Queued messages
+	done   chan struct{} // Closed when the client is gone
+	closed chan struct{} // Closed when the writer has finished
+	once   sync.Once
+	lagged bool   // Messages were dropped since the last resync
+	reason string // Close reason, set before out is closed
+}
+
+// newSessionStream creates a stream queuing up to buffer messages.
+func newSessionStream(conn *websocket.Conn, buffer int) *sessionStream {
+	return &sessionStream{
+		conn:   conn,
+		out:    make(chan []byte, buffer),
+		done:   make(chan struct{}),
+		closed: make(chan struct{}),
+	}
+}
+
+// send queues a message without blocking. When the queue is full the
+// message is dropped and the stream marked as lagged.
+func (s *sessionStream) send(message types.StreamMessage) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return
+	}
+	select {
+	case s.out <- data:
+	default:
+		s.lagged = true
+	}
+}
+
+// sendFinal queues a message, waiting up to streamWriteWait for room.
+func (s *sessionStream) sendFinal(message types.StreamMessage) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return
+	}
+	timer := time.NewTimer(streamWriteWait)
+	defer timer.Stop()
+	select {
+	case s.out <- data:
+	case <-s.done:
+	case <-timer.C:
+	}
+}
+
+// canResync reports whether the queue drained enough to resync.
+func (s *sessionStream) canResync() bool {
+	return len(s.out) < cap(s.out)/2
+}
+
+// gone returns a channel closed when the client is gone.
+func (s *sessionStream) gone() <-chan struct{} {
+	return s.done
+}
+
+// resync reports whether the stream dropped messages and its queue
+// drained enough to take the current state.
+func (s *sessionStream) resync() bool {
+	if !s.lagged || !s.canResync() {
+		return false
+	}
+	s.lagged = false
+	return true
+}
+
+// idle does nothing: the writer pings on its own.
+func (s *sessionStream) idle(now time.Time) {}
+
+// leave marks the client as gone.
+func (s *sessionStream) leave() {
+	s.once.Do(func() { close(s.done) })
+}
+
+// readLoop discards client messages and extends the read deadline on
+// every pong, so a client that stops answering pings is dropped.
+func (s *sessionStream) readLoop() {
+	defer s.leave()
+	s.conn.SetReadLimit(512)
+	s.conn.SetReadDeadline(time.Now().Add(streamPongWait))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(streamPongWait))
+	})
+	for {
+		if _, _, err := s.conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// writeLoop writes queued messages and pings. Once the queue is closed it
+// sends a close frame with the stream's reason.
+func (s *sessionStream) writeLoop() {
+	defer close(s.closed)
+	ping := time.NewTicker(streamPingPeriod)
+	defer ping.Stop()
+
+	for {
+		select {
+		case data, ok := <-s.out:
+			s.conn.SetWriteDeadline(time.Now().Add(streamWriteWait))
+			if !ok {
+				s.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, s.reason))
+				return
+			}
+			if err := s.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				s.leave()
+				return
+			}
+		case <-ping.C:
+			s.conn.SetWriteDeadline(time.Now().Add(streamWriteWait))
+			if err := s.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				s.leave()
+				return
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// close flushes the queue, sends a close frame with the reason and closes
+// the connection once the client answered or streamWriteWait elapsed.
+func (s *sessionStream) close(reason string) {
+	s.reason = reason
+	close(s.out)
+
+	timer := time.NewTimer(streamWriteWait)
+	defer timer.Stop()
+	select {
+	case <-s.closed:
+		// Wait for the client to answer the close frame
+		select {
+		case <-s.done:
+		case <-timer.C:
+		}
+	case <-timer.C:
+	}
+	s.conn.Close()
+}
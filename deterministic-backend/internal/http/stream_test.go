@@ -0,0 +1,177 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/types"
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/websocket"
+)
+
+// newStreamServer serves the handler's routes over a real listener.
+func newStreamServer(t *testing.T, handler *Handler) *httptest.Server {
+	router := chi.NewRouter()
+	router.Mount("/", handler.Routes())
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// dialStream connects to a session stream.
+func dialStream(t *testing.T, server *httptest.Server, sessionID string) *websocket.Conn {
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/v1/sessions/" + sessionID + "/stream"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readMessage reads the next stream message.
+func readMessage(t *testing.T, conn *websocket.Conn) types.StreamMessage {
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var message types.StreamMessage
+	if err := conn.ReadJSON(&message); err != nil {
+		t.Fatalf("Failed to read message: %v", err)
+	}
+	return message
+}
+
+func TestHandler_StreamSession(t *testing.T) {
+	store := newMockStore()
+	handler := NewHandler(store)
+	server := newStreamServer(t, handler)
+
+	store.CreateSession(context.Background(), &types.Session{
+		ID:        "test-session-stream",
+		Seed:      "12345",
+		StartAt:   time.Now().Add(-time.Minute),
+		TickMs:    50,
+		Status:    "running",
+		CreatedAt: time.Now(),
+	})
+
+	conn := dialStream(t, server, "test-session-stream")
+
+	// The current state first
+	state := readMessage(t, conn)
+	if state.Type != "state" || state.Status != "running" || state.Step < 1000 {
+		t.Fatalf("Expected the running state around step 1200, got %+v", state)
+	}
+
+	// Then every step in order; a break comes right before its break step
+	next := state.Step + 1
+	for ticks := 0; ticks < 10; {
+		message := readMessage(t, conn)
+		switch message.Type {
+		case "tick":
+			if message.Step != next {
+				t.Fatalf("Expected tick at step %d, got %+v", next, message)
+			}
+			next++
+			ticks++
+		case "break":
+			if message.Step != next {
+				t.Fatalf("Expected break at step %d, got %+v", next, message)
+			}
+		default:
+			t.Fatalf("Unexpected message %+v", message)
+		}
+	}
+
+	// Stopping ends the stream with the final state and a normal closure
+	req := httptest.NewRequest("POST", "/v1/sessions/test-session-stream/stop", nil)
+	w := httptest.NewRecorder()
+	router := chi.NewRouter()
+	router.Mount("/", handler.Routes())
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	for {
+		message := readMessage(t, conn)
+		if message.Type != "end" {
+			continue
+		}
+		if message.Status != "stopped" {
+			t.Errorf("Expected status stopped, got %+v", message)
+		}
+		break
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		t.Errorf("Expected normal closure, got %v", err)
+	}
+}
+
+func TestHandler_StreamSessionEnded(t *testing.T) {
+	store := newMockStore()
+	server := newStreamServer(t, NewHandler(store))
+
+	now := time.Now()
+	stoppedAt := now.Add(-5 * time.Second)
+	store.CreateSession(context.Background(), &types.Session{
+		ID:        "test-session-stream-stopped",
+		Seed:      "12345",
+		StartAt:   now.Add(-10 * time.Second),
+		TickMs:    100,
+		Status:    "stopped",
+		StoppedAt: &stoppedAt,
+		CreatedAt: time.Now(),
+	})
+
+	conn := dialStream(t, server, "test-session-stream-stopped")
+
+	if message := readMessage(t, conn); message.Type != "state" || message.Step != 50 {
+		t.Errorf("Expected the frozen state at step 50, got %+v", message)
+	}
+	if message := readMessage(t, conn); message.Type != "end" || message.Step != 50 || message.Status != "stopped" {
+		t.Errorf("Expected end at step 50, got %+v", message)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		t.Errorf("Expected normal closure, got %v", err)
+	}
+}
+
+func TestHandler_StreamSessionNotFound(t *testing.T) {
+	handler := NewHandler(newMockStore())
+
+	req := httptest.NewRequest("GET", "/v1/sessions/non-existent/stream", nil)
+	w := httptest.NewRecorder()
+	router := chi.NewRouter()
+	router.Mount("/", handler.Routes())
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+func TestSessionStream_DropsWhenFull(t *testing.T) {
+	stream := newSessionStream(nil, 4)
+
+	for i := 0; i < 6; i++ {
+		stream.send(types.StreamMessage{Type: "tick", Step: int64(i)})
+	}
+	if !stream.lagged || len(stream.out) != 4 {
+		t.Fatalf("Expected 4 queued messages and a lagged stream, got %d queued, lagged %v", len(stream.out), stream.lagged)
+	}
+	if stream.canResync() {
+		t.Error("Expected no resync while the queue is full")
+	}
+
+	<-stream.out
+	<-stream.out
+	<-stream.out
+	if !stream.canResync() {
+		t.Error("Expected a resync once the queue drained")
+	}
+}
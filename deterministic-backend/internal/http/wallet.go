@@ -0,0 +1,356 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/engine"
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/store"
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/types"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// maxTransactionIDLength bounds client-chosen transaction IDs
+	maxTransactionIDLength = 128
+
+	// defaultWalletLimit and maxWalletLimit bound transactions per wallet response
+	defaultWalletLimit = 20
+	maxWalletLimit     = 100
+)
+
+// GetWallet handles GET /v1/wallets/{player}
+//
+// Requires the player's X-Player-Token or the admin token.
+func (h *Handler) GetWallet(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if h.ledger == nil {
+		h.respondError(w, http.StatusNotImplemented, "wallets disabled", "no wallet ledger is configured")
+		return
+	}
+
+	playerID := chi.URLParam(r, "player")
+	if !h.authorizePlayer(w, r, playerID) {
+		return
+	}
+	limit := defaultWalletLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 || parsed > maxWalletLimit {
+			h.respondError(w, http.StatusBadRequest, "invalid limit", "limit must be between 1 and 100")
+			return
+		}
+		limit = parsed
+	}
+
+	account := types.PlayerAccount(playerID)
+	balance, err := h.ledger.Balance(ctx, account)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "failed to get balance", err.Error())
+		return
+	}
+	transactions, err := h.ledger.Transactions(ctx, account, limit)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "failed to get transactions", err.Error())
+		return
+	}
+
+	response := types.WalletResponse{
+		PlayerID:     playerID,
+		Balance:      balance,
+		Transactions: make([]types.TransactionResponse, 0, len(transactions)),
+	}
+	for _, tx := range transactions {
+		response.Transactions = append(response.Transactions, transactionResponse(tx))
+	}
+	h.respondJSON(w, http.StatusOK, response)
+}
+
+// Deposit handles POST /v1/wallets/{player}/deposit
+//
+// Admin only. Credits the player from the external account.
+func (h *Handler) Deposit(w http.ResponseWriter, r *http.Request) {
+	h.transfer(w, r, types.TransactionDeposit)
+}
+
+// Withdraw handles POST /v1/wallets/{player}/withdraw
+//
+// Admin only. Debits the player to the external account.
+func (h *Handler) Withdraw(w http.ResponseWriter, r *http.Request) {
+	h.transfer(w, r, types.TransactionWithdrawal)
+}
+
+// transfer moves money between a player and the external account. The
+// ledger transaction ID is the kind, player and client transaction ID, so
+// a retried request is applied once.
+func (h *Handler) transfer(w http.ResponseWriter, r *http.Request, kind string) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if !h.isAdmin(r) {
+		h.respondError(w, http.StatusForbidden, "forbidden", fmt.Sprintf("a %s requires an admin token", kind))
+		return
+	}
+	if h.ledger == nil {
+		h.respondError(w, http.StatusNotImplemented, "wallets disabled", "no wallet ledger is configured")
+		return
+	}
+
+	playerID := chi.URLParam(r, "player")
+	if len(playerID) > maxPlayerIDLength {
+		h.respondError(w, http.StatusBadRequest, "invalid player_id", "player_id must be between 1 and 128 characters")
+		return
+	}
+
+	var req types.WalletTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	if req.TransactionID == "" || len(req.TransactionID) > maxTransactionIDLength {
+		h.respondError(w, http.StatusBadRequest, "invalid transaction_id", "transaction_id must be between 1 and 128 characters")
+		return
+	}
+	if req.Amount <= 0 {
+		h.respondError(w, http.StatusBadRequest, "invalid amount", "amount must be greater than 0")
+		return
+	}
+
+	from, to := types.AccountExternal, types.PlayerAccount(playerID)
+	if kind == types.TransactionWithdrawal {
+		from, to = to, from
+	}
+	tx, err := h.ledger.Post(ctx, &types.Transaction{
+		ID:        transferTransactionID(kind, playerID, req.TransactionID),
+		Kind:      kind,
+		Postings:  []types.Posting{{Account: from, Amount: -req.Amount}, {Account: to, Amount: req.Amount}},
+		PlayerID:  playerID,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		switch err {
+		case store.ErrInsufficientFunds:
+			h.respondError(w, http.StatusConflict, "insufficient funds", err.Error())
+		case store.ErrTransactionConflict:
+			h.respondError(w, http.StatusConflict, "transaction conflict", err.Error())
+		default:
+			h.respondError(w, http.StatusInternalServerError, "failed to post transaction", err.Error())
+		}
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, transactionResponse(tx))
+}
+
+// ReconcileRound handles POST /v1/sessions/{id}/rounds/{round}/reconcile
+//
+// Admin only. Checks every entry of a round against the deterministic
+// timeline and the ledger: a cash-out must lie in the round before its
+// break and be paid the rule's payout at its step, a lost entry is paid
+// nothing and a voided one has its stake refunded. Entries that can no
+// longer cash out are settled first. Missing payouts and refunds are
+// posted; every other discrepancy is reported.
+func (h *Handler) ReconcileRound(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if !h.isAdmin(r) {
+		h.respondError(w, http.StatusForbidden, "forbidden", "reconciliation requires an admin token")
+		return
+	}
+	if h.ledger == nil {
+		h.respondError(w, http.StatusNotImplemented, "wallets disabled", "no wallet ledger is configured")
+		return
+	}
+
+	sessionID := chi.URLParam(r, "id")
+	round, err := strconv.ParseInt(chi.URLParam(r, "round"), 10, 64)
+	if err != nil || round < 0 {
+		h.respondError(w, http.StatusBadRequest, "invalid round", "round must be a non-negative integer")
+		return
+	}
+
+	track := 0
+	if v := r.URL.Query().Get("track"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 0 || parsed >= maxTracks {
+			h.respondError(w, http.StatusBadRequest, "invalid track", "track must be between 0 and 15")
+			return
+		}
+		track = parsed
+	}
+
+	now := time.Now()
+	session, rule, seed, ok := h.playerSession(w, ctx, sessionID, track, now)
+	if !ok {
+		return
+	}
+	entries, err := h.store.ListEntries(ctx, session.ID, track, round)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "failed to list entries", err.Error())
+		return
+	}
+
+	response := types.ReconcileResponse{
+		SessionID: session.ID,
+		Track:     track,
+		Round:     round,
+		Entries:   len(entries),
+		Repaired:  []string{},
+		Issues:    []types.ReconcileIssue{},
+	}
+	state := sessionStates(session, rule, seed, now)[track]
+	trackSeed := engine.TrackSeed(seed, track)
+	for _, entry := range entries {
+		if status := closedStatus(session, state, round); !entry.Settled() && status != "" {
+			if entry, err = h.closeEntry(ctx, entry, status, now); err != nil {
+				h.respondError(w, http.StatusInternalServerError, "failed to settle entry", err.Error())
+				return
+			}
+		}
+		if err := h.reconcileEntry(ctx, &response, entry, rule, trackSeed, now); err != nil {
+			h.respondError(w, http.StatusInternalServerError, "failed to reconcile entry", err.Error())
+			return
+		}
+	}
+
+	h.respondJSON(w, http.StatusOK, response)
+}
+
+// reconcileEntry compares one entry's stake, payout and refund with the
+// ledger, posting a missing payout or refund. Issues are added to the
+// response.
+func (h *Handler) reconcileEntry(ctx context.Context, response *types.ReconcileResponse, entry *types.Entry, rule engine.Rule, seed int64, now time.Time) error {
+	issue := func(issue string, expected, actual int64) {
+		response.Issues = append(response.Issues, types.ReconcileIssue{
+			PlayerID: entry.PlayerID,
+			Issue:    issue,
+			Expected: expected,
+			Actual:   actual,
+		})
+	}
+
+	stake, err := h.ledgerAmount(ctx, types.TransactionStake, entry, types.AccountHouse)
+	if err != nil {
+		return err
+	}
+	if stake != entry.Stake {
+		issue("stake mismatch", entry.Stake, stake)
+	}
+
+	// What the deterministic timeline says the entry is owed
+	var payout, refund int64
+	switch entry.Status {
+	case types.EntryCashedOut:
+		state := engine.RuleStateAtStep(rule, seed, entry.Step)
+		cashable := true
+		if cashOuter, ok := rule.(engine.CashOuter); ok {
+			cashable = cashOuter.CanCashOut(seed, state)
+		}
+		if state.Round != entry.Round || state.Broken || state.Value != entry.Value || !cashable {
+			issue("cash-out off the timeline", state.Value, entry.Value)
+			return nil
+		}
+		if payer, ok := rule.(engine.Payer); ok && entry.Stake > 0 {
+			payout = payer.Payout(entry.Stake, state)
+		}
+		if entry.Payout != payout {
+			issue("entry payout mismatch", payout, entry.Payout)
+		}
+	case types.EntryVoided:
+		refund = entry.Stake
+	}
+
+	for _, owed := range []struct {
+		kind     string
+		expected int64
+	}{
+		{kind: types.TransactionPayout, expected: payout},
+		{kind: types.TransactionRefund, expected: refund},
+	} {
+		actual, err := h.ledgerAmount(ctx, owed.kind, entry, types.PlayerAccount(entry.PlayerID))
+		if err != nil {
+			return err
+		}
+		switch {
+		case actual == owed.expected:
+		case actual == 0:
+			tx, err := h.ledger.Post(ctx, roundTransaction(owed.kind, entry, owed.expected, now))
+			if err != nil {
+				return err
+			}
+			response.Repaired = append(response.Repaired, tx.ID)
+		default:
+			issue(owed.kind+" mismatch", owed.expected, actual)
+		}
+	}
+	return nil
+}
+
+// ledgerAmount returns what an entry's transaction of the given kind
+// posted to an account, 0 if it was never posted.
+func (h *Handler) ledgerAmount(ctx context.Context, kind string, entry *types.Entry, account string) (int64, error) {
+	tx, err := h.ledger.GetTransaction(ctx, roundTransactionID(kind, entry))
+	if err == store.ErrTransactionNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return tx.Amount(account), nil
+}
+
+// roundTransaction builds an entry's stake (player → house), payout or
+// refund (house → player). Its ID derives from the entry, so each is
+// posted at most once.
+func roundTransaction(kind string, entry *types.Entry, amount int64, now time.Time) *types.Transaction {
+	from, to := types.AccountHouse, types.PlayerAccount(entry.PlayerID)
+	if kind == types.TransactionStake {
+		from, to = to, from
+	}
+	return &types.Transaction{
+		ID:        roundTransactionID(kind, entry),
+		Kind:      kind,
+		Postings:  []types.Posting{{Account: from, Amount: -amount}, {Account: to, Amount: amount}},
+		SessionID: entry.SessionID,
+		Track:     entry.Track,
+		Round:     entry.Round,
+		PlayerID:  entry.PlayerID,
+		CreatedAt: now,
+	}
+}
+
+// transferTransactionID returns the ledger ID of a deposit or withdrawal.
+// The player ID is length-prefixed: it may contain ':', and unprefixed
+// ("a:b", "c") and ("a", "b:c") would share an ID.
+func transferTransactionID(kind, playerID, transactionID string) string {
+	return fmt.Sprintf("%s:%d:%s:%s", kind, len(playerID), playerID, transactionID)
+}
+
+// roundTransactionID returns the ledger ID of an entry's transaction.
+func roundTransactionID(kind string, entry *types.Entry) string {
+	return fmt.Sprintf("%s:%s:%d:%d:%s", kind, entry.SessionID, entry.Track, entry.Round, entry.PlayerID)
+}
+
+// transactionResponse builds the public view of a transaction.
+func transactionResponse(tx *types.Transaction) types.TransactionResponse {
+	response := types.TransactionResponse{
+		ID:        tx.ID,
+		Kind:      tx.Kind,
+		Postings:  tx.Postings,
+		SessionID: tx.SessionID,
+		PlayerID:  tx.PlayerID,
+		CreatedAt: tx.CreatedAt.Format(time.RFC3339),
+	}
+	if tx.SessionID != "" {
+		track, round := tx.Track, tx.Round
+		response.Track, response.Round = &track, &round
+	}
+	return response
+}
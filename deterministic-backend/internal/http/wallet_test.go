@@ -0,0 +1,347 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/engine"
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/store"
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/types"
+	"github.com/go-chi/chi/v5"
+)
+
+// fund deposits an amount into a player's wallet.
+func fund(t *testing.T, ledger store.Ledger, playerID string, amount int64) {
+	_, err := ledger.Post(context.Background(), &types.Transaction{
+		ID:        "deposit:test:" + playerID,
+		Kind:      types.TransactionDeposit,
+		Postings:  []types.Posting{{Account: types.AccountExternal, Amount: -amount}, {Account: types.PlayerAccount(playerID), Amount: amount}},
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to fund %s: %v", playerID, err)
+	}
+}
+
+// stakedEntry records a staked entry as a join would: entry created, stake
+// debited.
+func stakedEntry(t *testing.T, s *mockStore, ledger store.Ledger, entry types.Entry) {
+	if err := s.CreateEntry(context.Background(), &entry); err != nil {
+		t.Fatalf("Failed to create entry for %s: %v", entry.PlayerID, err)
+	}
+	if entry.Stake > 0 {
+		if _, err := ledger.Post(context.Background(), roundTransaction(types.TransactionStake, &entry, entry.Stake, time.Now())); err != nil {
+			t.Fatalf("Failed to stake for %s: %v", entry.PlayerID, err)
+		}
+	}
+}
+
+// failingLedger fails every Post, after applying it if applied is set, as
+// a ledger whose reply is lost would.
+type failingLedger struct {
+	store.Ledger
+	applied bool
+}
+
+func (l *failingLedger) Post(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	if l.applied {
+		l.Ledger.Post(ctx, tx)
+	}
+	return nil, errors.New("connection reset")
+}
+
+// balance returns a player's balance.
+func balance(ledger store.Ledger, playerID string) int64 {
+	balance, _ := ledger.Balance(context.Background(), types.PlayerAccount(playerID))
+	return balance
+}
+
+func TestHandler_WalletTransfers(t *testing.T) {
+	ledger := store.NewMemoryLedger()
+	handler := NewHandler(newMockStore(), WithAdminToken("secret"), WithLedger(ledger), WithPlayerSecret(testPlayerSecret))
+	router := chi.NewRouter()
+	router.Mount("/", handler.Routes())
+
+	tests := []struct {
+		name            string
+		path            string
+		body            interface{}
+		adminToken      string
+		expectedStatus  int
+		expectedBalance int64
+	}{
+		{name: "deposit", path: "/v1/wallets/alice/deposit", body: types.WalletTransferRequest{TransactionID: "d1", Amount: 1000}, adminToken: "secret", expectedStatus: http.StatusCreated, expectedBalance: 1000},
+		{name: "retried deposit", path: "/v1/wallets/alice/deposit", body: types.WalletTransferRequest{TransactionID: "d1", Amount: 1000}, adminToken: "secret", expectedStatus: http.StatusCreated, expectedBalance: 1000},
+		{name: "reused transaction ID", path: "/v1/wallets/alice/deposit", body: types.WalletTransferRequest{TransactionID: "d1", Amount: 500}, adminToken: "secret", expectedStatus: http.StatusConflict, expectedBalance: 1000},
+		{name: "withdrawal", path: "/v1/wallets/alice/withdraw", body: types.WalletTransferRequest{TransactionID: "w1", Amount: 300}, adminToken: "secret", expectedStatus: http.StatusCreated, expectedBalance: 700},
+		{name: "overdraft", path: "/v1/wallets/alice/withdraw", body: types.WalletTransferRequest{TransactionID: "w2", Amount: 701}, adminToken: "secret", expectedStatus: http.StatusConflict, expectedBalance: 700},
+		{name: "without admin token", path: "/v1/wallets/alice/deposit", body: types.WalletTransferRequest{TransactionID: "d2", Amount: 100}, expectedStatus: http.StatusForbidden, expectedBalance: 700},
+		{name: "invalid amount", path: "/v1/wallets/alice/deposit", body: types.WalletTransferRequest{TransactionID: "d3", Amount: 0}, adminToken: "secret", expectedStatus: http.StatusBadRequest, expectedBalance: 700},
+		{name: "missing transaction ID", path: "/v1/wallets/alice/deposit", body: types.WalletTransferRequest{Amount: 100}, adminToken: "secret", expectedStatus: http.StatusBadRequest, expectedBalance: 700},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := json.Marshal(tt.body)
+			req := httptest.NewRequest("POST", tt.path, bytes.NewReader(data))
+			if tt.adminToken != "" {
+				req.Header.Set("X-Admin-Token", tt.adminToken)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if got := balance(ledger, "alice"); got != tt.expectedBalance {
+				t.Errorf("Expected balance %d, got %d", tt.expectedBalance, got)
+			}
+		})
+	}
+
+	// Transaction IDs of different players never collide, even when the
+	// player ID contains the separator
+	for _, deposit := range []struct{ playerID, transactionID string }{{"a:b", "c"}, {"a", "b:c"}} {
+		data, _ := json.Marshal(types.WalletTransferRequest{TransactionID: deposit.transactionID, Amount: 100})
+		req := httptest.NewRequest("POST", "/v1/wallets/"+deposit.playerID+"/deposit", bytes.NewReader(data))
+		req.Header.Set("X-Admin-Token", "secret")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusCreated || balance(ledger, deposit.playerID) != 100 {
+			t.Errorf("%s: expected a credited deposit, got %d: %s", deposit.playerID, w.Code, w.Body.String())
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/v1/wallets/alice?limit=1", nil)
+	req.Header.Set("X-Player-Token", signPlayerToken(testPlayerSecret, "alice", time.Now().Add(time.Hour)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var wallet types.WalletResponse
+	json.NewDecoder(w.Body).Decode(&wallet)
+	if w.Code != http.StatusOK || wallet.Balance != 700 || len(wallet.Transactions) != 1 || wallet.Transactions[0].Kind != types.TransactionWithdrawal {
+		t.Errorf("Expected balance 700 and the withdrawal, got %d %+v", w.Code, wallet)
+	}
+
+	// Only the player (or an admin) reads a wallet
+	if w, _ := playerRequest(router, "GET", "/v1/wallets/alice", "mallory", nil); w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for another player's wallet, got %d", w.Code)
+	}
+	req = httptest.NewRequest("GET", "/v1/wallets/alice", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without a token, got %d", w.Code)
+	}
+}
+
+func TestHandler_WalletsDisabled(t *testing.T) {
+	store := newMockStore()
+	handler := NewHandler(store, WithAdminToken("secret"), WithPlayerSecret(testPlayerSecret))
+	router := chi.NewRouter()
+	router.Mount("/", handler.Routes())
+
+	store.CreateSession(context.Background(), &types.Session{
+		ID:        "test-session-no-wallets",
+		Seed:      "12345",
+		Mode:      engine.ModeCrash,
+		StartAt:   time.Now().Add(time.Hour),
+		TickMs:    100,
+		Status:    "running",
+		CreatedAt: time.Now(),
+	})
+
+	if w, _ := playerRequest(router, "GET", "/v1/wallets/alice", "alice", nil); w.Code != http.StatusNotImplemented {
+		t.Errorf("Expected status 501 for a wallet, got %d", w.Code)
+	}
+	if w, _ := playerRequest(router, "POST", "/v1/sessions/test-session-no-wallets/rounds/0/join", "alice", types.JoinRoundRequest{PlayerID: "alice", Stake: 100}); w.Code != http.StatusNotImplemented {
+		t.Errorf("Expected status 501 for a stake, got %d", w.Code)
+	}
+	if w, _ := playerRequest(router, "POST", "/v1/sessions/test-session-no-wallets/rounds/0/join", "alice", types.JoinRoundRequest{PlayerID: "alice"}); w.Code != http.StatusCreated {
+		t.Errorf("Expected free play to work without wallets, got %d", w.Code)
+	}
+}
+
+func TestHandler_JoinRoundStake(t *testing.T) {
+	ledger := store.NewMemoryLedger()
+	store := newMockStore()
+	handler := NewHandler(store, WithLedger(ledger), WithPlayerSecret(testPlayerSecret))
+	router := chi.NewRouter()
+	router.Mount("/", handler.Routes())
+
+	state := runningAtRound(t, store, "test-session-stake", engine.ModeCrash)
+	runningAtRound(t, store, "test-session-stake-counter", "")
+	fund(t, ledger, "alice", 1000)
+	open := strconv.FormatInt(state.Round+1, 10)
+
+	tests := []struct {
+		name            string
+		path            string
+		body            types.JoinRoundRequest
+		expectedStatus  int
+		expectedBalance int64
+	}{
+		{name: "staked join", path: "/v1/sessions/test-session-stake/rounds/" + open + "/join", body: types.JoinRoundRequest{PlayerID: "alice", Stake: 300}, expectedStatus: http.StatusCreated, expectedBalance: 700},
+		{name: "retried join", path: "/v1/sessions/test-session-stake/rounds/" + open + "/join", body: types.JoinRoundRequest{PlayerID: "alice", Stake: 300}, expectedStatus: http.StatusConflict, expectedBalance: 700},
+		{name: "insufficient funds", path: "/v1/sessions/test-session-stake/rounds/" + open + "/join", body: types.JoinRoundRequest{PlayerID: "bob", Stake: 800}, expectedStatus: http.StatusConflict, expectedBalance: 700},
+		{name: "negative stake", path: "/v1/sessions/test-session-stake/rounds/" + open + "/join", body: types.JoinRoundRequest{PlayerID: "alice", Stake: -1}, expectedStatus: http.StatusBadRequest, expectedBalance: 700},
+		{name: "mode without payouts", path: "/v1/sessions/test-session-stake-counter/rounds/" + open + "/join", body: types.JoinRoundRequest{PlayerID: "alice", Stake: 100}, expectedStatus: http.StatusBadRequest, expectedBalance: 700},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, response := playerRequest(router, "POST", tt.path, tt.body.PlayerID, tt.body)
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code == http.StatusCreated && response.Stake != tt.body.Stake {
+				t.Errorf("Expected stake %d, got %+v", tt.body.Stake, response)
+			}
+			if got := balance(ledger, "alice"); got != tt.expectedBalance {
+				t.Errorf("Expected balance %d, got %d", tt.expectedBalance, got)
+			}
+		})
+	}
+
+	// An entry whose stake could not be debited is removed again
+	if _, err := store.GetEntry(context.Background(), "test-session-stake", 0, state.Round+1, "bob"); err == nil {
+		t.Errorf("Expected no entry for the unfunded join")
+	}
+}
+
+func TestHandler_JoinRoundStakeError(t *testing.T) {
+	for _, applied := range []bool{false, true} {
+		ledger := store.NewMemoryLedger()
+		store := newMockStore()
+		handler := NewHandler(store, WithLedger(&failingLedger{Ledger: ledger, applied: applied}), WithPlayerSecret(testPlayerSecret))
+		router := chi.NewRouter()
+		router.Mount("/", handler.Routes())
+
+		state := runningAtRound(t, store, "test-session-stake-error", engine.ModeCrash)
+		fund(t, ledger, "alice", 1000)
+		path := "/v1/sessions/test-session-stake-error/rounds/" + strconv.FormatInt(state.Round+1, 10) + "/join"
+		w, _ := playerRequest(router, "POST", path, "alice", types.JoinRoundRequest{PlayerID: "alice", Stake: 300})
+
+		// The entry is kept exactly when its stake was debited
+		_, err := store.GetEntry(context.Background(), "test-session-stake-error", 0, state.Round+1, "alice")
+		switch {
+		case applied && (w.Code != http.StatusCreated || err != nil || balance(ledger, "alice") != 700):
+			t.Errorf("Expected the posted stake to keep its entry, got %d %v", w.Code, err)
+		case !applied && (w.Code != http.StatusInternalServerError || err == nil || balance(ledger, "alice") != 1000):
+			t.Errorf("Expected the unstaked entry removed, got %d %v", w.Code, err)
+		}
+	}
+}
+
+func TestHandler_CashOutPayout(t *testing.T) {
+	ledger := store.NewMemoryLedger()
+	store := newMockStore()
+	handler := NewHandler(store, WithLedger(ledger), WithPlayerSecret(testPlayerSecret))
+	router := chi.NewRouter()
+	router.Mount("/", handler.Routes())
+
+	state := runningAtRound(t, store, "test-session-payout", engine.ModeCrash)
+	fund(t, ledger, "alice", 1000)
+	stakedEntry(t, store, ledger, types.Entry{
+		SessionID: "test-session-payout",
+		Round:     state.Round,
+		PlayerID:  "alice",
+		Status:    types.EntryJoined,
+		JoinedAt:  time.Now(),
+		Stake:     400,
+	})
+
+	path := "/v1/sessions/test-session-payout/rounds/" + strconv.FormatInt(state.Round, 10) + "/cashout"
+	w, response := playerRequest(router, "POST", path, "alice", types.CashOutRequest{PlayerID: "alice"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	rule := engine.CrashRule{HouseEdge: engine.DefaultHouseEdge, Growth: engine.DefaultGrowth}
+	payout := rule.Payout(400, state)
+	if response.Payout == nil || *response.Payout != payout {
+		t.Errorf("Expected payout %d, got %+v", payout, response)
+	}
+	if got := balance(ledger, "alice"); got != 1000-400+payout {
+		t.Errorf("Expected balance %d, got %d", 1000-400+payout, got)
+	}
+}
+
+func TestHandler_ReconcileRound(t *testing.T) {
+	ledger := store.NewMemoryLedger()
+	store := newMockStore()
+	handler := NewHandler(store, WithAdminToken("secret"), WithLedger(ledger))
+	router := chi.NewRouter()
+	router.Mount("/", handler.Routes())
+
+	// The session stops mid-round: open entries are voided
+	state := runningAtRound(t, store, "test-session-reconcile", engine.ModeCrash)
+	session, _ := store.GetSession(context.Background(), "test-session-reconcile")
+	stoppedAt := time.Now()
+	session.Status = "stopped"
+	session.StoppedAt = &stoppedAt
+	store.UpdateSession(context.Background(), session)
+
+	rule := engine.CrashRule{HouseEdge: engine.DefaultHouseEdge, Growth: engine.DefaultGrowth}
+	settledAt := time.Now()
+	for _, entry := range []types.Entry{
+		// Cashed out, but the payout was never credited
+		{PlayerID: "cashed", Status: types.EntryCashedOut, Step: state.Step, Value: state.Value, Payout: rule.Payout(100, state), SettledAt: &settledAt},
+		// Still joined when the session stopped
+		{PlayerID: "open", Status: types.EntryJoined},
+		// Claims a value the timeline never showed
+		{PlayerID: "tampered", Status: types.EntryCashedOut, Step: state.Step, Value: state.Value + 1, Payout: 1000, SettledAt: &settledAt},
+	} {
+		fund(t, ledger, entry.PlayerID, 100)
+		entry.SessionID = "test-session-reconcile"
+		entry.Round = state.Round
+		entry.JoinedAt = time.Now()
+		entry.Stake = 100
+		stakedEntry(t, store, ledger, entry)
+	}
+
+	reconcile := func() (*httptest.ResponseRecorder, types.ReconcileResponse) {
+		req := httptest.NewRequest("POST", "/v1/sessions/test-session-reconcile/rounds/"+strconv.FormatInt(state.Round, 10)+"/reconcile", nil)
+		req.Header.Set("X-Admin-Token", "secret")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response types.ReconcileResponse
+		json.NewDecoder(w.Body).Decode(&response)
+		return w, response
+	}
+
+	w, response := reconcile()
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if response.Entries != 3 {
+		t.Errorf("Expected 3 entries, got %d", response.Entries)
+	}
+	if len(response.Repaired) != 1 || response.Repaired[0] != roundTransactionID(types.TransactionPayout, &types.Entry{SessionID: "test-session-reconcile", Round: state.Round, PlayerID: "cashed"}) {
+		t.Errorf("Expected the missing payout to be posted, got %v", response.Repaired)
+	}
+	if len(response.Issues) != 1 || response.Issues[0].PlayerID != "tampered" {
+		t.Errorf("Expected one issue for the tampered entry, got %+v", response.Issues)
+	}
+
+	if got := balance(ledger, "cashed"); got != rule.Payout(100, state) {
+		t.Errorf("Expected the payout credited, got balance %d", got)
+	}
+	if got := balance(ledger, "open"); got != 100 {
+		t.Errorf("Expected the voided stake refunded, got balance %d", got)
+	}
+	if entry, _ := store.GetEntry(context.Background(), "test-session-reconcile", 0, state.Round, "open"); entry.Status != types.EntryVoided {
+		t.Errorf("Expected the open entry voided, got %+v", entry)
+	}
+
+	// Reconciling again repairs nothing
+	if _, response := reconcile(); len(response.Repaired) != 0 || len(response.Issues) != 1 {
+		t.Errorf("Expected nothing more to repair, got %+v", response)
+	}
+}
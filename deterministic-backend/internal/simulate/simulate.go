@@ -0,0 +1,349 @@
+package simulate
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/engine"
+)
+
+// Config describes a simulation run.
+type Config struct {
+	Mode          string               `json:"mode"`
+	EngineVersion int                  `json:"engine_version"` // 0 = latest, as for new sessions
+	Params        map[string]float64   `json:"mode_params,omitempty"`
+	Interval      *engine.IntervalSpec `json:"interval,omitempty"`
+	Seeds         int                  `json:"seeds"`           // Number of simulated sessions
+	Rounds        int                  `json:"rounds_per_seed"` // Rounds per session
+	SeedBase      string               `json:"seed_base"`       // Seeds are derived from SeedBase and the seed index
+	TickMs        int64                `json:"tick_ms,omitempty"`
+	Targets       []float64            `json:"targets,omitempty"` // Cash-out multipliers (crash) or values (counter)
+	Buckets       int                  `json:"buckets"`           // Histogram buckets
+	Workers       int                  `json:"-"`                 // Parallelism (0 = GOMAXPROCS); does not change results
+}
+
+// Report is the result of a simulation run.
+type Report struct {
+	Config      Config        `json:"config"`
+	Rounds      int64         `json:"rounds"`
+	RoundSteps  Distribution  `json:"round_steps"`             // Steps from round start to its break
+	BreakValue  Distribution  `json:"break_value"`             // Value reached just before the break
+	MeanRoundMs float64       `json:"mean_round_ms,omitempty"` // Mean round length in wall time (with TickMs)
+	CrashPoint  *Distribution `json:"crash_point,omitempty"`   // Crash mode only
+	Targets     []Target      `json:"targets,omitempty"`
+}
+
+// Distribution summarizes observed values.
+type Distribution struct {
+	Count       int64        `json:"count"`
+	Mean        float64      `json:"mean"`
+	StdDev      float64      `json:"stddev"`
+	Min         float64      `json:"min"`
+	Max         float64      `json:"max"`
+	Percentiles []Percentile `json:"percentiles"`
+	Histogram   []Bucket     `json:"histogram"`
+}
+
+// Percentile is the smallest observed value v with P(X <= v) >= P/100.
+type Percentile struct {
+	P     float64 `json:"p"`
+	Value float64 `json:"value"`
+}
+
+// Bucket counts values in [Low, High); the last bucket also holds
+// everything above the 99.9th percentile.
+type Bucket struct {
+	Low   float64 `json:"low"`
+	High  float64 `json:"high"`
+	Count int64   `json:"count"`
+}
+
+// Target is the chance of reaching a cash-out target before the break.
+//
+// In crash mode a bet cashed out at multiplier m pays m when the crash
+// point is above m (multipliers shown are strictly below it), so ExpectedPayout = m * HitRate is the return per
+// unit bet. In counter mode the target is a value and there is no payout.
+type Target struct {
+	Target         float64  `json:"target"`
+	HitRate        float64  `json:"hit_rate"`
+	ExpectedPayout *float64 `json:"expected_payout,omitempty"`
+}
+
+// percentiles reported for every distribution
+var percentiles = []float64{1, 5, 25, 50, 75, 90, 95, 99, 99.9}
+
+// crashPointer is implemented by rules with a crash point per round.
+type crashPointer interface {
+	CrashPoint(seed int64, round int64) float64
+}
+
+// counts is an exact histogram of values scaled to integers.
+type counts map[int64]int64
+
+// tally holds the observations of one worker.
+type tally struct {
+	rounds     int64
+	roundSteps counts
+	breakValue counts
+	crashPoint counts // Crash points in hundredths
+}
+
+// Run simulates Seeds sessions of Rounds rounds each with the production
+// engine and summarizes them. Rounds come from engine.RuleRounds, the same
+// walk StateAt uses, so the figures match what players see. Results do not
+// depend on Workers.
+func Run(config Config) (*Report, error) {
+	if config.Seeds <= 0 || config.Rounds <= 0 {
+		return nil, errors.New("seeds and rounds must be greater than 0")
+	}
+	if config.Buckets <= 0 {
+		config.Buckets = 20
+	}
+	if config.EngineVersion == 0 {
+		config.EngineVersion = engine.LatestVersion
+	}
+	if config.Interval != nil {
+		normalized, err := config.Interval.Normalize()
+		if err != nil {
+			return nil, err
+		}
+		config.Interval = &normalized
+	}
+
+	rule, err := engine.NewRule(config.Mode, engine.RuleConfig{
+		Version:  config.EngineVersion,
+		Params:   config.Params,
+		Interval: config.Interval,
+	})
+	if err != nil {
+		return nil, err
+	}
+	config.Mode = rule.Mode()
+	config.EngineVersion = rule.Version()
+	config.Params = rule.Params()
+	crash, isCrash := rule.(crashPointer)
+
+	workers := config.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > config.Seeds {
+		workers = config.Seeds
+	}
+
+	// Worker w simulates seeds w, w+workers, ...
+	tallies := make([]tally, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			t := tally{roundSteps: counts{}, breakValue: counts{}, crashPoint: counts{}}
+			for i := w; i < config.Seeds; i += workers {
+				seed := Seed(config.SeedBase, i)
+				for _, info := range engine.RuleRounds(rule, seed, math.MaxInt64, 0, config.Rounds) {
+					t.rounds++
+					t.roundSteps[info.BreakStep-info.StartStep]++
+					t.breakValue[info.Interval]++
+					if isCrash {
+						t.crashPoint[int64(math.Round(crash.CrashPoint(seed, info.Round)*100))]++
+					}
+				}
+			}
+			tallies[w] = t
+		}(w)
+	}
+	wg.Wait()
+
+	total := tally{roundSteps: counts{}, breakValue: counts{}, crashPoint: counts{}}
+	for _, t := range tallies {
+		total.rounds += t.rounds
+		total.roundSteps.merge(t.roundSteps)
+		total.breakValue.merge(t.breakValue)
+		total.crashPoint.merge(t.crashPoint)
+	}
+
+	report := &Report{
+		Config:     config,
+		Rounds:     total.rounds,
+		RoundSteps: total.roundSteps.distribution(1, config.Buckets),
+		BreakValue: total.breakValue.distribution(1, config.Buckets),
+	}
+	if config.TickMs > 0 {
+		report.MeanRoundMs = report.RoundSteps.Mean * float64(config.TickMs)
+	}
+
+	if isCrash {
+		dist := total.crashPoint.distribution(100, config.Buckets)
+		report.CrashPoint = &dist
+		for _, target := range config.Targets {
+			hitRate := total.crashPoint.above(int64(math.Round(target*100))) / float64(total.rounds)
+			payout := target * hitRate
+			report.Targets = append(report.Targets, Target{Target: target, HitRate: hitRate, ExpectedPayout: &payout})
+		}
+	} else {
+		for _, target := range config.Targets {
+			hitRate := total.breakValue.atLeast(int64(math.Ceil(target))) / float64(total.rounds)
+			report.Targets = append(report.Targets, Target{Target: target, HitRate: hitRate})
+		}
+	}
+
+	return report, nil
+}
+
+// Seed returns the engine seed of simulated session i: the first 8 bytes
+// of SHA-256("<base>:<i>"), like production UUID seeds on engine version 2.
+func Seed(base string, i int) int64 {
+	sum := sha256.Sum256([]byte(base + ":" + strconv.Itoa(i)))
+	return engine.SeedFromDigest(sum[:])
+}
+
+// merge adds other into c.
+func (c counts) merge(other counts) {
+	for value, n := range other {
+		c[value] += n
+	}
+}
+
+// atLeast returns the number of values >= min.
+func (c counts) atLeast(min int64) float64 {
+	var n int64
+	for value, count := range c {
+		if value >= min {
+			n += count
+		}
+	}
+	return float64(n)
+}
+
+// above returns the number of values > min.
+func (c counts) above(min int64) float64 {
+	return c.atLeast(min + 1)
+}
+
+// distribution summarizes the counts; values are divided by scale.
+func (c counts) distribution(scale float64, buckets int) Distribution {
+	values := make([]int64, 0, len(c))
+	var total int64
+	for value, n := range c {
+		values = append(values, value)
+		total += n
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+
+	dist := Distribution{Count: total}
+	if total == 0 {
+		return dist
+	}
+
+	var sum, sumSquares float64
+	for _, value := range values {
+		v := float64(value) / scale
+		sum += v * float64(c[value])
+		sumSquares += v * v * float64(c[value])
+	}
+	dist.Mean = sum / float64(total)
+	dist.StdDev = math.Sqrt(math.Max(0, sumSquares/float64(total)-dist.Mean*dist.Mean))
+	dist.Min = float64(values[0]) / scale
+	dist.Max = float64(values[len(values)-1]) / scale
+
+	// Percentiles from the cumulative counts
+	var cumulative int64
+	next := 0
+	for _, value := range values {
+		cumulative += c[value]
+		for next < len(percentiles) && float64(cumulative) >= percentiles[next]/100*float64(total) {
+			dist.Percentiles = append(dist.Percentiles, Percentile{P: percentiles[next], Value: float64(value) / scale})
+			next++
+		}
+	}
+
+	// Equal-width buckets on the value grid up to the 99.9th percentile, so
+	// long tails (crash points) do not squash the histogram
+	low := dist.Min
+	high := dist.Percentiles[len(dist.Percentiles)-1].Value
+	width := math.Max(math.Ceil((high-low)*scale/float64(buckets)), 1) / scale
+	for i := 0; i < buckets; i++ {
+		dist.Histogram = append(dist.Histogram, Bucket{Low: low + float64(i)*width, High: low + float64(i+1)*width})
+	}
+	dist.Histogram[buckets-1].High = math.Max(dist.Max+1/scale, dist.Histogram[buckets-1].High)
+	for _, value := range values {
+		i := int((float64(value)/scale - low) / width)
+		if i >= buckets {
+			i = buckets - 1
+		}
+		dist.Histogram[i].Count += c[value]
+	}
+
+	return dist
+}
+
+// WriteJSON writes the report as indented JSON.
+func WriteJSON(w io.Writer, report *Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// WriteCSV writes the report as metric,stat,value rows.
+func WriteCSV(w io.Writer, report *Report) error {
+	out := csv.NewWriter(w)
+	write := func(metric, stat string, value float64) {
+		out.Write([]string{metric, stat, formatFloat(value)})
+	}
+
+	out.Write([]string{"metric", "stat", "value"})
+	write("rounds", "count", float64(report.Rounds))
+	if report.MeanRoundMs > 0 {
+		write("round_ms", "mean", report.MeanRoundMs)
+	}
+
+	distributions := []struct {
+		name string
+		dist *Distribution
+	}{
+		{"round_steps", &report.RoundSteps},
+		{"break_value", &report.BreakValue},
+		{"crash_point", report.CrashPoint},
+	}
+	for _, d := range distributions {
+		if d.dist == nil {
+			continue
+		}
+		write(d.name, "mean", d.dist.Mean)
+		write(d.name, "stddev", d.dist.StdDev)
+		write(d.name, "min", d.dist.Min)
+		write(d.name, "max", d.dist.Max)
+		for _, p := range d.dist.Percentiles {
+			write(d.name, "p"+formatFloat(p.P), p.Value)
+		}
+		for _, b := range d.dist.Histogram {
+			write(d.name, fmt.Sprintf("bucket[%s,%s)", formatFloat(b.Low), formatFloat(b.High)), float64(b.Count))
+		}
+	}
+
+	for _, t := range report.Targets {
+		name := "target_" + formatFloat(t.Target)
+		write(name, "hit_rate", t.HitRate)
+		if t.ExpectedPayout != nil {
+			write(name, "expected_payout", *t.ExpectedPayout)
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+// formatFloat formats a float without exponent or trailing zeros.
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
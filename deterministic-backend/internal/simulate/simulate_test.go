@@ -0,0 +1,180 @@
+package simulate
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/engine"
+)
+
+func TestRun_IndependentOfWorkers(t *testing.T) {
+	config := Config{Mode: engine.ModeCrash, Seeds: 50, Rounds: 200, SeedBase: "test", Targets: []float64{2}}
+
+	config.Workers = 1
+	single, err := Run(config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	config.Workers = 7
+	parallel, err := Run(config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	single.Config.Workers, parallel.Config.Workers = 0, 0
+	if !reflect.DeepEqual(single, parallel) {
+		t.Errorf("Reports differ between 1 and 7 workers")
+	}
+}
+
+func TestRun_CounterMatchesEngine(t *testing.T) {
+	config := Config{Seeds: 20, Rounds: 100, SeedBase: "test", Targets: []float64{200}}
+	report, err := Run(config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if report.Rounds != 2000 || report.BreakValue.Count != 2000 {
+		t.Fatalf("Expected 2000 rounds, got %d", report.Rounds)
+	}
+
+	// Recompute the break values with the engine
+	rule, _ := engine.NewRule(engine.ModeCounter, engine.RuleConfig{Version: engine.LatestVersion})
+	var sum, hits float64
+	for i := 0; i < config.Seeds; i++ {
+		seed := Seed(config.SeedBase, i)
+		for round := int64(0); round < int64(config.Rounds); round++ {
+			interval := rule.BreakInterval(seed, round)
+			sum += float64(interval)
+			if interval >= 200 {
+				hits++
+			}
+		}
+	}
+	if mean := sum / 2000; math.Abs(report.BreakValue.Mean-mean) > 1e-9 {
+		t.Errorf("Expected mean break value %v, got %v", mean, report.BreakValue.Mean)
+	}
+	if report.Targets[0].HitRate != hits/2000 || report.Targets[0].ExpectedPayout != nil {
+		t.Errorf("Expected hit rate %v without payout, got %+v", hits/2000, report.Targets[0])
+	}
+
+	// Default interval range is [100, 300]
+	if report.BreakValue.Min < 100 || report.BreakValue.Max > 300 {
+		t.Errorf("Expected break values in [100, 300], got [%v, %v]", report.BreakValue.Min, report.BreakValue.Max)
+	}
+	if report.MeanRoundMs != 0 {
+		t.Errorf("Expected no wall-time figures without tick_ms, got %v", report.MeanRoundMs)
+	}
+}
+
+func TestRun_CrashReturnToPlayer(t *testing.T) {
+	report, err := Run(Config{
+		Mode:     engine.ModeCrash,
+		Params:   map[string]float64{"house_edge": 0.03},
+		Seeds:    100,
+		Rounds:   1000,
+		SeedBase: "rtp",
+		Targets:  []float64{1.5, 2, 5},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if report.CrashPoint == nil || report.CrashPoint.Min < 1 {
+		t.Fatalf("Expected crash points >= 1, got %+v", report.CrashPoint)
+	}
+	for _, target := range report.Targets {
+		if target.ExpectedPayout == nil || math.Abs(*target.ExpectedPayout-0.97) > 0.02 {
+			t.Errorf("Target %v: expected payout near 0.97, got %+v", target.Target, target.ExpectedPayout)
+		}
+	}
+}
+
+func TestRun_CrashTargetAtCrashPoint(t *testing.T) {
+	report, err := Run(Config{Mode: engine.ModeCrash, Seeds: 20, Rounds: 1000, SeedBase: "instant", Targets: []float64{1}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Rounds that crash at 1.00x cannot be cashed out at 1.00x
+	if hitRate := report.Targets[0].HitRate; hitRate >= 1 || hitRate < 0.97 {
+		t.Errorf("Expected a hit rate of about 0.99 for target 1.00, got %v", hitRate)
+	}
+}
+
+func TestRun_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{name: "no seeds", config: Config{Rounds: 10}},
+		{name: "unknown mode", config: Config{Mode: "roulette", Seeds: 1, Rounds: 1}},
+		{name: "invalid interval", config: Config{Seeds: 1, Rounds: 1, Interval: &engine.IntervalSpec{Min: 10, Max: 5}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Run(tt.config); err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestDistribution(t *testing.T) {
+	// 1..100 once each
+	c := counts{}
+	for v := int64(1); v <= 100; v++ {
+		c[v]++
+	}
+	dist := c.distribution(1, 4)
+
+	if dist.Count != 100 || dist.Mean != 50.5 || dist.Min != 1 || dist.Max != 100 {
+		t.Errorf("Unexpected summary %+v", dist)
+	}
+	for _, p := range dist.Percentiles {
+		if p.P == 50 && p.Value != 50 {
+			t.Errorf("Expected median 50, got %v", p.Value)
+		}
+		if p.P == 99 && p.Value != 99 {
+			t.Errorf("Expected p99 99, got %v", p.Value)
+		}
+	}
+
+	var total int64
+	for _, b := range dist.Histogram {
+		total += b.Count
+	}
+	if len(dist.Histogram) != 4 || total != 100 {
+		t.Errorf("Expected 4 buckets holding 100 values, got %+v", dist.Histogram)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	report, err := Run(Config{Mode: engine.ModeCrash, Seeds: 5, Rounds: 10, Targets: []float64{2}, TickMs: 100})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, report); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Invalid CSV: %v", err)
+	}
+
+	found := map[string]bool{}
+	for _, row := range rows {
+		found[row[0]+"/"+row[1]] = true
+	}
+	for _, key := range []string{"rounds/count", "round_ms/mean", "round_steps/p50", "crash_point/mean", "target_2/expected_payout"} {
+		if !found[key] {
+			t.Errorf("Missing row %s", key)
+		}
+	}
+}
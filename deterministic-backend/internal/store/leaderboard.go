@@ -0,0 +1,46 @@
+package store
+
+import (
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/types"
+)
+
+// LeaderboardScope selects one leaderboard of a board: a session's, a UTC
+// day's or the all-time one. Day and all-time leaderboards are kept per
+// game mode, as modes score cash-outs in different units.
+type LeaderboardScope struct {
+	Scope     string // types.ScopeSession, types.ScopeDay or types.ScopeAllTime
+	SessionID string // Session scope only
+	Day       string // Day scope only, YYYY-MM-DD
+	Mode      string // Day and all-time scopes only
+}
+
+// CashOutScopes returns the leaderboards a cash-out in a session of the
+// given mode counts towards: its session's, and its mode's day and
+// all-time ones.
+func CashOutScopes(entry *types.Entry, mode string) []LeaderboardScope {
+	scopes := []LeaderboardScope{
+		{Scope: types.ScopeSession, SessionID: entry.SessionID},
+		{Scope: types.ScopeAllTime, Mode: mode},
+	}
+	if entry.SettledAt != nil {
+		scopes = append(scopes, LeaderboardScope{Scope: types.ScopeDay, Day: entry.SettledAt.UTC().Format("2006-01-02"), Mode: mode})
+	}
+	return scopes
+}
+
+// Streak is a player's run of consecutive rounds cashed out on a session
+// track.
+type Streak struct {
+	Round  int64 `json:"round"`  // Round of the latest cash-out
+	Length int64 `json:"length"` // Rounds in the run, ending with Round
+}
+
+// Extend returns the streak after a cash-out in round: one longer if the
+// previous cash-out was in the round before, else a new run of one.
+// Skipping a round ends a run like losing one does.
+func (s Streak) Extend(round int64) Streak {
+	if s.Length > 0 && s.Round == round-1 {
+		return Streak{Round: round, Length: s.Length + 1}
+	}
+	return Streak{Round: round, Length: 1}
+}
@@ -0,0 +1,49 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/types"
+)
+
+func TestStreak_Extend(t *testing.T) {
+	tests := []struct {
+		name     string
+		streak   Streak
+		round    int64
+		expected Streak
+	}{
+		{name: "first cash-out", streak: Streak{}, round: 0, expected: Streak{Round: 0, Length: 1}},
+		{name: "next round", streak: Streak{Round: 4, Length: 2}, round: 5, expected: Streak{Round: 5, Length: 3}},
+		{name: "skipped round", streak: Streak{Round: 4, Length: 2}, round: 6, expected: Streak{Round: 6, Length: 1}},
+		{name: "first cash-out in round 1", streak: Streak{}, round: 1, expected: Streak{Round: 1, Length: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.streak.Extend(tt.round); got != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCashOutScopes(t *testing.T) {
+	settledAt := time.Date(2024, 1, 15, 23, 30, 0, 0, time.FixedZone("UTC-2", -2*3600))
+	scopes := CashOutScopes(&types.Entry{SessionID: "sess_1", SettledAt: &settledAt}, "crash")
+
+	expected := map[LeaderboardScope]bool{
+		{Scope: types.ScopeSession, SessionID: "sess_1"}:          true,
+		{Scope: types.ScopeDay, Day: "2024-01-16", Mode: "crash"}: true, // UTC day
+		{Scope: types.ScopeAllTime, Mode: "crash"}:                true,
+	}
+	if len(scopes) != len(expected) {
+		t.Fatalf("Expected scopes %v, got %v", expected, scopes)
+	}
+	for _, scope := range scopes {
+		if !expected[scope] {
+			t.Errorf("Unexpected scope %+v", scope)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package store
+
+import (
+	"context"
+	"strings"
+
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/types"
+)
+
+// Ledger defines the interface for the double-entry wallet ledger.
+// Transactions are applied atomically and at most once per ID; balances
+// are the sum of the postings to an account.
+type Ledger interface {
+	// Post applies a transaction and returns it. If a transaction with the
+	// same ID was already posted, nothing is applied and the stored one is
+	// returned; ErrTransactionConflict if it differs from tx
+	Post(ctx context.Context, tx *types.Transaction) (*types.Transaction, error)
+
+	// GetTransaction retrieves a transaction by ID
+	GetTransaction(ctx context.Context, id string) (*types.Transaction, error)
+
+	// Balance returns an account's balance, 0 for unknown accounts
+	Balance(ctx context.Context, account string) (int64, error)
+
+	// Transactions returns up to limit transactions of an account, newest
+	// first
+	Transactions(ctx context.Context, account string, limit int) ([]*types.Transaction, error)
+}
+
+// Ledger errors
+var (
+	ErrTransactionNotFound = &StoreError{Message: "transaction not found"}
+	ErrTransactionConflict = &StoreError{Message: "transaction ID already used for a different transaction"}
+	ErrInvalidTransaction  = &StoreError{Message: "transaction needs an ID and non-zero postings that sum to zero"}
+	ErrInsufficientFunds   = &StoreError{Message: "insufficient funds"}
+)
+
+// validTransaction reports whether a transaction can be posted: it has an
+// ID and at least two non-zero postings that balance.
+func validTransaction(tx *types.Transaction) bool {
+	if tx.ID == "" || len(tx.Postings) < 2 {
+		return false
+	}
+	var sum int64
+	for _, posting := range tx.Postings {
+		if posting.Account == "" || posting.Amount == 0 {
+			return false
+		}
+		sum += posting.Amount
+	}
+	return sum == 0
+}
+
+// sameTransaction reports whether a retried transaction matches the
+// stored one. The creation time is not compared.
+func sameTransaction(a, b *types.Transaction) bool {
+	if a.ID != b.ID || a.Kind != b.Kind || a.SessionID != b.SessionID || a.Track != b.Track ||
+		a.Round != b.Round || a.PlayerID != b.PlayerID || len(a.Postings) != len(b.Postings) {
+		return false
+	}
+	for i := range a.Postings {
+		if a.Postings[i] != b.Postings[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// mayOverdraw reports whether an account can go negative. Only player
+// accounts cannot.
+func mayOverdraw(account string) bool {
+	return !strings.HasPrefix(account, types.PlayerAccountPrefix)
+}
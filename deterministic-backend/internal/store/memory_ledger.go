@@ -0,0 +1,99 @@
+package store
+
+import (
+	"context"
+	"sync"
+
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/types"
+)
+
+// MemoryLedger implements the Ledger interface in process memory.
+// It suits tests and single-node development; nothing is persisted.
+type MemoryLedger struct {
+	mu           sync.Mutex
+	transactions map[string]types.Transaction
+	balances     map[string]int64
+	history      map[string][]string // Transaction IDs per account, oldest first
+}
+
+// NewMemoryLedger creates an empty in-memory ledger.
+func NewMemoryLedger() *MemoryLedger {
+	return &MemoryLedger{
+		transactions: make(map[string]types.Transaction),
+		balances:     make(map[string]int64),
+		history:      make(map[string][]string),
+	}
+}
+
+// Post applies a transaction in memory.
+func (l *MemoryLedger) Post(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	if !validTransaction(tx) {
+		return nil, ErrInvalidTransaction
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if stored, exists := l.transactions[tx.ID]; exists {
+		if !sameTransaction(&stored, tx) {
+			return nil, ErrTransactionConflict
+		}
+		return copyTransaction(stored), nil
+	}
+
+	for _, posting := range tx.Postings {
+		if !mayOverdraw(posting.Account) && l.balances[posting.Account]+posting.Amount < 0 {
+			return nil, ErrInsufficientFunds
+		}
+	}
+
+	stored := *copyTransaction(*tx)
+	l.transactions[tx.ID] = stored
+	for _, posting := range tx.Postings {
+		l.balances[posting.Account] += posting.Amount
+		l.history[posting.Account] = append(l.history[posting.Account], tx.ID)
+	}
+	return copyTransaction(stored), nil
+}
+
+// GetTransaction retrieves a transaction from memory.
+func (l *MemoryLedger) GetTransaction(ctx context.Context, id string) (*types.Transaction, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stored, exists := l.transactions[id]
+	if !exists {
+		return nil, ErrTransactionNotFound
+	}
+	return copyTransaction(stored), nil
+}
+
+// Balance returns an account's balance from memory.
+func (l *MemoryLedger) Balance(ctx context.Context, account string) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.balances[account], nil
+}
+
+// Transactions returns an account's latest transactions from memory.
+func (l *MemoryLedger) Transactions(ctx context.Context, account string, limit int) ([]*types.Transaction, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ids := l.history[account]
+	var transactions []*types.Transaction
+	for i := len(ids) - 1; i >= 0 && len(transactions) < limit; i-- {
+		transactions = append(transactions, copyTransaction(l.transactions[ids[i]]))
+	}
+	if transactions == nil {
+		transactions = []*types.Transaction{}
+	}
+	return transactions, nil
+}
+
+// copyTransaction returns a copy that shares no postings with tx.
+func copyTransaction(tx types.Transaction) *types.Transaction {
+	tx.Postings = append([]types.Posting(nil), tx.Postings...)
+	return &tx
+}
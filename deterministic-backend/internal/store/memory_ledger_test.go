@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/types"
+)
+
+// transfer builds a transaction moving amount from one account to another.
+func transfer(id, kind, from, to string, amount int64) *types.Transaction {
+	return &types.Transaction{
+		ID:        id,
+		Kind:      kind,
+		Postings:  []types.Posting{{Account: from, Amount: -amount}, {Account: to, Amount: amount}},
+		CreatedAt: time.Now(),
+	}
+}
+
+func TestMemoryLedger_Post(t *testing.T) {
+	ctx := context.Background()
+	ledger := NewMemoryLedger()
+	alice := types.PlayerAccount("alice")
+
+	if _, err := ledger.Post(ctx, transfer("deposit:1", types.TransactionDeposit, types.AccountExternal, alice, 1000)); err != nil {
+		t.Fatalf("Failed to post deposit: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		tx          *types.Transaction
+		expectedErr error
+	}{
+		{name: "stake", tx: transfer("stake:1", types.TransactionStake, alice, types.AccountHouse, 400)},
+		{name: "retried stake is not applied twice", tx: transfer("stake:1", types.TransactionStake, alice, types.AccountHouse, 400)},
+		{name: "reused ID", tx: transfer("stake:1", types.TransactionStake, alice, types.AccountHouse, 500), expectedErr: ErrTransactionConflict},
+		{name: "overdraft", tx: transfer("stake:2", types.TransactionStake, alice, types.AccountHouse, 601), expectedErr: ErrInsufficientFunds},
+		{name: "unbalanced", tx: &types.Transaction{ID: "bad:1", Postings: []types.Posting{{Account: alice, Amount: 1}, {Account: types.AccountHouse, Amount: 1}}}, expectedErr: ErrInvalidTransaction},
+		{name: "missing ID", tx: transfer("", types.TransactionStake, alice, types.AccountHouse, 1), expectedErr: ErrInvalidTransaction},
+		{name: "house may go negative", tx: transfer("payout:1", types.TransactionPayout, types.AccountHouse, alice, 2000)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ledger.Post(ctx, tt.tx); err != tt.expectedErr {
+				t.Errorf("Expected error %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+
+	expected := map[string]int64{
+		alice:                 1000 - 400 + 2000,
+		types.AccountHouse:    400 - 2000,
+		types.AccountExternal: -1000,
+	}
+	var sum int64
+	for account, balance := range expected {
+		got, err := ledger.Balance(ctx, account)
+		if err != nil || got != balance {
+			t.Errorf("Expected %s balance %d, got %d (%v)", account, balance, got, err)
+		}
+		sum += got
+	}
+	if sum != 0 {
+		t.Errorf("Expected balances to sum to zero, got %d", sum)
+	}
+
+	history, err := ledger.Transactions(ctx, alice, 2)
+	if err != nil || len(history) != 2 || history[0].ID != "payout:1" || history[1].ID != "stake:1" {
+		t.Errorf("Expected the two latest transactions newest first, got %+v (%v)", history, err)
+	}
+	if _, err := ledger.GetTransaction(ctx, "stake:2"); err != ErrTransactionNotFound {
+		t.Errorf("Expected rejected transaction not to be stored, got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/types"
+)
+
+// SessionQuery filters and pages a session listing. Zero fields match
+// every session.
+type SessionQuery struct {
+	Status        string                      // Lifecycle phase ("scheduled", "running", "paused", "stopped", "expired" or "finished")
+	Phase         func(*types.Session) string // Current phase of a session (nil = Session.Phase now)
+	CreatedAfter  *time.Time                  // Created at or after (inclusive)
+	CreatedBefore *time.Time                  // Created before (exclusive)
+	Labels        map[string]string           // Metadata labels that must all match
+	Cursor        string                      // NextCursor of the previous page, empty = first page
+	Limit         int                         // Maximum sessions per page (must be positive)
+}
+
+// SessionPage is one page of a session listing, newest first.
+type SessionPage struct {
+	Sessions   []*types.Session
+	NextCursor string // Empty once there are no more sessions
+}
+
+// Matches reports whether a session passes the query's filters. The
+// status filter matches the session's current phase, not its stored
+// status, which lags until an expired or finished session is settled.
+func (q SessionQuery) Matches(session *types.Session) bool {
+	if q.Status != "" && q.phase(session) != q.Status {
+		return false
+	}
+	if q.CreatedAfter != nil && session.CreatedAt.Before(*q.CreatedAfter) {
+		return false
+	}
+	if q.CreatedBefore != nil && !session.CreatedAt.Before(*q.CreatedBefore) {
+		return false
+	}
+	if len(q.Labels) > 0 {
+		labels := SessionLabels(session)
+		for key, value := range q.Labels {
+			if labels[key] != value {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// phase returns the current phase of a session.
+func (q SessionQuery) phase(session *types.Session) string {
+	if q.Phase != nil {
+		return q.Phase(session)
+	}
+	return session.Phase(time.Now())
+}
+
+// Label bounds. Every label is a Redis index, so a session may have at most
+// MaxSessionLabels of them; longer strings are kept as plain metadata.
+const (
+	MaxSessionLabels    = 16
+	MaxLabelKeyLength   = 64
+	MaxLabelValueLength = 128
+)
+
+// SessionLabels returns the labels of a session: the top-level string
+// values of its metadata object with a key of at most MaxLabelKeyLength and
+// a value of at most MaxLabelValueLength bytes. Other metadata is not a
+// label.
+func SessionLabels(session *types.Session) map[string]string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(session.Metadata, &fields); err != nil {
+		return nil
+	}
+
+	labels := make(map[string]string)
+	for key, raw := range fields {
+		if len(key) > MaxLabelKeyLength {
+			continue
+		}
+		var value string
+		if err := json.Unmarshal(raw, &value); err == nil && len(value) <= MaxLabelValueLength {
+			labels[key] = value
+		}
+	}
+	return labels
+}
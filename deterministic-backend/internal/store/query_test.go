@@ -0,0 +1,114 @@
+package store
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/types"
+)
+
+func TestSessionLabels(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata string
+		expected map[string]string
+	}{
+		{name: "string values", metadata: `{"region":"eu","tier":"vip"}`, expected: map[string]string{"region": "eu", "tier": "vip"}},
+		{name: "other values skipped", metadata: `{"region":"eu","seats":6,"tags":["a"]}`, expected: map[string]string{"region": "eu"}},
+		{name: "long strings skipped", metadata: `{"region":"eu","notes":"` + strings.Repeat("x", MaxLabelValueLength+1) + `","` + strings.Repeat("k", MaxLabelKeyLength+1) + `":"v"}`, expected: map[string]string{"region": "eu"}},
+		{name: "not an object", metadata: `["eu"]`, expected: map[string]string{}},
+		{name: "no metadata", expected: map[string]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels := SessionLabels(&types.Session{Metadata: json.RawMessage(tt.metadata)})
+			if len(labels) != len(tt.expected) {
+				t.Fatalf("Expected labels %v, got %v", tt.expected, labels)
+			}
+			for key, value := range tt.expected {
+				if labels[key] != value {
+					t.Errorf("Expected labels %v, got %v", tt.expected, labels)
+				}
+			}
+		})
+	}
+}
+
+func TestSessionQuery_Matches(t *testing.T) {
+	createdAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	session := &types.Session{
+		Status:    "running",
+		CreatedAt: createdAt,
+		Metadata:  json.RawMessage(`{"region":"eu","tier":"vip"}`),
+	}
+	before, after := createdAt.Add(-time.Second), createdAt.Add(time.Second)
+
+	tests := []struct {
+		name     string
+		query    SessionQuery
+		expected bool
+	}{
+		{name: "empty", query: SessionQuery{}, expected: true},
+		{name: "status", query: SessionQuery{Status: "running"}, expected: true},
+		{name: "other status", query: SessionQuery{Status: "stopped"}, expected: false},
+		{name: "current phase", query: SessionQuery{Status: "finished", Phase: func(*types.Session) string { return "finished" }}, expected: true},
+		{name: "stored status lags", query: SessionQuery{Status: "running", Phase: func(*types.Session) string { return "expired" }}, expected: false},
+		{name: "created after is inclusive", query: SessionQuery{CreatedAfter: &createdAt}, expected: true},
+		{name: "created before is exclusive", query: SessionQuery{CreatedBefore: &createdAt}, expected: false},
+		{name: "created in range", query: SessionQuery{CreatedAfter: &before, CreatedBefore: &after}, expected: true},
+		{name: "labels", query: SessionQuery{Labels: map[string]string{"region": "eu", "tier": "vip"}}, expected: true},
+		{name: "one label differs", query: SessionQuery{Labels: map[string]string{"region": "eu", "tier": "basic"}}, expected: false},
+		{name: "missing label", query: SessionQuery{Labels: map[string]string{"team": "a"}}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.Matches(session); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestListCursor(t *testing.T) {
+	cursor := listCursor{createdMs: 1705312800000, id: "sess_abc:123"}
+	decoded, err := decodeListCursor(cursor.encode())
+	if err != nil {
+		t.Fatalf("Failed to decode cursor: %v", err)
+	}
+	if decoded != cursor {
+		t.Errorf("Expected %+v, got %+v", cursor, decoded)
+	}
+
+	for _, invalid := range []string{"!!", "bm90LWEtY3Vyc29y", "MTIz"} {
+		if _, err := decodeListCursor(invalid); err == nil {
+			t.Errorf("Expected an error for cursor %q", invalid)
+		}
+	}
+}
+
+func TestQueryIndexKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    SessionQuery
+		expected string
+	}{
+		{name: "all sessions", query: SessionQuery{}, expected: "sessions:created"},
+		{name: "status first", query: SessionQuery{Status: "running", Labels: map[string]string{"region": "eu"}}, expected: "sessions:status:running"},
+		{name: "first label", query: SessionQuery{Labels: map[string]string{"tier": "vip", "region": "eu"}}, expected: "sessions:label:region=eu"},
+		{name: "scheduled sessions are stored as running", query: SessionQuery{Status: "scheduled"}, expected: "sessions:status:running"},
+		{name: "expired sessions may not be settled", query: SessionQuery{Status: "expired", Labels: map[string]string{"region": "eu"}}, expected: "sessions:label:region=eu"},
+		{name: "finished sessions may not be settled", query: SessionQuery{Status: "finished"}, expected: "sessions:created"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := queryIndexKey(tt.query); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/types"
+	"github.com/redis/go-redis/v9"
+)
+
+// maxPostAttempts bounds the retries of a post that lost an optimistic
+// transaction to a concurrent post on the same player accounts
+const maxPostAttempts = 10
+
+// RedisLedger implements the Ledger interface using Redis.
+// Transactions are stored as JSON, balances as integers and each
+// account's history as a list of transaction IDs. Nothing expires.
+type RedisLedger struct {
+	client *redis.Client
+}
+
+// Ledger returns a ledger sharing the store's Redis connection.
+func (s *RedisStore) Ledger() *RedisLedger {
+	return &RedisLedger{client: s.client}
+}
+
+// Post applies a transaction in Redis. The transaction and the balances
+// checked against overdraft are watched between the checks and the write,
+// so both checks hold when the postings are applied. Accounts that may
+// overdraw (the house) are only incremented, which is atomic in MULTI, so
+// posts to them from unrelated sessions do not abort each other.
+func (l *RedisLedger) Post(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	if !validTransaction(tx) {
+		return nil, ErrInvalidTransaction
+	}
+	data, err := json.Marshal(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal transaction: %w", err)
+	}
+
+	key := transactionKey(tx.ID)
+	keys := []string{key}
+	var checked []types.Posting
+	for _, posting := range tx.Postings {
+		if !mayOverdraw(posting.Account) {
+			keys = append(keys, balanceKey(posting.Account))
+			checked = append(checked, posting)
+		}
+	}
+
+	for attempt := 0; attempt < maxPostAttempts; attempt++ {
+		var result *types.Transaction
+		err := l.client.Watch(ctx, func(rtx *redis.Tx) error {
+			stored, err := rtx.Get(ctx, key).Bytes()
+			if err == nil {
+				var existing types.Transaction
+				if err := json.Unmarshal(stored, &existing); err != nil {
+					return fmt.Errorf("failed to unmarshal transaction: %w", err)
+				}
+				if !sameTransaction(&existing, tx) {
+					return ErrTransactionConflict
+				}
+				result = &existing
+				return nil
+			}
+			if err != redis.Nil {
+				return fmt.Errorf("failed to get transaction: %w", err)
+			}
+
+			if len(checked) > 0 {
+				balances, err := rtx.MGet(ctx, keys[1:]...).Result()
+				if err != nil {
+					return fmt.Errorf("failed to get balances: %w", err)
+				}
+				for i, posting := range checked {
+					var balance int64
+					if value, ok := balances[i].(string); ok {
+						if balance, err = strconv.ParseInt(value, 10, 64); err != nil {
+							return fmt.Errorf("invalid balance of %s: %w", posting.Account, err)
+						}
+					}
+					if balance+posting.Amount < 0 {
+						return ErrInsufficientFunds
+					}
+				}
+			}
+
+			_, err = rtx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.Set(ctx, key, data, 0)
+				for _, posting := range tx.Postings {
+					pipe.IncrBy(ctx, balanceKey(posting.Account), posting.Amount)
+					pipe.LPush(ctx, historyKey(posting.Account), tx.ID)
+				}
+				return nil
+			})
+			if err == nil {
+				result = tx
+			}
+			return err
+		}, keys...)
+
+		switch {
+		case err == redis.TxFailedErr:
+			continue // A concurrent post touched the same keys
+		case err == ErrTransactionConflict || err == ErrInsufficientFunds:
+			return nil, err
+		case err != nil:
+			return nil, fmt.Errorf("failed to post transaction: %w", err)
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("failed to post transaction: too much contention")
+}
+
+// GetTransaction retrieves a transaction from Redis.
+func (l *RedisLedger) GetTransaction(ctx context.Context, id string) (*types.Transaction, error) {
+	data, err := l.client.Get(ctx, transactionKey(id)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	var tx types.Transaction
+	if err := json.Unmarshal(data, &tx); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal transaction: %w", err)
+	}
+	return &tx, nil
+}
+
+// Balance returns an account's balance from Redis.
+func (l *RedisLedger) Balance(ctx context.Context, account string) (int64, error) {
+	balance, err := l.client.Get(ctx, balanceKey(account)).Int64()
+	if err != nil {
+		if err == redis.Nil {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get balance: %w", err)
+	}
+	return balance, nil
+}
+
+// Transactions returns an account's latest transactions from Redis.
+func (l *RedisLedger) Transactions(ctx context.Context, account string, limit int) ([]*types.Transaction, error) {
+	transactions := []*types.Transaction{}
+	if limit <= 0 {
+		return transactions, nil
+	}
+
+	ids, err := l.client.LRange(ctx, historyKey(account), 0, int64(limit)-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account history: %w", err)
+	}
+	if len(ids) == 0 {
+		return transactions, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = transactionKey(id)
+	}
+	values, err := l.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var tx types.Transaction
+		if err := json.Unmarshal([]byte(data), &tx); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal transaction: %w", err)
+		}
+		transactions = append(transactions, &tx)
+	}
+	return transactions, nil
+}
+
+// transactionKey generates a Redis key for a ledger transaction.
+func transactionKey(id string) string {
+	return "ledger:tx:" + id
+}
+
+// balanceKey generates a Redis key for an account balance.
+func balanceKey(account string) string {
+	return "ledger:balance:" + account
+}
+
+// historyKey generates a Redis key for the transaction IDs of an account.
+func historyKey(account string) string {
+	return "ledger:history:" + account
+}
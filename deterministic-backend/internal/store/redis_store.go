@@ -2,16 +2,25 @@ package store
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/types"
 	"github.com/redis/go-redis/v9"
 )
 
+// TTL replies for a key without an expiry and for a missing key
+const (
+	redisNoExpiry   = time.Duration(-1)
+	redisKeyMissing = time.Duration(-2)
+)
+
 // RedisStore implements the Store interface using Redis.
 // Sessions are stored as JSON with a TTL for automatic cleanup.
 type RedisStore struct {
@@ -57,7 +66,8 @@ func NewRedisStore(ttl time.Duration) (*RedisStore, error) {
 	}, nil
 }
 
-// CreateSession creates a new session in Redis.
+// CreateSession creates a new session in Redis and trims the index
+// entries of sessions that expired since.
 func (s *RedisStore) CreateSession(ctx context.Context, session *types.Session) error {
 	key := sessionKey(session.ID)
 
@@ -76,17 +86,22 @@ func (s *RedisStore) CreateSession(ctx context.Context, session *types.Session)
 		return fmt.Errorf("failed to marshal session: %w", err)
 	}
 
-	// Store in Redis with optional TTL
-	if s.ttl > 0 {
-		err = s.client.Set(ctx, key, data, s.ttl).Err()
-	} else {
-		err = s.client.Set(ctx, key, data, 0).Err()
-	}
+	// Store in Redis with optional TTL, together with its index entries
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if s.ttl > 0 {
+			pipe.Set(ctx, key, data, s.ttl)
+		} else {
+			pipe.Set(ctx, key, data, 0)
+		}
+		indexSession(ctx, pipe, session, s.ttl)
+		return nil
+	})
 
 	if err != nil {
 		return fmt.Errorf("failed to store session: %w", err)
 	}
 
+	s.trimIndexes(ctx, time.Now())
 	return nil
 }
 
@@ -110,54 +125,752 @@ func (s *RedisStore) GetSession(ctx context.Context, id string) (*types.Session,
 	return &session, nil
 }
 
-// UpdateSession updates an existing session in Redis.
-func (s *RedisStore) UpdateSession(ctx context.Context, session *types.Session) error {
-	key := sessionKey(session.ID)
+// GetSessions retrieves sessions from Redis with a single MGET.
+func (s *RedisStore) GetSessions(ctx context.Context, ids []string) ([]*types.Session, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
 
-	// Check if session exists
-	exists, err := s.client.Exists(ctx, key).Result()
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = sessionKey(id)
+	}
+
+	values, err := s.client.MGet(ctx, keys...).Result()
 	if err != nil {
-		return fmt.Errorf("failed to check session existence: %w", err)
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
 	}
-	if exists == 0 {
-		return ErrSessionNotFound
+
+	sessions := make([]*types.Session, len(ids))
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue // Missing key
+		}
+		var session types.Session
+		if err := json.Unmarshal([]byte(data), &session); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal session %s: %w", ids[i], err)
+		}
+		sessions[i] = &session
 	}
 
-	// Serialize and update
-	data, err := json.Marshal(session)
+	return sessions, nil
+}
+
+// UpdateSession updates an existing session in Redis. The session is
+// watched while its revision is compared, so of two concurrent updates
+// from the same revision only the first is stored.
+func (s *RedisStore) UpdateSession(ctx context.Context, session *types.Session) error {
+	key := sessionKey(session.ID)
+
+	updated := *session
+	updated.Revision++
+	data, err := json.Marshal(&updated)
 	if err != nil {
 		return fmt.Errorf("failed to marshal session: %w", err)
 	}
 
-	// Update with same TTL (extend if needed)
-	if s.ttl > 0 {
-		err = s.client.Set(ctx, key, data, s.ttl).Err()
-	} else {
-		err = s.client.Set(ctx, key, data, 0).Err()
-	}
+	err = s.client.Watch(ctx, func(tx *redis.Tx) error {
+		stored, err := tx.Get(ctx, key).Bytes()
+		if err != nil {
+			if err == redis.Nil {
+				return ErrSessionNotFound
+			}
+			return fmt.Errorf("failed to get session: %w", err)
+		}
+		var current types.Session
+		if err := json.Unmarshal(stored, &current); err != nil {
+			return fmt.Errorf("failed to unmarshal session: %w", err)
+		}
+		if current.Revision != session.Revision {
+			return ErrSessionConflict
+		}
 
-	if err != nil {
+		// One made persistent by a staked entry stays so
+		ttl := s.ttl
+		expiry, err := tx.TTL(ctx, key).Result()
+		if err != nil {
+			return fmt.Errorf("failed to get session TTL: %w", err)
+		}
+		if expiry == redisNoExpiry {
+			ttl = 0
+		}
+
+		// Update with same TTL (extend if needed); the status may have changed
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			if ttl > 0 {
+				pipe.Set(ctx, key, data, ttl)
+			} else {
+				pipe.Set(ctx, key, data, 0)
+			}
+			indexSession(ctx, pipe, &updated, ttl)
+			return nil
+		})
+		return err
+	}, key)
+
+	switch {
+	case err == redis.TxFailedErr:
+		return ErrSessionConflict // Updated concurrently
+	case err == ErrSessionNotFound || err == ErrSessionConflict:
+		return err
+	case err != nil:
 		return fmt.Errorf("failed to update session: %w", err)
 	}
-
+	session.Revision = updated.Revision
 	return nil
 }
 
-// DeleteSession deletes a session from Redis.
+// DeleteSession deletes a session and its index entries from Redis.
 func (s *RedisStore) DeleteSession(ctx context.Context, id string) error {
 	key := sessionKey(id)
-	err := s.client.Del(ctx, key).Err()
+
+	// Label index entries are found through the session's metadata
+	var labels map[string]string
+	session, err := s.GetSession(ctx, id)
+	if err != nil && err != ErrSessionNotFound {
+		return err
+	}
+	if session != nil {
+		labels = SessionLabels(session)
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		unindexSessions(ctx, pipe, []string{id}, labels)
+		return nil
+	})
 	if err != nil {
 		return fmt.Errorf("failed to delete session: %w", err)
 	}
 	return nil
 }
 
+// CreateEntry records a player joining a round in Redis. Free entries
+// expire with the same TTL as sessions. Staked entries are kept as long
+// as the ledger, which never expires: the entry, its round's index and
+// its session are made persistent, so the round can always be reconciled.
+func (s *RedisStore) CreateEntry(ctx context.Context, entry *types.Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal entry: %w", err)
+	}
+
+	ttl := s.ttl
+	if ttl < 0 || entry.Stake > 0 {
+		ttl = 0
+	}
+	created, err := s.client.SetNX(ctx, entryKey(entry.SessionID, entry.Track, entry.Round, entry.PlayerID), data, ttl).Result()
+	if err != nil {
+		return fmt.Errorf("failed to store entry: %w", err)
+	}
+	if !created {
+		return ErrEntryExists
+	}
+
+	// Index the player in the round for ListEntries. A persistent index
+	// (one with a staked entry) stays persistent
+	players := roundPlayersKey(entry.SessionID, entry.Track, entry.Round)
+	err = s.client.Watch(ctx, func(tx *redis.Tx) error {
+		current, err := tx.TTL(ctx, players).Result()
+		if err != nil {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.SAdd(ctx, players, entry.PlayerID)
+			switch {
+			case entry.Stake > 0:
+				pipe.Persist(ctx, players)
+				pipe.Persist(ctx, sessionKey(entry.SessionID))
+				pipe.ZRem(ctx, expiryIndexKey, entry.SessionID)
+			case ttl > 0 && current != redisNoExpiry:
+				pipe.Expire(ctx, players, ttl)
+			}
+			return nil
+		})
+		return err
+	}, players)
+	if err != nil {
+		return fmt.Errorf("failed to index entry: %w", err)
+	}
+	return nil
+}
+
+// DeleteEntry removes an entry and its index entry from Redis.
+func (s *RedisStore) DeleteEntry(ctx context.Context, sessionID string, track int, round int64, playerID string) error {
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, entryKey(sessionID, track, round, playerID))
+		pipe.SRem(ctx, roundPlayersKey(sessionID, track, round), playerID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete entry: %w", err)
+	}
+	return nil
+}
+
+// GetEntry retrieves a player's entry in a round from Redis.
+func (s *RedisStore) GetEntry(ctx context.Context, sessionID string, track int, round int64, playerID string) (*types.Entry, error) {
+	data, err := s.client.Get(ctx, entryKey(sessionID, track, round, playerID)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrEntryNotFound
+		}
+		return nil, fmt.Errorf("failed to get entry: %w", err)
+	}
+
+	var entry types.Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal entry: %w", err)
+	}
+	return &entry, nil
+}
+
+// ListEntries retrieves every entry of a round from Redis.
+func (s *RedisStore) ListEntries(ctx context.Context, sessionID string, track int, round int64) ([]*types.Entry, error) {
+	players, err := s.client.SMembers(ctx, roundPlayersKey(sessionID, track, round)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list round players: %w", err)
+	}
+	entries := []*types.Entry{}
+	if len(players) == 0 {
+		return entries, nil
+	}
+	sort.Strings(players)
+
+	keys := make([]string, len(players))
+	for i, playerID := range players {
+		keys[i] = entryKey(sessionID, track, round, playerID)
+	}
+	values, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entries: %w", err)
+	}
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue // Expired
+		}
+		var entry types.Entry
+		if err := json.Unmarshal([]byte(data), &entry); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal entry: %w", err)
+		}
+		entries = append(entries, &entry)
+	}
+	return entries, nil
+}
+
+// SettleEntry stores the result of a joined entry in Redis. The entry is
+// watched between the check and the write, so of two concurrent
+// settlements the second fails with ErrEntrySettled.
+func (s *RedisStore) SettleEntry(ctx context.Context, entry *types.Entry) error {
+	key := entryKey(entry.SessionID, entry.Track, entry.Round, entry.PlayerID)
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal entry: %w", err)
+	}
+
+	err = s.client.Watch(ctx, func(tx *redis.Tx) error {
+		stored, err := tx.Get(ctx, key).Bytes()
+		if err != nil {
+			if err == redis.Nil {
+				return ErrEntryNotFound
+			}
+			return fmt.Errorf("failed to get entry: %w", err)
+		}
+		var current types.Entry
+		if err := json.Unmarshal(stored, &current); err != nil {
+			return fmt.Errorf("failed to unmarshal entry: %w", err)
+		}
+		if current.Settled() {
+			return ErrEntrySettled
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, data, redis.KeepTTL)
+			return nil
+		})
+		return err
+	}, key)
+
+	switch {
+	case err == redis.TxFailedErr:
+		return ErrEntrySettled // Settled concurrently
+	case err == ErrEntryNotFound || err == ErrEntrySettled:
+		return err
+	case err != nil:
+		return fmt.Errorf("failed to settle entry: %w", err)
+	}
+	return nil
+}
+
+// dailyLeaderboardTTL keeps a day's leaderboards for a week after the day
+const dailyLeaderboardTTL = 8 * 24 * time.Hour
+
+// RecordCashOut updates the leaderboard sorted sets in Redis. The player's
+// streak is watched while it is extended; scores are only ever raised
+// (ZADD GT).
+func (s *RedisStore) RecordCashOut(ctx context.Context, entry *types.Entry, mode string, score int64) error {
+	key := streakKey(entry.SessionID, entry.Track, entry.PlayerID)
+
+	err := s.client.Watch(ctx, func(tx *redis.Tx) error {
+		var streak Streak
+		data, err := tx.Get(ctx, key).Bytes()
+		if err != nil && err != redis.Nil {
+			return fmt.Errorf("failed to get streak: %w", err)
+		}
+		if err == nil {
+			if err := json.Unmarshal(data, &streak); err != nil {
+				return fmt.Errorf("failed to unmarshal streak: %w", err)
+			}
+		}
+		streak = streak.Extend(entry.Round)
+		data, err = json.Marshal(streak)
+		if err != nil {
+			return fmt.Errorf("failed to marshal streak: %w", err)
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, data, s.ttl)
+			for _, scope := range CashOutScopes(entry, mode) {
+				for board, score := range map[string]int64{types.BoardCashOut: score, types.BoardSurvival: streak.Length} {
+					boardKey := leaderboardKey(board, scope)
+					pipe.ZAddGT(ctx, boardKey, redis.Z{Score: float64(score), Member: entry.PlayerID})
+					switch {
+					case scope.Scope == types.ScopeDay:
+						pipe.Expire(ctx, boardKey, dailyLeaderboardTTL)
+					case scope.Scope == types.ScopeSession && s.ttl > 0:
+						pipe.Expire(ctx, boardKey, s.ttl)
+					}
+				}
+			}
+			return nil
+		})
+		return err
+	}, key)
+	if err != nil {
+		return fmt.Errorf("failed to record cash-out: %w", err)
+	}
+	return nil
+}
+
+// Leaderboard returns the top of a leaderboard sorted set from Redis.
+func (s *RedisStore) Leaderboard(ctx context.Context, board string, scope LeaderboardScope, limit int) ([]types.LeaderboardEntry, error) {
+	entries := []types.LeaderboardEntry{}
+	if limit <= 0 {
+		return entries, nil
+	}
+
+	scores, err := s.client.ZRevRangeWithScores(ctx, leaderboardKey(board, scope), 0, int64(limit)-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get leaderboard: %w", err)
+	}
+	for i, z := range scores {
+		playerID, _ := z.Member.(string)
+		entries = append(entries, types.LeaderboardEntry{
+			Rank:     i + 1,
+			PlayerID: playerID,
+			Score:    int64(z.Score),
+		})
+	}
+	return entries, nil
+}
+
+// ReserveIdempotencyKey stores an idempotency record in Redis for the
+// lease unless the key is taken (SETNX), in which case the stored record
+// is returned.
+func (s *RedisStore) ReserveIdempotencyKey(ctx context.Context, record *types.IdempotencyRecord, lease time.Duration) (*types.IdempotencyRecord, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal idempotency record: %w", err)
+	}
+
+	key := idempotencyKey(record.Key)
+	// The stored record may expire between SETNX and GET; try again then
+	for attempt := 0; attempt < 3; attempt++ {
+		reserved, err := s.client.SetNX(ctx, key, data, lease).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+		}
+		if reserved {
+			return record, nil
+		}
+
+		stored, err := s.client.Get(ctx, key).Bytes()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get idempotency record: %w", err)
+		}
+		var existing types.IdempotencyRecord
+		if err := json.Unmarshal(stored, &existing); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal idempotency record: %w", err)
+		}
+		return &existing, ErrIdempotencyKeyExists
+	}
+	return nil, fmt.Errorf("failed to reserve idempotency key: key keeps expiring")
+}
+
+// CompleteIdempotencyKey overwrites a reserved idempotency record in
+// Redis, which then expires after the window.
+func (s *RedisStore) CompleteIdempotencyKey(ctx context.Context, record *types.IdempotencyRecord, window time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal idempotency record: %w", err)
+	}
+	if err := s.client.SetArgs(ctx, idempotencyKey(record.Key), data, redis.SetArgs{Mode: "XX", TTL: window}).Err(); err != nil && err != redis.Nil {
+		return fmt.Errorf("failed to store idempotency record: %w", err)
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey deletes an idempotency record from Redis.
+func (s *RedisStore) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	if err := s.client.Del(ctx, idempotencyKey(key)).Err(); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// ListSessions lists sessions through the secondary indexes.
+//
+// It walks the most selective index for the query, newest first, loads
+// the sessions in chunks and applies every filter to them. The cursor is
+// the creation time and ID of the last session examined. A page may hold
+// fewer than Limit sessions while NextCursor is set, when listMaxScan
+// entries were examined without filling it.
+func (s *RedisStore) ListSessions(ctx context.Context, query SessionQuery) (*SessionPage, error) {
+	index := queryIndexKey(query)
+
+	// Scores are Unix milliseconds, so the bounds are widened to whole
+	// milliseconds and the exact times checked by Matches
+	min, max := "-inf", "+inf"
+	if query.CreatedAfter != nil {
+		min = strconv.FormatInt(query.CreatedAfter.UnixMilli(), 10)
+	}
+	if query.CreatedBefore != nil {
+		max = strconv.FormatInt(query.CreatedBefore.UnixMilli(), 10)
+	}
+	var after *listCursor
+	if query.Cursor != "" {
+		cursor, err := decodeListCursor(query.Cursor)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		after = &cursor
+		max = strconv.FormatInt(cursor.createdMs, 10)
+	}
+
+	page := &SessionPage{}
+	var last listCursor
+	for offset, scanned := int64(0), 0; scanned < listMaxScan; {
+		entries, err := s.client.ZRangeArgsWithScores(ctx, redis.ZRangeArgs{
+			Key:     index,
+			Start:   min,
+			Stop:    max,
+			ByScore: true,
+			Rev:     true,
+			Offset:  offset,
+			Count:   listScanChunk,
+		}).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list sessions: %w", err)
+		}
+		offset += int64(len(entries))
+
+		// Equal scores come by descending ID; those up to the cursor
+		// were listed already
+		ids := make([]string, 0, len(entries))
+		positions := make([]listCursor, 0, len(entries))
+		for _, entry := range entries {
+			id, _ := entry.Member.(string)
+			position := listCursor{createdMs: int64(entry.Score), id: id}
+			if after != nil && position.createdMs == after.createdMs && id >= after.id {
+				continue
+			}
+			ids = append(ids, id)
+			positions = append(positions, position)
+		}
+
+		sessions, err := s.GetSessions(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		var stale []string
+		for i, session := range sessions {
+			scanned++
+			last = positions[i]
+			if session == nil {
+				stale = append(stale, ids[i]) // Expired through the TTL
+				continue
+			}
+			if !query.Matches(session) {
+				continue
+			}
+			page.Sessions = append(page.Sessions, session)
+			if len(page.Sessions) == query.Limit {
+				break
+			}
+		}
+		s.pruneIndexes(ctx, index, stale)
+
+		if len(page.Sessions) == query.Limit {
+			page.NextCursor = last.encode()
+			return page, nil
+		}
+		if len(entries) < listScanChunk {
+			return page, nil
+		}
+	}
+
+	page.NextCursor = last.encode()
+	return page, nil
+}
+
+// Secondary indexes are sorted sets of session IDs scored by creation time
+// in Unix milliseconds: every session is in createdIndexKey, in the index
+// of its stored status and in the index of each of its labels.
+const createdIndexKey = "sessions:created"
+
+// expiryIndexKey is a sorted set of the sessions whose key has a TTL,
+// scored by when it expires in Unix milliseconds. It finds the index
+// entries of expired sessions to trim; sessions kept without a TTL (by a
+// staked entry) are not in it.
+const expiryIndexKey = "sessions:expiry"
+
+// indexTrimBatch is the most expired sessions trimmed per session created,
+// more than are created, so the indexes do not grow with expired sessions.
+const indexTrimBatch = 100
+
+// Session listing bounds
+const (
+	listScanChunk = 100  // Index entries loaded per round trip
+	listMaxScan   = 1000 // Index entries examined per page at most
+)
+
+// sessionStatuses are the statuses with a status index.
+var sessionStatuses = []string{
+	types.PhaseRunning,
+	types.PhasePaused,
+	types.PhaseStopped,
+	types.PhaseExpired,
+	types.PhaseFinished,
+}
+
+// statusIndexKey generates the Redis key of a status index.
+func statusIndexKey(status string) string {
+	return fmt.Sprintf("sessions:status:%s", status)
+}
+
+// labelIndexKey generates the Redis key of a label index.
+func labelIndexKey(key, value string) string {
+	return fmt.Sprintf("sessions:label:%s=%s", key, value)
+}
+
+// phaseIndexStatus maps a phase to the stored status whose index holds
+// every session in that phase. An expired or finished session is stored
+// as running or paused until it is settled, so those phases have none.
+var phaseIndexStatus = map[string]string{
+	types.PhaseScheduled: types.PhaseRunning,
+	types.PhaseRunning:   types.PhaseRunning,
+	types.PhasePaused:    types.PhasePaused,
+	types.PhaseStopped:   types.PhaseStopped,
+}
+
+// queryIndexKey picks the index to walk for a query: the index of the
+// stored status that holds its phase, else the index of the first label,
+// else all sessions.
+func queryIndexKey(query SessionQuery) string {
+	if status, ok := phaseIndexStatus[query.Status]; ok {
+		return statusIndexKey(status)
+	}
+	if len(query.Labels) > 0 {
+		keys := make([]string, 0, len(query.Labels))
+		for key := range query.Labels {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return labelIndexKey(keys[0], query.Labels[keys[0]])
+	}
+	return createdIndexKey
+}
+
+// indexSession adds a session to its indexes and removes it from the
+// indexes of other statuses. ttl is the TTL its key was stored with
+// (0 = none): label indexes are kept as long as their newest session, and
+// the session is tracked in expiryIndexKey until it expires.
+func indexSession(ctx context.Context, pipe redis.Pipeliner, session *types.Session, ttl time.Duration) {
+	member := redis.Z{Score: float64(session.CreatedAt.UnixMilli()), Member: session.ID}
+	pipe.ZAdd(ctx, createdIndexKey, member)
+	for _, status := range sessionStatuses {
+		if status != session.Status {
+			pipe.ZRem(ctx, statusIndexKey(status), session.ID)
+		}
+	}
+	pipe.ZAdd(ctx, statusIndexKey(session.Status), member)
+	for key, value := range SessionLabels(session) {
+		pipe.ZAdd(ctx, labelIndexKey(key, value), member)
+		if ttl > 0 {
+			pipe.Expire(ctx, labelIndexKey(key, value), ttl)
+		}
+	}
+	if ttl > 0 {
+		pipe.ZAdd(ctx, expiryIndexKey, redis.Z{Score: float64(time.Now().Add(ttl).UnixMilli()), Member: session.ID})
+	} else {
+		pipe.ZRem(ctx, expiryIndexKey, session.ID)
+	}
+}
+
+// unindexSessions removes sessions from the created, expiry and status
+// indexes and from the given label indexes.
+func unindexSessions(ctx context.Context, pipe redis.Pipeliner, ids []string, labels map[string]string) {
+	members := make([]interface{}, len(ids))
+	for i, id := range ids {
+		members[i] = id
+	}
+	pipe.ZRem(ctx, createdIndexKey, members...)
+	pipe.ZRem(ctx, expiryIndexKey, members...)
+	for _, status := range sessionStatuses {
+		pipe.ZRem(ctx, statusIndexKey(status), members...)
+	}
+	for key, value := range labels {
+		pipe.ZRem(ctx, labelIndexKey(key, value), members...)
+	}
+}
+
+// pruneIndexes removes sessions that expired through the TTL from the
+// created and status indexes and from the index being listed. Their other
+// label index entries are pruned when those are listed. Pruning is best
+// effort: a failure only leaves entries to skip again.
+func (s *RedisStore) pruneIndexes(ctx context.Context, index string, ids []string) {
+	if len(ids) == 0 {
+		return
+	}
+	s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		unindexSessions(ctx, pipe, ids, nil)
+		if index != createdIndexKey {
+			members := make([]interface{}, len(ids))
+			for i, id := range ids {
+				members[i] = id
+			}
+			pipe.ZRem(ctx, index, members...)
+		}
+		return nil
+	})
+}
+
+// trimIndexes removes up to indexTrimBatch sessions past their expiry in
+// expiryIndexKey from the created and status indexes. Each key is checked
+// first: one persisted or given a new TTL since is only rescored. Label
+// index entries expire with their index or are pruned when listed.
+// Trimming is best effort: a failure leaves entries for the next time.
+func (s *RedisStore) trimIndexes(ctx context.Context, now time.Time) {
+	ids, err := s.client.ZRangeArgs(ctx, redis.ZRangeArgs{
+		Key:     expiryIndexKey,
+		Start:   "-inf",
+		Stop:    strconv.FormatInt(now.UnixMilli(), 10),
+		ByScore: true,
+		Count:   indexTrimBatch,
+	}).Result()
+	if err != nil || len(ids) == 0 {
+		return
+	}
+
+	ttls := make([]*redis.DurationCmd, len(ids))
+	_, err = s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, id := range ids {
+			ttls[i] = pipe.TTL(ctx, sessionKey(id))
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		var expired []string
+		for i, id := range ids {
+			switch ttl := ttls[i].Val(); {
+			case ttl == redisKeyMissing:
+				expired = append(expired, id)
+			case ttl == redisNoExpiry:
+				pipe.ZRem(ctx, expiryIndexKey, id)
+			default:
+				pipe.ZAdd(ctx, expiryIndexKey, redis.Z{Score: float64(now.Add(ttl).UnixMilli()), Member: id})
+			}
+		}
+		if len(expired) > 0 {
+			unindexSessions(ctx, pipe, expired, nil)
+		}
+		return nil
+	})
+}
+
+// listCursor is a position in a session listing.
+type listCursor struct {
+	createdMs int64
+	id        string
+}
+
+// encode returns the cursor in its opaque form.
+func (c listCursor) encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%s", c.createdMs, c.id)))
+}
+
+// decodeListCursor parses a cursor returned by encode.
+func decodeListCursor(s string) (listCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return listCursor{}, err
+	}
+	ms, id, ok := strings.Cut(string(data), ":")
+	if !ok || id == "" {
+		return listCursor{}, fmt.Errorf("malformed cursor")
+	}
+	createdMs, err := strconv.ParseInt(ms, 10, 64)
+	if err != nil {
+		return listCursor{}, err
+	}
+	return listCursor{createdMs: createdMs, id: id}, nil
+}
+
 // sessionKey generates a Redis key for a session.
 func sessionKey(id string) string {
 	return fmt.Sprintf("session:%s", id)
 }
 
+// entryKey generates a Redis key for a player's entry in a round.
+func entryKey(sessionID string, track int, round int64, playerID string) string {
+	return fmt.Sprintf("entry:%s:%d:%d:%s", sessionID, track, round, playerID)
+}
+
+// roundPlayersKey generates a Redis key for the set of players in a round.
+func roundPlayersKey(sessionID string, track int, round int64) string {
+	return fmt.Sprintf("entries:%s:%d:%d", sessionID, track, round)
+}
+
+// streakKey generates a Redis key for a player's streak on a session track.
+func streakKey(sessionID string, track int, playerID string) string {
+	return fmt.Sprintf("streak:%s:%d:%s", sessionID, track, playerID)
+}
+
+// leaderboardKey generates a Redis key for a leaderboard.
+func leaderboardKey(board string, scope LeaderboardScope) string {
+	switch scope.Scope {
+	case types.ScopeSession:
+		return fmt.Sprintf("leaderboard:%s:session:%s", board, scope.SessionID)
+	case types.ScopeDay:
+		return fmt.Sprintf("leaderboard:%s:%s:day:%s", board, scope.Mode, scope.Day)
+	}
+	return fmt.Sprintf("leaderboard:%s:%s:all", board, scope.Mode)
+}
+
+// idempotencyKey generates a Redis key for an Idempotency-Key record.
+func idempotencyKey(key string) string {
+	return fmt.Sprintf("idempotency:%s", key)
+}
+
 // getEnv gets an environment variable or returns a default value.
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
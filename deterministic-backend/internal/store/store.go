@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"time"
 
 	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/types"
 )
@@ -16,17 +17,73 @@ type Store interface {
 	// GetSession retrieves a session by ID
 	GetSession(ctx context.Context, id string) (*types.Session, error)
 
-	// UpdateSession updates an existing session
+	// GetSessions retrieves sessions by ID in one round trip. The result
+	// is aligned with ids; missing sessions are nil
+	GetSessions(ctx context.Context, ids []string) ([]*types.Session, error)
+
+	// ListSessions lists the sessions matching a query, newest first, one
+	// page at a time
+	ListSessions(ctx context.Context, query SessionQuery) (*SessionPage, error)
+
+	// UpdateSession updates an existing session and bumps its Revision.
+	// It fails with ErrSessionConflict if the stored session's revision
+	// is no longer the one the session was loaded with
 	UpdateSession(ctx context.Context, session *types.Session) error
 
 	// DeleteSession deletes a session (optional, for cleanup)
 	DeleteSession(ctx context.Context, id string) error
+
+	// CreateEntry records a player joining a round
+	CreateEntry(ctx context.Context, entry *types.Entry) error
+
+	// GetEntry retrieves a player's entry in a round
+	GetEntry(ctx context.Context, sessionID string, track int, round int64, playerID string) (*types.Entry, error)
+
+	// DeleteEntry removes an entry, e.g. one whose stake could not be
+	// debited
+	DeleteEntry(ctx context.Context, sessionID string, track int, round int64, playerID string) error
+
+	// ListEntries retrieves every entry of a round, ordered by player ID
+	ListEntries(ctx context.Context, sessionID string, track int, round int64) ([]*types.Entry, error)
+
+	// SettleEntry stores the result of an entry that is still joined.
+	// Concurrent settlements of one entry are serialized: only the first
+	// succeeds
+	SettleEntry(ctx context.Context, entry *types.Entry) error
+
+	// RecordCashOut raises the player's scores on the leaderboards of
+	// CashOutScopes for the session's mode: the given cash-out score, and
+	// the streak it extends
+	RecordCashOut(ctx context.Context, entry *types.Entry, mode string, score int64) error
+
+	// Leaderboard returns the best players of a board, best first
+	Leaderboard(ctx context.Context, board string, scope LeaderboardScope, limit int) ([]types.LeaderboardEntry, error)
+
+	// ReserveIdempotencyKey stores an in-progress record for a new
+	// Idempotency-Key, which expires after lease unless it is completed or
+	// released first. If the key is already taken, the stored record is
+	// returned with ErrIdempotencyKeyExists
+	ReserveIdempotencyKey(ctx context.Context, record *types.IdempotencyRecord, lease time.Duration) (*types.IdempotencyRecord, error)
+
+	// CompleteIdempotencyKey stores the response of a reserved key,
+	// remembered for the given window from now
+	CompleteIdempotencyKey(ctx context.Context, record *types.IdempotencyRecord, window time.Duration) error
+
+	// ReleaseIdempotencyKey forgets a reserved key whose request failed,
+	// so it can be retried
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
 }
 
 // Errors
 var (
-	ErrSessionNotFound = &StoreError{Message: "session not found"}
-	ErrSessionExists  = &StoreError{Message: "session already exists"}
+	ErrSessionNotFound      = &StoreError{Message: "session not found"}
+	ErrSessionExists        = &StoreError{Message: "session already exists"}
+	ErrSessionConflict      = &StoreError{Message: "session was updated concurrently"}
+	ErrInvalidCursor        = &StoreError{Message: "invalid cursor"}
+	ErrEntryNotFound        = &StoreError{Message: "entry not found"}
+	ErrEntryExists          = &StoreError{Message: "player already joined this round"}
+	ErrEntrySettled         = &StoreError{Message: "entry already settled"}
+	ErrIdempotencyKeyExists = &StoreError{Message: "idempotency key already used"}
 )
 
 // StoreError represents a storage error
@@ -37,4 +94,3 @@ type StoreError struct {
 func (e *StoreError) Error() string {
 	return e.Message
 }
-
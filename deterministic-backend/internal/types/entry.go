@@ -0,0 +1,71 @@
+package types
+
+import "time"
+
+// Entry is a player's participation in one round of a session track
+type Entry struct {
+	SessionID string     `json:"session_id"`
+	Track     int        `json:"track"`
+	Round     int64      `json:"round"`
+	PlayerID  string     `json:"player_id"`
+	Status    string     `json:"status"` // "joined", "cashed_out", "lost" or "voided"
+	JoinedAt  time.Time  `json:"joined_at"`
+	SettledAt *time.Time `json:"settled_at,omitempty"` // When the entry cashed out or was found lost
+	Step      int64      `json:"step,omitempty"`       // Cash-out step
+	Value     int64      `json:"value,omitempty"`      // Value at the cash-out step
+	Stake     int64      `json:"stake,omitempty"`      // Debited from the player's wallet on join
+	Payout    int64      `json:"payout,omitempty"`     // Credited on cash-out
+}
+
+// Entry statuses.
+//
+// An entry is joined until it is settled: cashed out before its round
+// broke, lost once the round broke without a cash-out, or voided when the
+// session ended before the round broke. Voided stakes are refunded.
+const (
+	EntryJoined    = "joined"
+	EntryCashedOut = "cashed_out"
+	EntryLost      = "lost"
+	EntryVoided    = "voided"
+)
+
+// Settled reports whether the entry has its final result.
+func (e *Entry) Settled() bool {
+	return e.Status != EntryJoined
+}
+
+// PlayerTokenResponse represents a token a player authenticates with
+type PlayerTokenResponse struct {
+	PlayerID  string `json:"player_id"`
+	Token     string `json:"token"`      // Sent as X-Player-Token
+	ExpiresAt string `json:"expires_at"` // RFC3339
+}
+
+// JoinRoundRequest represents a request to join a round
+type JoinRoundRequest struct {
+	PlayerID string `json:"player_id"`
+	Track    int    `json:"track,omitempty"` // Track of a multi-track session (default 0)
+	Stake    int64  `json:"stake,omitempty"` // Minor units debited from the player's wallet (0 = free play)
+}
+
+// CashOutRequest represents a request to cash out of a round
+type CashOutRequest struct {
+	PlayerID string `json:"player_id"`
+	Track    int    `json:"track,omitempty"` // Track of a multi-track session (default 0)
+}
+
+// EntryResponse represents a player's entry in a round
+type EntryResponse struct {
+	SessionID string      `json:"session_id"`
+	Track     int         `json:"track"`
+	Round     int64       `json:"round"`
+	PlayerID  string      `json:"player_id"`
+	Status    string      `json:"status"`               // "joined", "cashed_out", "lost" or "voided"
+	JoinedAt  string      `json:"joined_at"`            // RFC3339
+	SettledAt *string     `json:"settled_at,omitempty"` // RFC3339
+	Step      *int64      `json:"step,omitempty"`       // Cash-out step (cashed_out only)
+	Value     *int64      `json:"value,omitempty"`      // Value won (cashed_out only)
+	Outcome   interface{} `json:"outcome,omitempty"`    // Rule-specific state at the cash-out step (e.g. multiplier)
+	Stake     int64       `json:"stake,omitempty"`      // Staked on join
+	Payout    *int64      `json:"payout,omitempty"`     // Credited on cash-out (staked entries only)
+}
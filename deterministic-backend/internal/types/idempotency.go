@@ -0,0 +1,18 @@
+package types
+
+import "time"
+
+// IdempotencyRecord remembers a request made with an Idempotency-Key, so
+// a retry replays its response instead of running again.
+type IdempotencyRecord struct {
+	Key         string    `json:"key"`
+	RequestHash string    `json:"request_hash"`     // SHA-256 of the request, hex
+	Status      int       `json:"status,omitempty"` // Response status (0 = still in progress)
+	Body        []byte    `json:"body,omitempty"`   // Response body
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Completed reports whether the response has been stored
+func (r *IdempotencyRecord) Completed() bool {
+	return r.Status != 0
+}
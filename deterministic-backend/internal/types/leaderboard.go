@@ -0,0 +1,32 @@
+package types
+
+// Leaderboards rank players by their best score. Each board is kept per
+// session, and per game mode for each UTC day and for all time.
+const (
+	BoardCashOut  = "cashout"  // Best cash-out multiplier (in hundredths) or value
+	BoardSurvival = "survival" // Most consecutive rounds survived (cashed out) on a session track
+)
+
+// Leaderboard scopes.
+const (
+	ScopeSession = "session"
+	ScopeDay     = "day"
+	ScopeAllTime = "all"
+)
+
+// LeaderboardEntry is one player's position on a leaderboard
+type LeaderboardEntry struct {
+	Rank     int    `json:"rank"` // 1-based
+	PlayerID string `json:"player_id"`
+	Score    int64  `json:"score"`
+}
+
+// LeaderboardResponse represents the top of a leaderboard
+type LeaderboardResponse struct {
+	Board     string             `json:"board"` // "cashout" or "survival"
+	Scope     string             `json:"scope"` // "session", "day" or "all"
+	SessionID string             `json:"session_id,omitempty"`
+	Day       string             `json:"day,omitempty"`  // YYYY-MM-DD (UTC)
+	Mode      string             `json:"mode,omitempty"` // Game mode (day and all-time scopes)
+	Entries   []LeaderboardEntry `json:"entries"`        // Best first
+}
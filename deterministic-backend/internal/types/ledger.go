@@ -0,0 +1,99 @@
+package types
+
+import "time"
+
+// Transaction is a balanced set of postings in the wallet ledger: the
+// amounts of its postings sum to zero. Amounts are in minor units.
+type Transaction struct {
+	ID        string    `json:"id"`   // Unique; posting the same ID again is a no-op
+	Kind      string    `json:"kind"` // "deposit", "withdrawal", "stake", "payout" or "refund"
+	Postings  []Posting `json:"postings"`
+	SessionID string    `json:"session_id,omitempty"` // Round the transaction belongs to (round kinds only)
+	Track     int       `json:"track,omitempty"`
+	Round     int64     `json:"round,omitempty"`
+	PlayerID  string    `json:"player_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Posting moves an amount into (positive) or out of (negative) an account.
+type Posting struct {
+	Account string `json:"account"`
+	Amount  int64  `json:"amount"`
+}
+
+// Transaction kinds.
+const (
+	TransactionDeposit    = "deposit"    // External → player
+	TransactionWithdrawal = "withdrawal" // Player → external
+	TransactionStake      = "stake"      // Player → house, when joining a round
+	TransactionPayout     = "payout"     // House → player, on cash-out
+	TransactionRefund     = "refund"     // House → player, when a round is voided
+)
+
+// Ledger accounts. Player accounts cannot go negative; the house and
+// external accounts can.
+const (
+	AccountHouse        = "house"    // Takes stakes and pays payouts and refunds
+	AccountExternal     = "external" // Money entering or leaving the ledger
+	PlayerAccountPrefix = "player:"
+)
+
+// PlayerAccount returns the ledger account of a player.
+func PlayerAccount(playerID string) string {
+	return PlayerAccountPrefix + playerID
+}
+
+// Amount returns the transaction's posting to an account, 0 if none.
+func (t *Transaction) Amount(account string) int64 {
+	var amount int64
+	for _, posting := range t.Postings {
+		if posting.Account == account {
+			amount += posting.Amount
+		}
+	}
+	return amount
+}
+
+// WalletTransferRequest represents a deposit or withdrawal request
+type WalletTransferRequest struct {
+	TransactionID string `json:"transaction_id"` // Client-chosen; retries with the same ID are not applied twice
+	Amount        int64  `json:"amount"`         // Minor units, positive
+}
+
+// WalletResponse represents a player's balance and recent transactions
+type WalletResponse struct {
+	PlayerID     string                `json:"player_id"`
+	Balance      int64                 `json:"balance"`
+	Transactions []TransactionResponse `json:"transactions"` // Newest first
+}
+
+// TransactionResponse represents a ledger transaction
+type TransactionResponse struct {
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	Postings  []Posting `json:"postings"`
+	SessionID string    `json:"session_id,omitempty"`
+	Track     *int      `json:"track,omitempty"`
+	Round     *int64    `json:"round,omitempty"`
+	PlayerID  string    `json:"player_id,omitempty"`
+	CreatedAt string    `json:"created_at"` // RFC3339
+}
+
+// ReconcileResponse reports how a round's ledger compares with its
+// deterministic results
+type ReconcileResponse struct {
+	SessionID string           `json:"session_id"`
+	Track     int              `json:"track"`
+	Round     int64            `json:"round"`
+	Entries   int              `json:"entries"`  // Entries checked
+	Repaired  []string         `json:"repaired"` // IDs of missing payouts and refunds posted
+	Issues    []ReconcileIssue `json:"issues"`   // Discrepancies that were not repaired
+}
+
+// ReconcileIssue is a discrepancy between an entry and the ledger
+type ReconcileIssue struct {
+	PlayerID string `json:"player_id"`
+	Issue    string `json:"issue"`
+	Expected int64  `json:"expected"`
+	Actual   int64  `json:"actual"`
+}
@@ -7,14 +7,142 @@ import (
 
 // Session represents a deterministic real-time session
 type Session struct {
-	ID        string          `json:"id"`
-	Seed      string          `json:"seed"` // UUID or uint64 as string
-	StartAt   time.Time       `json:"start_at"`
-	TickMs    int             `json:"tick_ms"`
-	Metadata  json.RawMessage `json:"metadata,omitempty"`
-	Status    string          `json:"status"` // "running", "stopped"
-	CreatedAt time.Time       `json:"created_at"`
-	StoppedAt *time.Time      `json:"stopped_at,omitempty"`
+	ID                string             `json:"id"`
+	Seed              string             `json:"seed"`                  // Server seed (UUID or uint64 as string), secret until stopped
+	SeedHash          string             `json:"seed_hash,omitempty"`   // SHA-256 commitment of Seed
+	ClientSeed        string             `json:"client_seed,omitempty"` // Optional client-supplied seed mixed into Seed
+	Nonce             int64              `json:"nonce,omitempty"`       // Optional nonce mixed into Seed
+	StartAt           time.Time          `json:"start_at"`
+	TickMs            int                `json:"tick_ms"`                      // Tick interval (first tick interval with a schedule)
+	TickSchedule      *TickSchedule      `json:"tick_schedule,omitempty"`      // Variable tick rate, nil = TickMs throughout
+	EngineVersion     int                `json:"engine_version,omitempty"`     // Engine algorithm version, 0 = version 1
+	Mode              string             `json:"mode,omitempty"`               // Game rule, empty = "counter"
+	ModeParams        map[string]float64 `json:"mode_params,omitempty"`        // Rule parameters
+	Interval          *IntervalConfig    `json:"interval,omitempty"`           // Break interval distribution, nil = uniform [100, 300]
+	Tracks            int                `json:"tracks,omitempty"`             // Independent tracks with derived sub-seeds, 0 = 1
+	LobbyTicks        int64              `json:"lobby_ticks,omitempty"`        // Countdown ticks before round 0
+	IntermissionTicks int64              `json:"intermission_ticks,omitempty"` // Cooldown ticks after every break
+	End               *EndCondition      `json:"end,omitempty"`                // Deterministic end conditions, nil = runs until stopped
+	Metadata          json.RawMessage    `json:"metadata,omitempty"`
+	Status            string             `json:"status"`           // "running", "paused", "stopped", "expired" or "finished"
+	Pauses            []PauseWindow      `json:"pauses,omitempty"` // Pause windows, excluded from active time
+	CreatedAt         time.Time          `json:"created_at"`
+	ExpiresAt         *time.Time         `json:"expires_at,omitempty"`   // When the session expires, nil = never
+	StoppedAt         *time.Time         `json:"stopped_at,omitempty"`   // When the session stopped, expired or was found finished
+	FinalState        *FinalState        `json:"final_state,omitempty"`  // State frozen at StoppedAt (track 0)
+	FinalTracks       []FinalState       `json:"final_tracks,omitempty"` // State of every track frozen at StoppedAt (multi-track only)
+	Revision          int64              `json:"revision,omitempty"`     // Stored updates so far, checked by every update
+}
+
+// Session lifecycle phases.
+//
+// Status stores the phases entered through transitions (running, paused,
+// stopped, expired, finished). Scheduled is derived: a running session
+// whose start_at is still in the future. Finished sessions met their end
+// condition.
+const (
+	PhaseScheduled = "scheduled"
+	PhaseRunning   = "running"
+	PhasePaused    = "paused"
+	PhaseStopped   = "stopped"
+	PhaseExpired   = "expired"
+	PhaseFinished  = "finished"
+)
+
+// Phase returns the lifecycle phase of the session at the given time.
+// A session past ExpiresAt is expired even if that is not stored yet.
+func (s *Session) Phase(now time.Time) string {
+	if s.Ended() {
+		return s.Status
+	}
+	if s.ExpiresAt != nil && !now.Before(*s.ExpiresAt) {
+		return PhaseExpired
+	}
+	if s.Status == PhasePaused {
+		return PhasePaused
+	}
+	if now.Before(s.StartAt) {
+		return PhaseScheduled
+	}
+	return PhaseRunning
+}
+
+// PhaseAt returns the lifecycle phase the session was in at the given
+// time. Unlike Phase it does not take the current status for the present:
+// pauses and the end only count from when they happened.
+func (s *Session) PhaseAt(at time.Time) string {
+	if s.Ended() && s.StoppedAt != nil && !at.Before(*s.StoppedAt) {
+		return s.Status
+	}
+	if s.ExpiresAt != nil && !at.Before(*s.ExpiresAt) {
+		return PhaseExpired
+	}
+	for _, p := range s.Pauses {
+		if !at.Before(p.PausedAt) && (p.ResumedAt == nil || at.Before(*p.ResumedAt)) {
+			return PhasePaused
+		}
+	}
+	if at.Before(s.StartAt) {
+		return PhaseScheduled
+	}
+	return PhaseRunning
+}
+
+// Ended reports whether the session is stopped, expired or finished.
+// Ended sessions no longer advance and their seed is revealed.
+func (s *Session) Ended() bool {
+	return s.Status == PhaseStopped || s.Status == PhaseExpired || s.Status == PhaseFinished
+}
+
+// FinalState is the engine state a session ended in
+type FinalState struct {
+	Step      int64  `json:"step"`
+	Value     int64  `json:"value"`
+	Round     int64  `json:"round"`
+	Broken    bool   `json:"broken"`
+	GamePhase string `json:"game_phase,omitempty"`
+	TicksLeft int64  `json:"ticks_left,omitempty"`
+}
+
+// EndCondition ends a session deterministically at the first condition met
+type EndCondition struct {
+	MaxRounds     int64 `json:"max_rounds,omitempty"`      // End at the break that completes this many rounds
+	MaxDurationMs int64 `json:"max_duration_ms,omitempty"` // End after this much active time
+	StopAtStep    int64 `json:"stop_at_step,omitempty"`    // End at this step
+}
+
+// PauseWindow is a period during which a session did not advance
+type PauseWindow struct {
+	PausedAt  time.Time  `json:"paused_at"`
+	ResumedAt *time.Time `json:"resumed_at,omitempty"` // nil while still paused
+}
+
+// TickSchedule varies the tick rate within a session: either segments of
+// active time or a tick interval per round
+type TickSchedule struct {
+	Segments   []TickSegment `json:"segments,omitempty"`
+	RoundTicks []int64       `json:"round_ticks,omitempty"` // Tick interval of round r (last entry repeats)
+}
+
+// TickSegment is a span of active time with a fixed tick interval
+type TickSegment struct {
+	DurationMs int64 `json:"duration_ms,omitempty"` // Omitted on the last segment (runs until the end)
+	TickMs     int64 `json:"tick_ms"`
+}
+
+// IntervalConfig configures how many steps each round lasts before it breaks
+type IntervalConfig struct {
+	Min          int64              `json:"min,omitempty"`          // Minimum interval (uniform, geometric)
+	Max          int64              `json:"max,omitempty"`          // Maximum interval (uniform, geometric)
+	Distribution string             `json:"distribution,omitempty"` // "uniform" (default), "geometric" or "weighted"
+	P            float64            `json:"p,omitempty"`            // Per-step break probability (geometric)
+	Weights      []WeightedInterval `json:"weights,omitempty"`      // Interval table (weighted)
+}
+
+// WeightedInterval is one entry of a weighted interval table
+type WeightedInterval struct {
+	Interval int64 `json:"interval"`
+	Weight   int64 `json:"weight"`
 }
 
 // State represents the computed state at a given step
@@ -27,44 +155,188 @@ type State struct {
 
 // CreateSessionRequest represents a request to create a session
 type CreateSessionRequest struct {
-	TickMs   int             `json:"tick_ms"`
-	StartAt  *string         `json:"start_at,omitempty"` // Optional RFC3339 string
-	Metadata json.RawMessage `json:"metadata,omitempty"`
+	TickMs            int                `json:"tick_ms"`                      // Required unless tick_schedule is set
+	TickSchedule      *TickSchedule      `json:"tick_schedule,omitempty"`      // Optional variable tick rate
+	StartAt           *string            `json:"start_at,omitempty"`           // Optional RFC3339 string
+	EngineVersion     int                `json:"engine_version,omitempty"`     // Optional engine version (default latest)
+	Seed              string             `json:"seed,omitempty"`               // Optional explicit decimal seed (admin only)
+	ClientSeed        string             `json:"client_seed,omitempty"`        // Optional client seed mixed into the server seed
+	Nonce             int64              `json:"nonce,omitempty"`              // Optional nonce, mixed with client_seed
+	Mode              string             `json:"mode,omitempty"`               // Optional game rule (default "counter")
+	ModeParams        map[string]float64 `json:"mode_params,omitempty"`        // Optional rule parameters
+	Interval          *IntervalConfig    `json:"interval,omitempty"`           // Optional break interval distribution
+	Tracks            int                `json:"tracks,omitempty"`             // Optional number of tracks (default 1)
+	LobbyTicks        int64              `json:"lobby_ticks,omitempty"`        // Optional countdown before round 0
+	IntermissionTicks int64              `json:"intermission_ticks,omitempty"` // Optional cooldown after every break
+	End               *EndCondition      `json:"end,omitempty"`                // Optional deterministic end conditions
+	Metadata          json.RawMessage    `json:"metadata,omitempty"`
 }
 
 // CreateSessionResponse represents the response when creating a session
 type CreateSessionResponse struct {
-	ID       string          `json:"id"`
-	Seed     string          `json:"seed"` // UUID or uint64 as string
-	StartAt  string          `json:"start_at"` // RFC3339
-	TickMs   int             `json:"tick_ms"`
-	Metadata json.RawMessage `json:"metadata,omitempty"`
-	Status   string          `json:"status"` // "running"
+	ID                string             `json:"id"`
+	SeedHash          string             `json:"seed_hash"` // Commitment to the secret server seed
+	ClientSeed        string             `json:"client_seed,omitempty"`
+	Nonce             int64              `json:"nonce,omitempty"`
+	StartAt           string             `json:"start_at"` // RFC3339
+	TickMs            int                `json:"tick_ms"`
+	TickSchedule      *TickSchedule      `json:"tick_schedule,omitempty"`
+	EngineVersion     int                `json:"engine_version"`
+	Mode              string             `json:"mode"`
+	ModeParams        map[string]float64 `json:"mode_params,omitempty"`
+	Interval          *IntervalConfig    `json:"interval,omitempty"`
+	Tracks            int                `json:"tracks"`
+	LobbyTicks        int64              `json:"lobby_ticks"`
+	IntermissionTicks int64              `json:"intermission_ticks"`
+	End               *EndCondition      `json:"end,omitempty"`
+	Metadata          json.RawMessage    `json:"metadata,omitempty"`
+	Status            string             `json:"status"`               // "running"
+	Phase             string             `json:"phase"`                // "scheduled" or "running"
+	ExpiresAt         *string            `json:"expires_at,omitempty"` // RFC3339
 }
 
 // GetSessionResponse represents the response when getting a session
 type GetSessionResponse struct {
-	ID       string          `json:"id"`
-	Seed     string          `json:"seed"`
-	StartAt  string          `json:"start_at"` // RFC3339
-	TickMs   int             `json:"tick_ms"`
-	Metadata json.RawMessage `json:"metadata,omitempty"`
-	Status   string          `json:"status"` // "running" or "stopped"
+	ID                string             `json:"id"`
+	Seed              string             `json:"seed,omitempty"` // Revealed only once the session is stopped
+	SeedHash          string             `json:"seed_hash"`
+	ClientSeed        string             `json:"client_seed,omitempty"`
+	Nonce             int64              `json:"nonce,omitempty"`
+	StartAt           string             `json:"start_at"` // RFC3339
+	TickMs            int                `json:"tick_ms"`
+	TickSchedule      *TickSchedule      `json:"tick_schedule,omitempty"`
+	EngineVersion     int                `json:"engine_version"`
+	Mode              string             `json:"mode"`
+	ModeParams        map[string]float64 `json:"mode_params,omitempty"`
+	Interval          *IntervalConfig    `json:"interval,omitempty"`
+	Tracks            int                `json:"tracks"`
+	LobbyTicks        int64              `json:"lobby_ticks"`
+	IntermissionTicks int64              `json:"intermission_ticks"`
+	End               *EndCondition      `json:"end,omitempty"`
+	Metadata          json.RawMessage    `json:"metadata,omitempty"`
+	Status            string             `json:"status"` // "running", "paused", "stopped", "expired" or "finished"
+	Phase             string             `json:"phase"`  // Lifecycle phase, see PhaseScheduled etc.
+	Pauses            []PauseWindow      `json:"pauses,omitempty"`
+	ExpiresAt         *string            `json:"expires_at,omitempty"`   // RFC3339
+	StoppedAt         *string            `json:"stopped_at,omitempty"`   // RFC3339
+	FinalState        *FinalState        `json:"final_state,omitempty"`  // Set once the session ended
+	FinalTracks       []FinalState       `json:"final_tracks,omitempty"` // Set once a multi-track session ended
+	CreatedAt         string             `json:"created_at"`             // RFC3339
+}
+
+// ListSessionsResponse represents one page of a session listing
+type ListSessionsResponse struct {
+	Sessions   []GetSessionResponse `json:"sessions"`
+	NextCursor string               `json:"next_cursor,omitempty"` // Pass as cursor for the next page; absent on the last page
 }
 
 // StopSessionResponse represents the response when stopping a session
 type StopSessionResponse struct {
+	ID       string `json:"id"`
+	Status   string `json:"status"` // "stopped"
+	Seed     string `json:"seed"`   // Revealed server seed
+	SeedHash string `json:"seed_hash"`
+}
+
+// VerifySessionResponse represents the response when verifying an ended session
+type VerifySessionResponse struct {
+	ID              string        `json:"id"`
+	Seed            string        `json:"seed"`
+	SeedHash        string        `json:"seed_hash"`
+	CommitmentValid bool          `json:"commitment_valid"` // SHA-256(seed) == seed_hash
+	ClientSeed      string        `json:"client_seed,omitempty"`
+	Nonce           int64         `json:"nonce,omitempty"`
+	EngineVersion   int           `json:"engine_version"`
+	Mode            string        `json:"mode"`
+	Track           int           `json:"track"`     // Track the rounds belong to
+	StopStep        int64         `json:"stop_step"` // Last step before the session stopped or expired
+	Rounds          []RoundResult `json:"rounds"`
+	NextRound       *int64        `json:"next_round,omitempty"` // Set when more rounds are available
+}
+
+// RoundResult represents one round recomputed from the revealed seed
+type RoundResult struct {
+	Round     int64       `json:"round"`
+	StartStep int64       `json:"start_step"`
+	BreakStep int64       `json:"break_step"`
+	Interval  int64       `json:"interval"`
+	Completed bool        `json:"completed"`         // Whether the round broke before the session stopped
+	Outcome   interface{} `json:"outcome,omitempty"` // Rule-specific outcome at the break
+}
+
+// PauseSessionResponse represents the response when pausing or resuming a session
+type PauseSessionResponse struct {
 	ID     string `json:"id"`
-	Status string `json:"status"` // "stopped"
+	Status string `json:"status"` // "paused" or "running"
+	Step   int64  `json:"step"`   // Step the session is frozen at (pause) or resumes from (resume)
 }
 
 // SessionStateResponse represents the response when getting session state
 type SessionStateResponse struct {
-	Step      int64  `json:"step"`
-	Value     int64  `json:"value"`
-	Round     int64  `json:"round"`
-	Broken    bool   `json:"broken"`
-	ComputedAt string `json:"computed_at"` // RFC3339
+	Step          int64        `json:"step"`
+	Value         int64        `json:"value"`
+	Round         int64        `json:"round"`
+	Broken        bool         `json:"broken"`
+	Status        string       `json:"status"`               // Session status: "running", "paused", "stopped", "expired" or "finished"
+	Phase         string       `json:"phase"`                // Lifecycle phase; the state is frozen unless "running"
+	GamePhase     string       `json:"game_phase,omitempty"` // "lobby", "running" or "intermission"; empty before start_at
+	TicksLeft     int64        `json:"ticks_left,omitempty"` // Ticks until the lobby or intermission ends
+	EngineVersion int          `json:"engine_version"`
+	Mode          string       `json:"mode"`
+	Outcome       interface{}  `json:"outcome,omitempty"` // Rule-specific state
+	Tracks        []TrackState `json:"tracks,omitempty"`  // Every track, multi-track sessions only (top-level fields are track 0)
+	At            *string      `json:"at,omitempty"`      // RFC3339, the time queried with ?at=
+	ComputedAt    string       `json:"computed_at"`       // RFC3339
+}
+
+// BatchStateRequest represents the request to get the state of many sessions
+type BatchStateRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// BatchStateResponse represents the state of many sessions, in request order
+type BatchStateResponse struct {
+	States []BatchStateItem `json:"states"`
+}
+
+// BatchStateItem is the state of one session of a batch, or why it failed
+type BatchStateItem struct {
+	ID    string                `json:"id"`
+	State *SessionStateResponse `json:"state,omitempty"`
+	Error *ErrorResponse        `json:"error,omitempty"`
+}
+
+// TrackState is the state of one track of a multi-track session
+type TrackState struct {
+	Track     int         `json:"track"`
+	Step      int64       `json:"step"`
+	Value     int64       `json:"value"`
+	Round     int64       `json:"round"`
+	Broken    bool        `json:"broken"`
+	GamePhase string      `json:"game_phase,omitempty"`
+	TicksLeft int64       `json:"ticks_left,omitempty"`
+	Outcome   interface{} `json:"outcome,omitempty"`
+}
+
+// StreamMessage is a message pushed on a session stream.
+//
+// "state" carries the full state of a track (on connect, on status and
+// lobby/intermission changes, and with resync after dropped messages),
+// "tick" the state at every step, "break" the step a round ended at, and
+// "end" the final state before the stream closes.
+type StreamMessage struct {
+	Type      string      `json:"type"` // "state", "tick", "break" or "end"
+	Track     int         `json:"track"`
+	Step      int64       `json:"step"`
+	Value     int64       `json:"value"`
+	Round     int64       `json:"round"` // Round that ended for "break"
+	Broken    bool        `json:"broken"`
+	Status    string      `json:"status,omitempty"` // Session status ("state" and "end" only)
+	Phase     string      `json:"phase,omitempty"`  // Lifecycle phase ("state" and "end" only)
+	GamePhase string      `json:"game_phase,omitempty"`
+	TicksLeft int64       `json:"ticks_left,omitempty"`
+	Outcome   interface{} `json:"outcome,omitempty"`
+	Resync    bool        `json:"resync,omitempty"` // Messages were dropped for a slow client
 }
 
 // ErrorResponse represents an error response
@@ -72,4 +344,3 @@ type ErrorResponse struct {
 	Error   string `json:"error"`
 	Message string `json:"message,omitempty"`
 }
-
@@ -0,0 +1,232 @@
+package vectors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/engine"
+)
+
+// FormatVersion is the version of the vector file format.
+// Bump it on any incompatible change to File or Vector.
+const FormatVersion = 1
+
+// File is a set of engine test vectors.
+//
+// Vectors describe the counter rule: given (seed, start_at_ms, tick_ms,
+// now_ms), an implementation of engine_version must produce state. The
+// seed is a session seed string, derived with the version's ParseSeed
+// rules, so the vectors also cover UUID and hex digest seeds.
+// Other implementations (TypeScript ports, client SDKs) fill in state with
+// their own output and hand the file to Verify.
+type File struct {
+	FormatVersion int      `json:"format_version"`
+	EngineVersion int      `json:"engine_version"`
+	Generator     string   `json:"generator"`
+	Vectors       []Vector `json:"vectors"`
+}
+
+// Vector is one engine input and its expected state.
+// Seeds are strings: decimal integers (int64 does not fit in a JSON number
+// for every language), UUIDs or hex digests; timestamps are Unix
+// milliseconds.
+type Vector struct {
+	Name      string `json:"name"`
+	Seed      string `json:"seed"`
+	StartAtMs int64  `json:"start_at_ms"`
+	TickMs    int64  `json:"tick_ms"`
+	NowMs     int64  `json:"now_ms"`
+	State     State  `json:"state"`
+}
+
+// State is the engine state of a vector.
+type State struct {
+	Step   int64 `json:"step"`
+	Value  int64 `json:"value"`
+	Round  int64 `json:"round"`
+	Broken bool  `json:"broken"`
+}
+
+// Mismatch describes the first vector whose state differs from the Go engine.
+type Mismatch struct {
+	Index    int
+	Vector   Vector
+	Expected State
+}
+
+// Error implements error.
+func (m *Mismatch) Error() string {
+	return fmt.Sprintf("vector %d (%s): seed=%s start_at_ms=%d tick_ms=%d now_ms=%d: expected %+v, got %+v",
+		m.Index, m.Vector.Name, m.Vector.Seed, m.Vector.StartAtMs, m.Vector.TickMs, m.Vector.NowMs,
+		m.Expected, m.Vector.State)
+}
+
+// derivationSeeds are the non-decimal seeds of the derivation vectors.
+var derivationSeeds = []string{
+	"550e8400-e29b-41d4-a716-446655440000",
+	"550E8400-E29B-41D4-A716-446655440000",
+	"00000000-0000-0000-0000-000000000000",
+	"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", // SHA-256("test")
+	"9F86D081884C7D659A2FEAA0C55AD015A3BF4F1B2B0B822CD15D6C15B0F00A08",
+	"ffffffffffffffff000000000000000000000000000000000000000000000000",
+	"18446744073709551615", // 2^64-1
+}
+
+// baseStartAtMs is the start time used by most vectors (2024-01-15T10:00:00Z).
+const baseStartAtMs = 1705312800000
+
+// Generate builds the vector set for an engine version.
+//
+// It covers edge cases that ports tend to get wrong: before start, the
+// first tick, sub-tick times, steps around breaks, extreme seeds
+// (0, ±1, MinInt64, MaxInt64, seed == round), odd tick rates and large
+// step counts. Seed derivation vectors cover UUIDs and 64-character hex
+// digests in both cases and decimal seeds above 2^63-1.
+func Generate(engineVersion int) (*File, error) {
+	rule, err := engine.NewRule(engine.ModeCounter, engine.RuleConfig{Version: engineVersion})
+	if err != nil {
+		return nil, err
+	}
+
+	file := &File{
+		FormatVersion: FormatVersion,
+		EngineVersion: rule.Version(),
+		Generator:     "deterministic-backend/go",
+	}
+
+	addSeed := func(name, seed string, startAtMs, tickMs, nowMs int64) error {
+		engineSeed, err := engine.ParseSeed(rule.Version(), seed)
+		if err != nil {
+			return err
+		}
+		file.Vectors = append(file.Vectors, Vector{
+			Name:      name,
+			Seed:      seed,
+			StartAtMs: startAtMs,
+			TickMs:    tickMs,
+			NowMs:     nowMs,
+			State:     compute(rule, engineSeed, startAtMs, tickMs, nowMs),
+		})
+		return nil
+	}
+	add := func(name string, seed int64, startAtMs, tickMs, nowMs int64) {
+		// Canonical decimal seeds always parse
+		_ = addSeed(name, strconv.FormatInt(seed, 10), startAtMs, tickMs, nowMs)
+	}
+
+	seeds := []int64{0, 1, -1, 2, 12345, 987654321, -987654321, math.MaxInt64, math.MinInt64, math.MaxInt32, 0x5555555555555555}
+
+	for _, seed := range seeds {
+		prefix := "seed " + strconv.FormatInt(seed, 10)
+
+		// Timing around start
+		add(prefix+": before start", seed, baseStartAtMs, 100, baseStartAtMs-1000)
+		add(prefix+": 1ms before start", seed, baseStartAtMs, 100, baseStartAtMs-1)
+		add(prefix+": at start", seed, baseStartAtMs, 100, baseStartAtMs)
+		add(prefix+": mid first tick", seed, baseStartAtMs, 100, baseStartAtMs+99)
+		add(prefix+": second tick", seed, baseStartAtMs, 100, baseStartAtMs+100)
+
+		// Around the first few breaks
+		for _, info := range engine.RuleRounds(rule, seed, math.MaxInt64, 0, 4) {
+			for _, offset := range []int64{-1, 0, 1} {
+				step := info.BreakStep + offset
+				add(fmt.Sprintf("%s: round %d break %+d", prefix, info.Round, offset),
+					seed, baseStartAtMs, 100, baseStartAtMs+step*100)
+			}
+		}
+
+		// Large step counts
+		add(prefix+": one hour at 100ms", seed, baseStartAtMs, 100, baseStartAtMs+3600*1000)
+		add(prefix+": one day at 10ms", seed, baseStartAtMs, 10, baseStartAtMs+24*3600*1000)
+	}
+
+	// Seeds equal to the round number (degenerate for xorshift64 in version 1)
+	for round := int64(0); round < 4; round++ {
+		add(fmt.Sprintf("seed == round %d", round), round, baseStartAtMs, 100, baseStartAtMs+int64(1000+round*250)*100)
+	}
+
+	// Odd tick rates and start times
+	for _, tickMs := range []int64{1, 7, 16, 333, 1000} {
+		add(fmt.Sprintf("tick %dms: 5000 ticks", tickMs), 12345, baseStartAtMs, tickMs, baseStartAtMs+5000*tickMs)
+		add(fmt.Sprintf("tick %dms: between ticks", tickMs), 12345, baseStartAtMs, tickMs, baseStartAtMs+5000*tickMs+tickMs/2)
+	}
+	add("unix epoch start", 12345, 0, 100, 123456)
+	add("start with milliseconds", 12345, baseStartAtMs+123, 100, baseStartAtMs+123+45678)
+
+	// Seed derivation: UUID and hex digest seeds go through SHA-256 (or the
+	// legacy string hash in version 1) before the engine sees them
+	for _, seed := range derivationSeeds {
+		for _, nowMs := range []int64{baseStartAtMs, baseStartAtMs + 3600*1000} {
+			if err := addSeed(fmt.Sprintf("derived seed %s: %+dms", seed, nowMs-baseStartAtMs), seed, baseStartAtMs, 100, nowMs); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return file, nil
+}
+
+// Verify recomputes every vector with the Go engine.
+// It returns the first Mismatch, or nil if all vectors match. A file
+// without vectors is invalid, so an empty export never passes.
+func Verify(file *File) (*Mismatch, error) {
+	if file.FormatVersion != FormatVersion {
+		return nil, fmt.Errorf("unsupported format_version %d (expected %d)", file.FormatVersion, FormatVersion)
+	}
+	if len(file.Vectors) == 0 {
+		return nil, fmt.Errorf("vector file has no vectors")
+	}
+
+	rule, err := engine.NewRule(engine.ModeCounter, engine.RuleConfig{Version: file.EngineVersion})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, v := range file.Vectors {
+		seed, err := engine.ParseSeed(rule.Version(), v.Seed)
+		if err != nil {
+			return nil, fmt.Errorf("vector %d (%s): %w", i, v.Name, err)
+		}
+		if v.TickMs <= 0 {
+			return nil, fmt.Errorf("vector %d (%s): tick_ms must be greater than 0", i, v.Name)
+		}
+
+		expected := compute(rule, seed, v.StartAtMs, v.TickMs, v.NowMs)
+		if v.State != expected {
+			return &Mismatch{Index: i, Vector: v, Expected: expected}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Read decodes a vector file.
+func Read(r io.Reader) (*File, error) {
+	var file File
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to decode vector file: %w", err)
+	}
+	return &file, nil
+}
+
+// Write encodes a vector file as indented JSON.
+func Write(w io.Writer, file *File) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(file)
+}
+
+// compute runs the Go engine for one vector.
+func compute(rule engine.Rule, seed int64, startAtMs, tickMs, nowMs int64) State {
+	state := engine.RuleStateAt(rule, seed, time.UnixMilli(startAtMs), tickMs, time.UnixMilli(nowMs))
+	return State{
+		Step:   state.Step,
+		Value:  state.Value,
+		Round:  state.Round,
+		Broken: state.Broken,
+	}
+}
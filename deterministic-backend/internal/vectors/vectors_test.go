@@ -0,0 +1,138 @@
+package vectors
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"strings"
+	"testing"
+
+	"github.com/distrubuted-game-mechanic/deterministic-backend/internal/engine"
+)
+
+func TestGenerate_VerifiesAgainstItself(t *testing.T) {
+	for _, version := range []int{engine.Version1, engine.Version2} {
+		file, err := Generate(version)
+		if err != nil {
+			t.Fatalf("version %d: unexpected error: %v", version, err)
+		}
+		if len(file.Vectors) == 0 {
+			t.Fatalf("version %d: expected vectors", version)
+		}
+		if file.EngineVersion != version || file.FormatVersion != FormatVersion {
+			t.Errorf("version %d: unexpected header %+v", version, file)
+		}
+
+		// Round trip through JSON
+		var buf bytes.Buffer
+		if err := Write(&buf, file); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+		read, err := Read(&buf)
+		if err != nil {
+			t.Fatalf("Failed to read: %v", err)
+		}
+
+		mismatch, err := Verify(read)
+		if err != nil {
+			t.Fatalf("version %d: unexpected error: %v", version, err)
+		}
+		if mismatch != nil {
+			t.Errorf("version %d: unexpected mismatch: %v", version, mismatch)
+		}
+	}
+}
+
+func TestGenerate_CoversEdgeCases(t *testing.T) {
+	file, err := Generate(engine.Version1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var beforeStart, broken bool
+	for _, v := range file.Vectors {
+		if v.NowMs < v.StartAtMs {
+			beforeStart = true
+		}
+		if v.State.Broken {
+			broken = true
+		}
+	}
+	if !beforeStart {
+		t.Error("Expected vectors before start")
+	}
+	if !broken {
+		t.Error("Expected vectors on break steps")
+	}
+}
+
+func TestGenerate_SeedDerivation(t *testing.T) {
+	file, err := Generate(engine.Version2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// A UUID seed is SHA-256 of its lowercase form, in any case
+	sum := sha256.Sum256([]byte("550e8400-e29b-41d4-a716-446655440000"))
+	seed := engine.SeedFromDigest(sum[:])
+	rule, _ := engine.NewRule(engine.ModeCounter, engine.RuleConfig{Version: engine.Version2})
+
+	derived := 0
+	for _, v := range file.Vectors {
+		if !strings.EqualFold(v.Seed, "550e8400-e29b-41d4-a716-446655440000") {
+			continue
+		}
+		derived++
+		if expected := compute(rule, seed, v.StartAtMs, v.TickMs, v.NowMs); v.State != expected {
+			t.Errorf("%s: expected %+v, got %+v", v.Name, expected, v.State)
+		}
+	}
+	if derived != 4 {
+		t.Errorf("Expected 4 UUID vectors, got %d", derived)
+	}
+}
+
+func TestVerify_ReportsFirstMismatch(t *testing.T) {
+	file, err := Generate(engine.Version1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Simulate an implementation that is off by one in two places
+	file.Vectors[5].State.Value++
+	file.Vectors[9].State.Round++
+
+	mismatch, err := Verify(file)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if mismatch == nil {
+		t.Fatal("Expected a mismatch")
+	}
+	if mismatch.Index != 5 {
+		t.Errorf("Expected first mismatch at vector 5, got %d", mismatch.Index)
+	}
+	if mismatch.Expected.Value != file.Vectors[5].State.Value-1 {
+		t.Errorf("Expected Go value %d, got %d", file.Vectors[5].State.Value-1, mismatch.Expected.Value)
+	}
+}
+
+func TestVerify_InvalidFile(t *testing.T) {
+	tests := []struct {
+		name string
+		file File
+	}{
+		{name: "unknown format", file: File{FormatVersion: 99, EngineVersion: 1}},
+		{name: "unknown engine version", file: File{FormatVersion: FormatVersion, EngineVersion: 99}},
+		{name: "no vectors", file: File{FormatVersion: FormatVersion, EngineVersion: 1}},
+		{name: "invalid seed", file: File{FormatVersion: FormatVersion, EngineVersion: 2, Vectors: []Vector{{Seed: "abc", TickMs: 100}}}},
+		{name: "invalid tick", file: File{FormatVersion: FormatVersion, EngineVersion: 1, Vectors: []Vector{{Seed: "1", TickMs: 0}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Verify(&tt.file); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}